@@ -0,0 +1,137 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pgedge/pgedge-docloader/internal/config"
+	"github.com/pgedge/pgedge-docloader/internal/database"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List or restore previous versions of a document",
+	Long: `List or restore previous versions of a document kept in the table
+specified with --history-table. Versions are numbered from 1 (the most
+recently replaced version) backwards.`,
+}
+
+func init() {
+	listCmd := &cobra.Command{
+		Use:   "list FILE",
+		Short: "List previous versions of a document",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runHistoryList,
+	}
+	listCmd.Flags().String("ref", "", "Git ref of the document, when several refs are stored (requires --col-git-ref)")
+	historyCmd.AddCommand(listCmd)
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "Restore a previous version of a document",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runHistoryRestore,
+	}
+	restoreCmd.Flags().Int("version", 1, "Version to restore, as numbered by 'history list'")
+	restoreCmd.Flags().String("ref", "", "Git ref of the document, when several refs are stored (requires --col-git-ref)")
+	historyCmd.AddCommand(restoreCmd)
+}
+
+// connectHistory loads the configuration and connects to the database for
+// the history subcommands, returning the Git ref of the document as well
+func connectHistory(cmd *cobra.Command) (*database.Client, string, error) {
+	ref, err := cmd.Flags().GetString("ref")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get ref flag: %w", err)
+	}
+
+	cfg, err := config.LoadWithoutSource(cmd)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if cfg.HistoryTable == "" {
+		return nil, "", fmt.Errorf("--history-table is required")
+	}
+
+	// Documents are only stored per ref when the ref column is mapped
+	if ref != "" && cfg.ColumnGitRef == "" {
+		return nil, "", fmt.Errorf("--ref requires --col-git-ref")
+	}
+
+	dbClient, err := database.New(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return dbClient, ref, nil
+}
+
+// historyName describes a document and, if given, its Git ref
+func historyName(fileName, ref string) string {
+	if ref == "" {
+		return fileName
+	}
+	return fmt.Sprintf("%s at %s", fileName, ref)
+}
+
+func runHistoryList(cmd *cobra.Command, args []string) error {
+	dbClient, ref, err := connectHistory(cmd)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	versions, err := dbClient.ListHistory(context.Background(), args[0], ref)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		fmt.Printf("No previous versions of %s\n", historyName(args[0], ref))
+		return nil
+	}
+
+	fmt.Printf("Previous versions of %s:\n", historyName(args[0], ref))
+	for _, v := range versions {
+		validFrom := "unknown"
+		if v.ValidFrom != nil {
+			validFrom = v.ValidFrom.Format(time.RFC3339)
+		}
+		fmt.Printf("  %d. %s to %s  %s\n", v.Version, validFrom, v.ValidTo.Format(time.RFC3339), v.Title)
+	}
+
+	return nil
+}
+
+func runHistoryRestore(cmd *cobra.Command, args []string) error {
+	version, err := cmd.Flags().GetInt("version")
+	if err != nil {
+		return fmt.Errorf("failed to get version flag: %w", err)
+	}
+
+	dbClient, ref, err := connectHistory(cmd)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	if err := dbClient.RestoreHistory(context.Background(), args[0], ref, version); err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored version %d of %s\n", version, historyName(args[0], ref))
+	return nil
+}
@@ -0,0 +1,134 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pgedge/pgedge-docloader/internal/database"
+	"github.com/pgedge/pgedge-docloader/internal/gitsource"
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// planIncremental works out what to load from a Git checkout since the
+// commit last loaded from its ref. It returns the files to process and the
+// rows to rename or delete, or nil changes if all files must be loaded
+// (because nothing has been loaded before, or the last loaded commit is no
+// longer available).
+func planIncremental(ctx context.Context, dbClient *database.Client,
+	source *loadSource) ([]string, *types.FileChanges, error) {
+	last, err := dbClient.LastLoadedCommit(ctx, source.gitURL, source.ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last == "" {
+		fmt.Printf("No previously loaded commit found%s, loading all files\n", refSuffix(source.ref))
+		return nil, nil, nil
+	}
+	if last == source.commit {
+		fmt.Printf("Commit %s is already loaded%s, nothing to do\n", shortCommit(last), refSuffix(source.ref))
+		return nil, &types.FileChanges{Ref: source.ref}, nil
+	}
+
+	diff, err := source.git.Changes(last)
+	if errors.Is(err, gitsource.ErrCommitNotFound) {
+		fmt.Fprintf(os.Stderr, "Warning: %v, loading all files\n", err)
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, changes := planChanges(diff, source)
+
+	fmt.Printf("Loading changes since %s%s: %d changed, %d renamed, %d deleted\n",
+		shortCommit(last), refSuffix(source.ref), len(files), len(changes.Renamed), len(changes.Deleted))
+
+	return files, changes, nil
+}
+
+// planChanges works out the files to process and the rows to rename or
+// delete for the changes made to a Git checkout. Archives are reloaded
+// when added, modified or renamed, but as their members are stored by
+// their path within the archive, the rows of a deleted archive (or of
+// members removed from one) are kept.
+func planChanges(diff *gitsource.Changes, source *loadSource) ([]string, *types.FileChanges) {
+	// Only files that would be loaded from the source paths have rows
+	inScope := func(path string) bool {
+		if !source.opts.Registry().IsSupported(path) && !processor.IsArchive(path) {
+			return false
+		}
+		for _, sourcePath := range source.paths {
+			if processor.MatchesSource(path, sourcePath) {
+				return !processor.Excluded(path, sourcePath, source.opts)
+			}
+		}
+		return false
+	}
+
+	var files []string
+	changes := &types.FileChanges{Ref: source.ref, Renamed: make(map[string]string)}
+
+	for oldPath, newPath := range diff.Renamed {
+		if !inScope(oldPath) {
+			continue
+		}
+		if processor.IsArchive(oldPath) {
+			if !inScope(newPath) {
+				fmt.Fprintf(os.Stderr, "Warning: archive %s was moved out of the source paths, keeping the rows of its members\n", oldPath)
+			}
+			continue
+		}
+		if inScope(newPath) {
+			changes.Renamed[processor.FileName(oldPath, source.opts)] = processor.FileName(newPath, source.opts)
+		} else {
+			changes.Deleted = append(changes.Deleted, processor.FileName(oldPath, source.opts))
+		}
+	}
+
+	for _, path := range diff.Deleted {
+		if !inScope(path) {
+			continue
+		}
+		if processor.IsArchive(path) {
+			fmt.Fprintf(os.Stderr, "Warning: archive %s was deleted, keeping the rows of its members\n", path)
+			continue
+		}
+		changes.Deleted = append(changes.Deleted, processor.FileName(path, source.opts))
+	}
+
+	for _, path := range diff.Modified {
+		if inScope(path) {
+			files = append(files, path)
+		}
+	}
+
+	return files, changes
+}
+
+// refSuffix describes a Git ref in progress messages
+func refSuffix(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	return " on " + ref
+}
+
+// shortCommit abbreviates a commit SHA for display
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,57 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/gitsource"
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+)
+
+func TestPlanChanges(t *testing.T) {
+	docs := filepath.Join(t.TempDir(), "docs")
+	path := func(name string) string {
+		return filepath.Join(docs, name)
+	}
+
+	source := &loadSource{
+		paths: []string{docs},
+		opts:  processor.Options{StripPath: true},
+	}
+	diff := &gitsource.Changes{
+		Modified: []string{path("guide.md"), path("bundle.tar.gz"), path("moved.zip"), path("image.png")},
+		Deleted:  []string{path("old.md"), path("old.tgz"), path("logo.png")},
+		Renamed: map[string]string{
+			path("before.md"):  path("after.md"),
+			path("before.zip"): path("moved.zip"),
+		},
+	}
+
+	files, changes := planChanges(diff, source)
+
+	// Added, modified and renamed archives are reloaded
+	expected := []string{path("guide.md"), path("bundle.tar.gz"), path("moved.zip")}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+
+	// Archive members keep their rows: they are not named after the archive
+	if len(changes.Renamed) != 1 || changes.Renamed["before.md"] != "after.md" {
+		t.Errorf("expected only before.md to be renamed, got %v", changes.Renamed)
+	}
+	sort.Strings(changes.Deleted)
+	if strings.Join(changes.Deleted, ",") != "old.md" {
+		t.Errorf("expected only old.md to be deleted, got %v", changes.Deleted)
+	}
+}
@@ -11,8 +11,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -21,7 +24,9 @@ import (
 	"github.com/pgedge/pgedge-docloader/internal/database"
 	"github.com/pgedge/pgedge-docloader/internal/gitsource"
 	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/s3source"
 	"github.com/pgedge/pgedge-docloader/internal/types"
+	"github.com/pgedge/pgedge-docloader/internal/websource"
 )
 
 var (
@@ -41,58 +46,108 @@ var rootCmd = &cobra.Command{
 	Use:   "pgedge-docloader",
 	Short: "pgEdge Document Loader - Load documents into PostgreSQL",
 	Long: `pgEdge Document Loader is a tool to load documents from various formats
-(HTML, Markdown, reStructuredText, SGML/DocBook) into a PostgreSQL database table.
+(HTML, Markdown, reStructuredText, AsciiDoc, Jupyter notebooks, man pages,
+plain text, SGML/DocBook, and formats handled by external converters set up
+in the configuration file) into a PostgreSQL database table.
 
 The tool converts documents to Markdown format and extracts metadata before
-storing them in the specified database table.`,
+storing them in the specified database table. Run the formats command to
+list the supported formats.`,
 	RunE: run,
 }
 
 func init() {
-	// Configuration file
-	rootCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	// Configuration file, database connection and column mappings are
+	// shared with subcommands that access the database
+	rootCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
 
 	// Source configuration - Local
-	rootCmd.Flags().StringSliceP("source", "s", []string{}, "Source file, directory, or glob pattern (can be repeated)")
+	rootCmd.Flags().StringSliceP("source", "s", []string{}, "Source file, directory, glob pattern, or .tar, .tar.gz, .tgz or .zip archive (can be repeated)")
 	rootCmd.Flags().Bool("strip-path", false, "Strip path from filename, keeping only the base name")
+	rootCmd.Flags().String("files-from", "", "File listing paths to load, one per line or NUL separated (- for stdin)")
+	rootCmd.Flags().Bool("stdin", false, "Load one document from stdin (requires --stdin-name)")
+	rootCmd.Flags().String("stdin-name", "", "Name to store the stdin document under; its extension gives the format (e.g. guide.md)")
+	rootCmd.Flags().StringSlice("include", []string{}, "Load only files matching this pattern from directories and globs (can be repeated; supports **)")
+	rootCmd.Flags().StringSlice("exclude", []string{}, "Leave out files and directories matching this pattern (can be repeated; supports **)")
+	rootCmd.Flags().Bool("no-ignore-files", false, "Do not honor .gitignore and .docloaderignore files")
+	rootCmd.PersistentFlags().StringSlice("format-map", []string{}, "Load files matching a pattern or extension as a format (format: pattern=format, e.g. .mdx=markdown or docs/api/*.xml=html; can be repeated)")
+	rootCmd.Flags().Bool("strip-front-matter", false, "Remove YAML/TOML front matter from the stored Markdown content")
+	rootCmd.Flags().Bool("notebook-outputs", false, "Include the text outputs of Jupyter notebook code cells")
+	rootCmd.Flags().Int("notebook-output-limit", converter.DefaultNotebookOutputLimit, "Size in bytes at which each notebook output is truncated (0 for no limit)")
 
 	// Source configuration - Git (mutually exclusive with --source)
 	rootCmd.Flags().String("git-url", "", "Git repository URL to clone and process")
 	rootCmd.Flags().String("git-branch", "", "Git branch to checkout (default: repository default)")
 	rootCmd.Flags().String("git-tag", "", "Git tag to checkout (mutually exclusive with --git-branch)")
+	rootCmd.Flags().StringSlice("git-refs", []string{}, "Branches, tags or tag globs (e.g. v*) to load together, each in its own worktree (can be repeated)")
 	rootCmd.Flags().StringSlice("git-doc-path", []string{}, "Path within repository to process (can be repeated)")
 	rootCmd.Flags().String("git-clone-dir", "", "Directory to store cloned repositories (default: temp directory)")
 	rootCmd.Flags().Bool("git-keep-clone", false, "Keep cloned repository after processing")
 	rootCmd.Flags().Bool("git-skip-fetch", false, "Skip git fetch if repository already exists")
+	rootCmd.Flags().Bool("git-full-history", false, "Clone full history for per-file commit timestamps, author and commit")
+	rootCmd.Flags().String("git-token-env", "", "Environment variable holding an access token for HTTPS Git URLs")
+	rootCmd.Flags().String("git-username", "", "Username to send with the Git access token (default: x-access-token)")
+	rootCmd.Flags().String("git-ssh-key", "", "SSH private key file for SSH Git URLs")
+	rootCmd.Flags().Bool("git-submodules", false, "Recursively initialize submodules within the doc paths")
+	rootCmd.Flags().String("git-lfs", "skip", "Git LFS pointer files: skip them, or fetch their content (skip, fetch)")
+	rootCmd.Flags().String("git-backend", "exec", "Git implementation: the git command, or built in for hosts without git (exec, go-git)")
+	rootCmd.Flags().Bool("git-incremental", false, "Only load files changed since the last loaded commit (implies --update)")
+
+	// Source configuration - Web
+	rootCmd.Flags().StringSlice("web-url", []string{}, "Web page to start crawling from (can be repeated)")
+	rootCmd.Flags().String("web-sitemap", "", "URL of a sitemap.xml listing web pages to load")
+	rootCmd.Flags().StringSlice("web-prefix", []string{}, "URL prefix crawled pages must be within (default: the directories of the start URLs and sitemap; can be repeated)")
+	rootCmd.Flags().Int("web-max-depth", 3, "Number of links to follow from a start page")
+	rootCmd.Flags().Int("web-max-pages", 1000, "Maximum number of web pages to fetch (0 for no limit)")
+	rootCmd.Flags().Float64("web-rate-limit", 2, "Maximum web requests per second (0 for no limit)")
+
+	// Source configuration - S3
+	rootCmd.Flags().String("s3-bucket", "", "S3 bucket to load objects from")
+	rootCmd.Flags().StringSlice("s3-prefix", []string{}, "Key prefix of the objects to load (default: the whole bucket; can be repeated)")
+	rootCmd.Flags().String("s3-endpoint", "", "Endpoint URL of an S3-compatible service (default: AWS)")
+	rootCmd.Flags().String("s3-region", "", "S3 region (default: from the environment or profile)")
+	rootCmd.Flags().Bool("s3-path-style", false, "Address the bucket in the URL path, as some S3-compatible services require")
+	rootCmd.Flags().String("s3-profile", "", "AWS shared configuration profile to take credentials from")
 
 	// Database connection
-	rootCmd.Flags().String("db-host", "localhost", "Database host")
-	rootCmd.Flags().Int("db-port", 5432, "Database port")
-	rootCmd.Flags().String("db-name", "", "Database name")
-	rootCmd.Flags().String("db-user", "", "Database user")
-	rootCmd.Flags().String("db-sslmode", "prefer", "SSL mode (disable, allow, prefer, require, verify-ca, verify-full)")
-	rootCmd.Flags().String("db-table", "", "Database table name")
+	rootCmd.PersistentFlags().String("db-host", "localhost", "Database host")
+	rootCmd.PersistentFlags().Int("db-port", 5432, "Database port")
+	rootCmd.PersistentFlags().String("db-name", "", "Database name")
+	rootCmd.PersistentFlags().String("db-user", "", "Database user")
+	rootCmd.PersistentFlags().String("db-sslmode", "prefer", "SSL mode (disable, allow, prefer, require, verify-ca, verify-full)")
+	rootCmd.PersistentFlags().String("db-table", "", "Database table name")
 
 	// SSL/TLS configuration
-	rootCmd.Flags().String("db-sslcert", "", "Path to client SSL certificate")
-	rootCmd.Flags().String("db-sslkey", "", "Path to client SSL key")
-	rootCmd.Flags().String("db-sslrootcert", "", "Path to SSL root certificate")
+	rootCmd.PersistentFlags().String("db-sslcert", "", "Path to client SSL certificate")
+	rootCmd.PersistentFlags().String("db-sslkey", "", "Path to client SSL key")
+	rootCmd.PersistentFlags().String("db-sslrootcert", "", "Path to SSL root certificate")
 
 	// Column mappings
-	rootCmd.Flags().String("col-doc-title", "", "Column name for document title")
-	rootCmd.Flags().String("col-doc-content", "", "Column name for document content (markdown)")
-	rootCmd.Flags().String("col-source-content", "", "Column name for source content (bytea)")
-	rootCmd.Flags().String("col-file-name", "", "Column name for file name")
-	rootCmd.Flags().String("col-file-created", "", "Column name for file creation timestamp")
-	rootCmd.Flags().String("col-file-modified", "", "Column name for file modification timestamp")
-	rootCmd.Flags().String("col-row-created", "", "Column name for row creation timestamp")
-	rootCmd.Flags().String("col-row-updated", "", "Column name for row update timestamp")
+	rootCmd.PersistentFlags().String("col-doc-title", "", "Column name for document title")
+	rootCmd.PersistentFlags().String("col-doc-content", "", "Column name for document content (markdown)")
+	rootCmd.PersistentFlags().String("col-source-content", "", "Column name for source content (bytea)")
+	rootCmd.PersistentFlags().String("col-file-name", "", "Column name for file name")
+	rootCmd.PersistentFlags().String("col-file-created", "", "Column name for file creation timestamp")
+	rootCmd.PersistentFlags().String("col-file-modified", "", "Column name for file modification timestamp")
+	rootCmd.PersistentFlags().String("col-author", "", "Column name for the last commit author (Git sources)")
+	rootCmd.PersistentFlags().String("col-commit", "", "Column name for the last commit SHA (Git sources)")
+	rootCmd.PersistentFlags().String("col-git-ref", "", "Column name for the Git ref (version) documents were loaded from")
+	rootCmd.PersistentFlags().String("col-row-created", "", "Column name for row creation timestamp")
+	rootCmd.PersistentFlags().String("col-row-updated", "", "Column name for row update timestamp")
+	rootCmd.PersistentFlags().String("col-run-id", "", "Column name for the ID of the run that last wrote the row")
+	rootCmd.PersistentFlags().String("col-metadata", "", "Column name for document front matter (jsonb)")
+	rootCmd.PersistentFlags().StringSlice("col-front-matter", []string{}, "Map a front matter key to a column (format: key=column, can be specified multiple times)")
+
+	// Run log
+	rootCmd.Flags().String("run-log-table", "", "Table to record one row per loader run in (optional)")
+	rootCmd.Flags().String("state-table", "", "Table to record the last loaded Git commit in (optional)")
 
 	// Custom metadata columns
-	rootCmd.Flags().StringSlice("set-column", []string{}, "Set custom column value (format: column=value, can be specified multiple times)")
+	rootCmd.PersistentFlags().StringSlice("set-column", []string{}, "Set custom column value (format: column=value or column:type=value; value may be a Go template, can be specified multiple times)")
 
 	// Operation mode
 	rootCmd.Flags().BoolP("update", "u", false, "Update existing rows (matched by filename) or insert new ones")
+	rootCmd.PersistentFlags().String("history-table", "", "Table to keep previous versions of updated documents in (optional)")
 
 	// Version command
 	rootCmd.AddCommand(&cobra.Command{
@@ -103,64 +158,215 @@ func init() {
 		},
 	})
 
+	// Document history command
+	rootCmd.AddCommand(historyCmd)
+
 	// List supported formats command
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "formats",
-		Short: "List supported document formats",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Supported document formats:")
-			for _, ext := range converter.GetSupportedExtensions() {
-				fmt.Printf("  %s\n", ext)
-			}
-		},
+		Short: "List supported document formats and how files are mapped to them",
+		RunE:  runFormats,
 	})
 }
 
-func run(cmd *cobra.Command, args []string) error {
+func run(cmd *cobra.Command, args []string) (err error) {
 	// Load configuration
 	cfg, err := config.Load(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	formats, err := config.Formats(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	run := types.NewRunInfo()
+	run.Version = version
+	run.Commit = commit
+
+	ctx := context.Background()
+	stats := &types.Stats{}
+	var dbClient *database.Client
+
+	// Every run is recorded in the run log, including runs that fail or
+	// find no documents to load
+	defer func() {
+		err = finishRun(ctx, cfg, run, dbClient, stats, err)
+	}()
+
+	opts := processor.Options{
+		StripPath:        cfg.StripPath,
+		StripFrontMatter: cfg.StripFrontMatter,
+		Include:          cfg.Include,
+		Exclude:          cfg.Exclude,
+		IgnoreFiles:      !cfg.NoIgnoreFiles,
+		Formats:          formats,
+	}
 
 	// Determine source paths
-	var sourcePaths []string
-	var gitSource *gitsource.GitSource
+	var sources []*loadSource
+	var gitURLs, refs, commits []string
+
+	for _, entry := range cfg.SourceEntries() {
+		entryOpts := opts
+		entryOpts.StripPath = entry.StripPath
+		entryOpts.Source = gitsource.RedactURL(entry.Name)
+
+		if entry.Type == types.SourceWeb {
+			crawler, err := websource.New(cfg.ForSource(entry))
+			if err != nil {
+				return fmt.Errorf("failed to setup web source %s: %w", entryOpts.Source, err)
+			}
+			sources = append(sources, &loadSource{opts: entryOpts, loader: crawler})
+			continue
+		}
+
+		if entry.Type == types.SourceS3 {
+			bucket, err := s3source.New(ctx, cfg.ForSource(entry))
+			if err != nil {
+				return fmt.Errorf("failed to setup S3 source %s: %w", entryOpts.Source, err)
+			}
+			sources = append(sources, &loadSource{opts: entryOpts, loader: bucket})
+			continue
+		}
+
+		if entry.Type != types.SourceGit {
+			// Local source
+			sources = append(sources, &loadSource{paths: entry.Paths, opts: entryOpts})
+			continue
+		}
 
-	if cfg.GitURL != "" {
 		// Git source
-		gitSource, err = gitsource.New(cfg)
+		gitSource, err := gitsource.New(cfg.ForSource(entry))
 		if err != nil {
-			return fmt.Errorf("failed to setup git source: %w", err)
+			return fmt.Errorf("failed to setup git source %s: %w", entryOpts.Source, err)
 		}
 		defer func() {
 			if cleanupErr := gitSource.Cleanup(); cleanupErr != nil {
 				fmt.Fprintf(os.Stderr, "Warning: cleanup failed: %v\n", cleanupErr)
 			}
 		}()
-		sourcePaths = gitSource.GetSourcePaths()
-	} else {
-		// Local source
-		sourcePaths = cfg.Source
+		// Credentials in the URL are not stored or printed
+		gitURL := gitsource.RedactURL(entry.GitURL)
+		gitURLs = append(gitURLs, gitURL)
+
+		// Each ref is loaded from its own checkout
+		for _, checkout := range gitSource.Checkouts() {
+			source := &loadSource{
+				paths:    checkout.GetSourcePaths(),
+				opts:     entryOpts,
+				git:      checkout,
+				gitURL:   gitURL,
+				ref:      checkout.Ref(),
+				docPaths: entry.GitDocPath,
+			}
+			if source.commit, err = checkout.HeadCommit(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			source.opts.GitURL = source.gitURL
+			source.opts.GitRef = source.ref
+			source.opts.GitCommit = source.commit
+
+			// Use commit history for file timestamps, author and commit
+			if source.opts.FileMetadata, err = checkout.FileMetadata(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to read file history: %v\n", err)
+			}
+
+			sources = append(sources, source)
+			refs = append(refs, source.ref)
+			commits = append(commits, source.commit)
+			run.Checkouts = append(run.Checkouts, types.GitCheckout{
+				URL:    source.gitURL,
+				Ref:    source.ref,
+				Commit: source.commit,
+			})
+		}
+	}
+
+	// Files listed in a file or on stdin
+	if cfg.FilesFrom != "" {
+		source, err := fileListSource(cfg.FilesFrom, opts)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+
+	// A single document on stdin
+	if cfg.Stdin {
+		stdinOpts := opts
+		stdinOpts.Source = "stdin"
+		sources = append(sources, &loadSource{
+			opts:   stdinOpts,
+			loader: readerLoader{r: os.Stdin, name: cfg.StdinName},
+		})
 	}
 
-	// Process files from all source paths
+	run.GitURL = strings.Join(gitURLs, ",")
+	run.GitRef = strings.Join(refs, ",")
+	run.GitCommit = strings.Join(commits, ",")
+	for _, source := range sources {
+		run.Sources = append(run.Sources, source.logNames()...)
+	}
+
+	// Incremental loads need the last loaded commit before processing
+	if cfg.GitIncremental {
+		if dbClient, err = connectDatabase(cfg); err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			if source.git == nil {
+				continue
+			}
+			source.files, source.changes, err = planIncremental(ctx, dbClient, source)
+			if err != nil {
+				return fmt.Errorf("failed to determine changed files: %w", err)
+			}
+		}
+	}
+
+	// Process files from all sources
 	var allDocuments []*types.Document
-	stats := &types.Stats{}
+	incremental := false
+
+	for _, source := range sources {
+		if source.loader != nil {
+			fmt.Printf("Loading documents from: %s\n", source.opts.Source)
+			documents, loaderStats, err := source.loader.Load(ctx, source.opts)
+			if err != nil {
+				return fmt.Errorf("failed to load documents from %s: %w", source.opts.Source, err)
+			}
+			allDocuments = append(allDocuments, documents...)
+			stats.Merge(loaderStats)
+			continue
+		}
 
-	for _, sourcePath := range sourcePaths {
-		fmt.Printf("Processing files from: %s\n", sourcePath)
-		documents, pathStats, err := processor.ProcessFiles(sourcePath, cfg.StripPath)
-		if err != nil {
-			return fmt.Errorf("failed to process files from %s: %w", sourcePath, err)
+		if source.files != nil || source.changes != nil {
+			if source.changes != nil {
+				incremental = true
+			}
+			documents, fileStats := processor.ProcessFileList(source.files, source.opts)
+			allDocuments = append(allDocuments, documents...)
+			stats.Merge(fileStats)
+			stats.FilesExcluded += source.excluded
+			continue
+		}
+
+		for _, sourcePath := range source.paths {
+			fmt.Printf("Processing files from: %s\n", sourcePath)
+			documents, pathStats, err := processor.ProcessFiles(sourcePath, source.opts)
+			if err != nil {
+				return fmt.Errorf("failed to process files from %s: %w", sourcePath, err)
+			}
+			allDocuments = append(allDocuments, documents...)
+			stats.Merge(pathStats)
 		}
-		allDocuments = append(allDocuments, documents...)
-		stats.FilesProcessed += pathStats.FilesProcessed
-		stats.FilesSkipped += pathStats.FilesSkipped
-		stats.Errors = append(stats.Errors, pathStats.Errors...)
 	}
 
-	if len(allDocuments) == 0 {
+	// Incremental loads continue so that renames, deletions and the loaded
+	// commit are recorded
+	if len(allDocuments) == 0 && !incremental {
 		fmt.Println("No documents to process.")
 		return nil
 	}
@@ -169,16 +375,19 @@ func run(cmd *cobra.Command, args []string) error {
 		stats.FilesProcessed, stats.FilesSkipped)
 
 	// Connect to database
-	fmt.Printf("Connecting to database %s@%s:%d/%s\n",
-		cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
-	dbClient, err := database.New(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	if dbClient == nil {
+		if dbClient, err = connectDatabase(cfg); err != nil {
+			return err
+		}
+	}
+	dbClient.SetRunInfo(run)
+	for _, source := range sources {
+		if source.changes != nil {
+			dbClient.AddFileChanges(source.changes)
+		}
 	}
-	defer dbClient.Close()
 
 	// Insert documents
-	ctx := context.Background()
 	if err := dbClient.InsertDocuments(ctx, allDocuments, stats); err != nil {
 		return fmt.Errorf("failed to insert documents: %w", err)
 	}
@@ -186,15 +395,215 @@ func run(cmd *cobra.Command, args []string) error {
 	// Print summary
 	printSummary(stats)
 
+	if cfg.RunLogTable != "" {
+		fmt.Printf("Run ID: %s\n", run.ID)
+	}
+
+	return nil
+}
+
+// loadSource is a set of source paths processed with the same options: a
+// configured local source, or one checked out ref of a Git source; or a
+// list of files, or a web, S3 or stdin source, whose documents are loaded
+// without paths
+type loadSource struct {
+	paths []string
+	opts  processor.Options
+
+	// Loader for a web, S3 or stdin source
+	loader documentLoader
+
+	// Git checkout the paths are in, if any, and the doc paths within the
+	// repository they were given as
+	git      *gitsource.GitSource
+	gitURL   string
+	ref      string
+	commit   string
+	docPaths []string
+
+	// Files to process: those listed with --files-from, or for incremental
+	// loads those changed, with the rows to rename or delete (nil changes
+	// means all files are loaded)
+	files   []string
+	changes *types.FileChanges
+
+	// Listed files left out by the include and exclude patterns
+	excluded int
+}
+
+// logNames returns what the run log records a source as: its paths, or its
+// name if it has none. The paths of a Git source are within a clone that
+// may be gone after the run, so it is recorded by its repository URL and
+// ref, with each doc path after a # (as in url@ref#docs).
+func (s *loadSource) logNames() []string {
+	if s.git == nil {
+		if len(s.paths) == 0 {
+			return []string{s.opts.Source}
+		}
+		return s.paths
+	}
+
+	name := s.gitURL
+	if s.ref != "" {
+		name += "@" + s.ref
+	}
+	if len(s.docPaths) == 0 {
+		return []string{name}
+	}
+	names := make([]string, len(s.docPaths))
+	for i, docPath := range s.docPaths {
+		names[i] = name + "#" + docPath
+	}
+	return names
+}
+
+// documentLoader loads the documents of a source that is not a set of
+// files, such as a website or S3 bucket
+type documentLoader interface {
+	Load(ctx context.Context, opts processor.Options) ([]*types.Document, *types.Stats, error)
+}
+
+// readerLoader loads a single document from a reader, such as stdin
+type readerLoader struct {
+	r    io.Reader
+	name string
+}
+
+// Load reads and converts the document
+func (l readerLoader) Load(_ context.Context, opts processor.Options) ([]*types.Document, *types.Stats, error) {
+	doc, err := processor.ProcessReader(l.r, l.name, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*types.Document{doc}, &types.Stats{FilesProcessed: 1}, nil
+}
+
+// fileListSource reads the list of files to load from a file, or from stdin
+// if listFile is -
+func fileListSource(listFile string, opts processor.Options) (*loadSource, error) {
+	var r io.Reader = os.Stdin
+	name := "stdin"
+	if listFile != "-" {
+		f, err := os.Open(listFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file list: %w", err)
+		}
+		defer f.Close()
+		r = f
+		name = listFile
+	}
+
+	files, err := processor.ReadFileList(r)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Processing %d file(s) listed in: %s\n", len(files), name)
+
+	opts.Source = name
+	// The remaining list is never nil, so an empty list still forms a
+	// source with no files
+	files, excluded := processor.FilterFiles(files, opts)
+	return &loadSource{files: files, excluded: excluded, opts: opts}, nil
+}
+
+// runFormats lists the format mappings in the order they are applied
+func runFormats(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadFormats(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	formats, err := config.Formats(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if mappings := formats.FormatMappings(); len(mappings) > 0 {
+		fmt.Println("Configured format mappings:")
+		for _, m := range mappings {
+			fmt.Printf("  %-20s %s\n", m.Pattern, m.Type)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Supported document formats:")
+	for _, e := range formats.Extensions() {
+		format := e.Type.String()
+		if e.Type == types.TypeUnknown {
+			format = "detected from content"
+		}
+		fmt.Printf("  %-20s %s\n", e.Extension, format)
+	}
+	fmt.Printf("  %-20s %s\n", "(no extension)", "detected from content")
+
+	fmt.Println()
+	fmt.Println("Content detection recognizes YAML or TOML front matter as Markdown, an")
+	fmt.Println("AsciiDoc document title (= Title) as AsciiDoc, a .TH line as a Man Page,")
+	fmt.Println("notebook JSON as a Jupyter Notebook, and an HTML or DocBook doctype or")
+	fmt.Println("root element as HTML or SGML/DocBook. Otherwise, .txt files and files")
+	fmt.Println("named like README or LICENSE are Plain Text.")
 	return nil
 }
 
+// errConnect is returned when the target database cannot be connected to
+var errConnect = errors.New("failed to connect to database")
+
+// connectDatabase connects to the target database
+func connectDatabase(cfg *types.Config) (*database.Client, error) {
+	fmt.Printf("Connecting to database %s@%s:%d/%s\n",
+		cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
+	dbClient, err := database.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConnect, err)
+	}
+	return dbClient, nil
+}
+
+// finishRun records the run in the run log table, if one is configured,
+// and closes the database connection. Runs that ended before connecting to
+// the database, such as those that found no documents, connect to record
+// the run. It returns the run's error, or the run log's if the run
+// succeeded.
+func finishRun(ctx context.Context, cfg *types.Config, run *types.RunInfo, dbClient *database.Client, stats *types.Stats, runErr error) error {
+	if dbClient == nil {
+		if cfg.RunLogTable == "" || errors.Is(runErr, errConnect) {
+			return runErr
+		}
+		var err error
+		if dbClient, err = connectDatabase(cfg); err != nil {
+			if runErr != nil {
+				return fmt.Errorf("%w (additionally, the run was not recorded: %v)", runErr, err)
+			}
+			return err
+		}
+	}
+	defer dbClient.Close()
+
+	dbClient.SetRunInfo(run)
+	if logErr := dbClient.WriteRunLog(ctx, stats, runErr); logErr != nil {
+		if runErr != nil {
+			return fmt.Errorf("%w (additionally, %v)", runErr, logErr)
+		}
+		return logErr
+	}
+	return runErr
+}
+
 func printSummary(stats *types.Stats) {
 	fmt.Println("\n=== Processing Summary ===")
 	fmt.Printf("Files processed: %d\n", stats.FilesProcessed)
 	fmt.Printf("Files skipped:   %d\n", stats.FilesSkipped)
+	if stats.FilesExcluded > 0 {
+		fmt.Printf("Files excluded:  %d\n", stats.FilesExcluded)
+	}
+	if stats.LFSPointers > 0 {
+		fmt.Printf("  LFS pointers:  %d\n", stats.LFSPointers)
+	}
 	fmt.Printf("Rows inserted:   %d\n", stats.FilesInserted)
 	fmt.Printf("Rows updated:    %d\n", stats.FilesUpdated)
+	if stats.FilesRenamed > 0 || stats.FilesDeleted > 0 {
+		fmt.Printf("Rows renamed:    %d\n", stats.FilesRenamed)
+		fmt.Printf("Rows deleted:    %d\n", stats.FilesDeleted)
+	}
 
 	if stats.HasErrors() {
 		fmt.Printf("\nErrors encountered: %d\n", len(stats.Errors))
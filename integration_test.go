@@ -17,7 +17,6 @@ import (
 	"fmt"
 	"os"
 	"testing"
-	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -73,7 +72,7 @@ func TestIntegrationInsertDocuments(t *testing.T) {
 	defer dropTestTable(ctx, dbClient, cfg)
 
 	// Process test documents
-	docs, stats, err := processor.ProcessFiles("testdata", false)
+	docs, stats, err := processor.ProcessFiles("testdata", processor.Options{})
 	if err != nil {
 		t.Fatalf("Failed to process files: %v", err)
 	}
@@ -110,7 +109,7 @@ func TestIntegrationUpdateDocuments(t *testing.T) {
 	defer dropTestTable(ctx, dbClient, cfg)
 
 	// Process and insert documents first time
-	docs, stats, err := processor.ProcessFiles("testdata/sample.md", false)
+	docs, stats, err := processor.ProcessFiles("testdata/sample.md", processor.Options{})
 	if err != nil {
 		t.Fatalf("Failed to process files: %v", err)
 	}
@@ -138,6 +137,76 @@ func TestIntegrationUpdateDocuments(t *testing.T) {
 	t.Logf("Updated %d documents", stats2.FilesUpdated)
 }
 
+func TestIntegrationRestoreDeletedDocument(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.UpdateMode = true
+	cfg.HistoryTable = "test_documents_history"
+
+	dbClient, err := database.New(cfg)
+	if err != nil {
+		t.Skipf("Skipping integration test: %v", err)
+	}
+	defer dbClient.Close()
+
+	ctx := context.Background()
+	if err := createTestTable(ctx, dbClient, cfg); err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+	defer dropTestTable(ctx, dbClient, cfg)
+
+	conn, err := testConn(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	historySQL := fmt.Sprintf(`
+        CREATE TABLE IF NOT EXISTS %s (
+            %s TEXT, %s TEXT, %s BYTEA, %s TEXT, %s TIMESTAMP,
+            valid_from TIMESTAMPTZ, valid_to TIMESTAMPTZ NOT NULL
+        )`,
+		cfg.HistoryTable, cfg.ColumnDocTitle, cfg.ColumnDocContent,
+		cfg.ColumnSourceContent, cfg.ColumnFileName, cfg.ColumnFileModified)
+	if _, err := conn.Exec(ctx, historySQL); err != nil {
+		t.Fatalf("Failed to create history table: %v", err)
+	}
+	defer conn.Exec(ctx, "DROP TABLE IF EXISTS "+cfg.HistoryTable)
+
+	// Load two versions, so that the first is in the history
+	for _, content := range []string{"# Page\n\nFirst", "# Page\n\nSecond"} {
+		doc := &types.Document{Title: "Page", Content: content, FileName: "page.md"}
+		if err := dbClient.InsertDocuments(ctx, []*types.Document{doc}, &types.Stats{}); err != nil {
+			t.Fatalf("Failed to load document: %v", err)
+		}
+	}
+
+	// Deleting the row archives nothing, so version 1 is still the first
+	if _, err := conn.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = 'page.md'", cfg.DBTable, cfg.ColumnFileName)); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	if err := dbClient.RestoreHistory(ctx, "page.md", "", 1); err != nil {
+		t.Fatalf("Failed to restore document: %v", err)
+	}
+
+	var content string
+	err = conn.QueryRow(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE %s = 'page.md'",
+		cfg.ColumnDocContent, cfg.DBTable, cfg.ColumnFileName)).Scan(&content)
+	if err != nil {
+		t.Fatalf("Failed to read restored document: %v", err)
+	}
+	if content != "# Page\n\nFirst" {
+		t.Errorf("Expected the first version to be restored, got %q", content)
+	}
+}
+
+// testConn connects to the test database directly, for statements the
+// client does not run
+func testConn(ctx context.Context, cfg *types.Config) (*pgx.Conn, error) {
+	connStr := fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBName, cfg.DBUser, cfg.DBPassword, cfg.DBSSLMode)
+	return pgx.Connect(ctx, connStr)
+}
+
 func createTestTable(ctx context.Context, dbClient *database.Client, cfg *types.Config) error {
 	// This is a helper to access the pool - in a real implementation
 	// we'd expose a method to execute raw SQL
@@ -11,6 +11,7 @@ package config
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,48 +20,93 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/gitsource"
+	"github.com/pgedge/pgedge-docloader/internal/glob"
 	"github.com/pgedge/pgedge-docloader/internal/types"
 )
 
 // Load loads configuration from file and CLI flags
 func Load(cmd *cobra.Command) (*types.Config, error) {
-	cfg := &types.Config{}
-
-	// Get config file path if specified
-	configFile, err := cmd.Flags().GetString("config")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config flag: %w", err)
-	}
-	if configFile != "" {
-		cfg.ConfigFile = configFile
-
-		// Set the config file path
-		viper.SetConfigFile(configFile)
+	return load(cmd, true)
+}
 
-		// Read the config file
-		if err := viper.ReadInConfig(); err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
-	}
+// LoadWithoutSource loads configuration for commands that only work with
+// the database (such as history) and so do not need a document source
+func LoadWithoutSource(cmd *cobra.Command) (*types.Config, error) {
+	return load(cmd, false)
+}
 
-	// Bind CLI flags to viper
-	if err := viper.BindPFlags(cmd.Flags()); err != nil {
-		return nil, fmt.Errorf("failed to bind flags: %w", err)
+// load loads configuration from file and CLI flags, optionally requiring a
+// document source to be configured
+func load(cmd *cobra.Command, requireSource bool) (*types.Config, error) {
+	cfg := &types.Config{}
+	if err := readConfig(cmd, cfg); err != nil {
+		return nil, err
 	}
 
 	// Load configuration values (CLI flags override config file)
 	// Local source configuration
 	cfg.Source = viper.GetStringSlice("source")
 	cfg.StripPath = viper.GetBool("strip-path")
+	cfg.StripFrontMatter = viper.GetBool("strip-front-matter")
+	cfg.FilesFrom = viper.GetString("files-from")
+	cfg.Stdin = viper.GetBool("stdin")
+	cfg.StdinName = viper.GetString("stdin-name")
+	cfg.Include = viper.GetStringSlice("include")
+	cfg.Exclude = viper.GetStringSlice("exclude")
+	cfg.NoIgnoreFiles = viper.GetBool("no-ignore-files")
+	if err := loadConverters(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadFormatMap(cfg); err != nil {
+		return nil, err
+	}
+	cfg.NotebookOutputs = viper.GetBool("notebook-outputs")
+	cfg.NotebookOutputLimit = viper.GetInt("notebook-output-limit")
+	if cfg.NotebookOutputLimit < 0 {
+		return nil, fmt.Errorf("--notebook-output-limit must not be negative")
+	}
 
 	// Git source configuration
 	cfg.GitURL = viper.GetString("git-url")
 	cfg.GitBranch = viper.GetString("git-branch")
 	cfg.GitTag = viper.GetString("git-tag")
 	cfg.GitDocPath = viper.GetStringSlice("git-doc-path")
+	cfg.GitRefs = viper.GetStringSlice("git-refs")
 	cfg.GitCloneDir = viper.GetString("git-clone-dir")
 	cfg.GitKeepClone = viper.GetBool("git-keep-clone")
 	cfg.GitSkipFetch = viper.GetBool("git-skip-fetch")
+	cfg.GitFullHistory = viper.GetBool("git-full-history")
+	cfg.GitIncremental = viper.GetBool("git-incremental")
+	cfg.GitTokenEnv = viper.GetString("git-token-env")
+	cfg.GitUsername = viper.GetString("git-username")
+	cfg.GitSSHKey = viper.GetString("git-ssh-key")
+	cfg.GitSubmodules = viper.GetBool("git-submodules")
+	cfg.GitLFS = strings.ToLower(viper.GetString("git-lfs"))
+	if cfg.GitLFS == "" {
+		cfg.GitLFS = gitsource.LFSSkip
+	}
+	cfg.GitBackend = strings.ToLower(viper.GetString("git-backend"))
+	if cfg.GitBackend == "" {
+		cfg.GitBackend = gitsource.BackendExec
+	}
+
+	// Web source configuration
+	cfg.WebURL = viper.GetStringSlice("web-url")
+	cfg.WebSitemap = viper.GetString("web-sitemap")
+	cfg.WebPrefix = viper.GetStringSlice("web-prefix")
+	cfg.WebMaxDepth = viper.GetInt("web-max-depth")
+	cfg.WebMaxPages = viper.GetInt("web-max-pages")
+	cfg.WebRateLimit = viper.GetFloat64("web-rate-limit")
+
+	// S3 source configuration
+	cfg.S3Bucket = viper.GetString("s3-bucket")
+	cfg.S3Prefix = viper.GetStringSlice("s3-prefix")
+	cfg.S3Endpoint = viper.GetString("s3-endpoint")
+	cfg.S3Region = viper.GetString("s3-region")
+	cfg.S3PathStyle = viper.GetBool("s3-path-style")
+	cfg.S3Profile = viper.GetString("s3-profile")
 
 	cfg.DBHost = viper.GetString("db-host")
 	cfg.DBPort = viper.GetInt("db-port")
@@ -81,40 +127,98 @@ func Load(cmd *cobra.Command) (*types.Config, error) {
 	cfg.ColumnFileModified = viper.GetString("col-file-modified")
 	cfg.ColumnRowCreated = viper.GetString("col-row-created")
 	cfg.ColumnRowUpdated = viper.GetString("col-row-updated")
+	cfg.ColumnRunID = viper.GetString("col-run-id")
+	cfg.ColumnAuthor = viper.GetString("col-author")
+	cfg.ColumnCommit = viper.GetString("col-commit")
+	cfg.ColumnGitRef = viper.GetString("col-git-ref")
+	cfg.ColumnMetadata = viper.GetString("col-metadata")
+
+	// Front matter key to column mappings, from the config file map and
+	// then --col-front-matter flags (which override the config file)
+	cfg.FrontMatterColumns = make(map[string]string)
+	if viper.IsSet("front-matter-columns") {
+		for key, col := range viper.GetStringMapString("front-matter-columns") {
+			cfg.FrontMatterColumns[key] = col
+		}
+	}
+	for _, mapping := range viper.GetStringSlice("col-front-matter") {
+		key, col, found := strings.Cut(mapping, "=")
+		key = strings.TrimSpace(key)
+		col = strings.TrimSpace(col)
+		if !found || key == "" || col == "" {
+			return nil, fmt.Errorf("invalid col-front-matter format '%s': expected key=column", mapping)
+		}
+		cfg.FrontMatterColumns[key] = col
+	}
+
+	cfg.RunLogTable = viper.GetString("run-log-table")
+	cfg.HistoryTable = viper.GetString("history-table")
+	cfg.StateTable = viper.GetString("state-table")
 
 	// Parse custom columns from --set-column flags and config file
 	cfg.CustomColumns = make(map[string]string)
+	cfg.CustomColumnTypes = make(map[string]string)
 
 	// First, try to load from config file as a map
 	if viper.IsSet("custom-columns") {
-		customCols := viper.GetStringMapString("custom-columns")
-		for k, v := range customCols {
-			cfg.CustomColumns[k] = v
+		if err := loadCustomColumns(cfg.CustomColumns, cfg.CustomColumnTypes, viper.Get("custom-columns")); err != nil {
+			return nil, err
 		}
 	}
 
 	// Then, load from CLI flags (which override config file)
 	setColumnFlags := viper.GetStringSlice("set-column")
 	for _, colValue := range setColumnFlags {
-		parts := strings.SplitN(colValue, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid set-column format '%s': expected column=value", colValue)
-		}
-		colName := strings.TrimSpace(parts[0])
-		colVal := strings.TrimSpace(parts[1])
-		if colName == "" {
-			return nil, fmt.Errorf("invalid set-column format '%s': column name cannot be empty", colValue)
+		colName, colType, colVal, err := parseSetColumn(colValue)
+		if err != nil {
+			return nil, err
 		}
 		cfg.CustomColumns[colName] = colVal
+		if colType != "" {
+			cfg.CustomColumnTypes[colName] = colType
+		} else {
+			delete(cfg.CustomColumnTypes, colName)
+		}
+	}
+
+	// Additional sources from the config file
+	if viper.IsSet("sources") {
+		sources, err := loadSources(viper.Get("sources"), types.SourceConfig{
+			StripPath:    cfg.StripPath,
+			WebMaxDepth:  cfg.WebMaxDepth,
+			WebMaxPages:  cfg.WebMaxPages,
+			WebRateLimit: cfg.WebRateLimit,
+			S3Endpoint:   cfg.S3Endpoint,
+			S3Region:     cfg.S3Region,
+			S3PathStyle:  cfg.S3PathStyle,
+			S3Profile:    cfg.S3Profile,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cfg.Sources = sources
 	}
 
 	cfg.UpdateMode = viper.GetBool("update")
 
+	// Incremental loads update changed documents in place
+	if cfg.GitIncremental {
+		cfg.UpdateMode = true
+	}
+
 	// Resolve relative paths relative to config file directory
 	if cfg.ConfigFile != "" {
 		configDir := filepath.Dir(cfg.ConfigFile)
 		cfg.Source = resolvePaths(cfg.Source, configDir)
+		for i := range cfg.Sources {
+			cfg.Sources[i].Paths = resolvePaths(cfg.Sources[i].Paths, configDir)
+			cfg.Sources[i].GitSSHKey = resolvePath(cfg.Sources[i].GitSSHKey, configDir)
+		}
+		if cfg.FilesFrom != "-" {
+			cfg.FilesFrom = resolvePath(cfg.FilesFrom, configDir)
+		}
 		cfg.GitCloneDir = resolvePath(cfg.GitCloneDir, configDir)
+		cfg.GitSSHKey = resolvePath(cfg.GitSSHKey, configDir)
 		cfg.DBSSLCert = resolvePath(cfg.DBSSLCert, configDir)
 		cfg.DBSSLKey = resolvePath(cfg.DBSSLKey, configDir)
 		cfg.DBSSLRoot = resolvePath(cfg.DBSSLRoot, configDir)
@@ -128,13 +232,166 @@ func Load(cmd *cobra.Command) (*types.Config, error) {
 	cfg.DBPassword = password
 
 	// Validate configuration
-	if err := validate(cfg); err != nil {
+	validateFn := validate
+	if !requireSource {
+		validateFn = validateDatabase
+	}
+	if err := validateFn(cfg); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// LoadFormats loads just the external converters and format mappings, for
+// commands that describe the supported formats
+func LoadFormats(cmd *cobra.Command) (*types.Config, error) {
+	cfg := &types.Config{}
+	if err := readConfig(cmd, cfg); err != nil {
+		return nil, err
+	}
+	if err := loadConverters(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadFormatMap(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readConfig reads the configuration file, if one is given, and binds the
+// command's flags so that they override it
+func readConfig(cmd *cobra.Command, cfg *types.Config) error {
+	// Get config file path if specified
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("failed to get config flag: %w", err)
+	}
+	if configFile != "" {
+		cfg.ConfigFile = configFile
+
+		// Set the config file path
+		viper.SetConfigFile(configFile)
+
+		// Read the config file
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	// Bind CLI flags to viper
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return fmt.Errorf("failed to bind flags: %w", err)
+	}
+	return nil
+}
+
+// loadFormatMap loads the file pattern to format mappings, from the config
+// file map and then --format-map flags (which override the config file)
+func loadFormatMap(cfg *types.Config) error {
+	cfg.FormatMap = make(map[string]string)
+	if viper.IsSet("formats") {
+		for pattern, format := range viper.GetStringMapString("formats") {
+			cfg.FormatMap[pattern] = format
+		}
+	}
+	for _, mapping := range viper.GetStringSlice("format-map") {
+		pattern, format, found := strings.Cut(mapping, "=")
+		pattern = strings.TrimSpace(pattern)
+		format = strings.TrimSpace(format)
+		if !found || pattern == "" || format == "" {
+			return fmt.Errorf("invalid format-map '%s': expected pattern=format", mapping)
+		}
+		cfg.FormatMap[pattern] = format
+	}
+
+	// Check the converters and mappings now, as the mappings must name
+	// known formats
+	_, err := Formats(cfg)
+	return err
+}
+
+// Formats returns the converters and format mappings of the configuration,
+// for a run to detect and convert documents with (see processor.Options)
+func Formats(cfg *types.Config) (*converter.Registry, error) {
+	formats := converter.Default().WithNotebookOutputs(cfg.NotebookOutputs, cfg.NotebookOutputLimit)
+	formats, err := formats.WithCommands(cfg.Converters)
+	if err != nil {
+		return nil, err
+	}
+	return formats.WithFormatMap(cfg.FormatMap)
+}
+
+// loadCustomColumns loads custom columns from the config file into the
+// given value and type maps. Each entry is either a plain value, or a map
+// with "value" and optional "type" keys.
+func loadCustomColumns(values, colTypes map[string]string, raw interface{}) error {
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid custom-columns: expected a map of column names to values")
+	}
+
+	for colName, entry := range entries {
+		switch v := entry.(type) {
+		case map[string]interface{}:
+			value, ok := v["value"]
+			if !ok {
+				return fmt.Errorf("invalid custom column '%s': missing value", colName)
+			}
+			values[colName] = customColumnValue(value)
+			if colType, ok := v["type"]; ok {
+				colTypes[colName] = fmt.Sprint(colType)
+			}
+		default:
+			values[colName] = customColumnValue(v)
+		}
+	}
+
+	return nil
+}
+
+// customColumnValue converts a config file value to its string form; lists
+// and maps are converted to JSON for array and json/jsonb columns
+func customColumnValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseSetColumn parses a --set-column value of the form column=value or
+// column:type=value
+func parseSetColumn(colValue string) (colName, colType, value string, err error) {
+	parts := strings.SplitN(colValue, "=", 2)
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("invalid set-column format '%s': expected column=value", colValue)
+	}
+
+	colName = strings.TrimSpace(parts[0])
+	value = strings.TrimSpace(parts[1])
+
+	if name, typ, found := strings.Cut(colName, ":"); found {
+		colName = strings.TrimSpace(name)
+		colType = strings.TrimSpace(typ)
+	}
+
+	if colName == "" {
+		return "", "", "", fmt.Errorf("invalid set-column format '%s': column name cannot be empty", colValue)
+	}
+
+	return colName, colType, value, nil
+}
+
 // resolvePath resolves a path relative to a base directory if not absolute
 func resolvePath(path, baseDir string) string {
 	if path == "" {
@@ -218,21 +475,104 @@ func readPgPass() (string, error) {
 
 // validate validates the configuration
 func validate(cfg *types.Config) error {
-	// Source validation: either local source or git-url, but not both
-	if len(cfg.Source) == 0 && cfg.GitURL == "" {
-		return fmt.Errorf("either --source or --git-url is required")
+	if err := validateSource(cfg); err != nil {
+		return err
+	}
+	return validateDatabase(cfg)
+}
+
+// validateSource validates the document source configuration
+func validateSource(cfg *types.Config) error {
+	entries := cfg.SourceEntries()
+	if len(entries) == 0 && cfg.FilesFrom == "" && !cfg.Stdin {
+		return fmt.Errorf("either --source, --files-from, --stdin, --git-url, --web-url, --web-sitemap, --s3-bucket or a sources list is required")
+	}
+
+	if cfg.Stdin {
+		if cfg.StdinName == "" {
+			return fmt.Errorf("--stdin requires --stdin-name to name the document and give its format")
+		}
+		formats, err := Formats(cfg)
+		if err != nil {
+			return err
+		}
+		if !formats.IsSupported(cfg.StdinName) {
+			return fmt.Errorf("unsupported --stdin-name '%s': the extension must be one of %s",
+				cfg.StdinName, strings.Join(formats.GetSupportedExtensions(), ", "))
+		}
+		if cfg.FilesFrom == "-" {
+			return fmt.Errorf("--stdin and --files-from - cannot both read stdin")
+		}
+	} else if cfg.StdinName != "" {
+		return fmt.Errorf("--stdin-name requires --stdin")
 	}
-	if len(cfg.Source) > 0 && cfg.GitURL != "" {
-		return fmt.Errorf("--source and --git-url are mutually exclusive")
+
+	for _, pattern := range append(append([]string{}, cfg.Include...), cfg.Exclude...) {
+		if err := glob.Validate(pattern); err != nil {
+			return err
+		}
 	}
 
-	// Git-specific validation
-	if cfg.GitURL != "" {
-		if cfg.GitBranch != "" && cfg.GitTag != "" {
-			return fmt.Errorf("--git-branch and --git-tag are mutually exclusive")
+	names := make(map[string]bool)
+	gitSources := 0
+	unstableNames := false
+	for i := range entries {
+		if err := validateSourceEntry(&entries[i]); err != nil {
+			return err
+		}
+		// Documents are matched to their source's custom columns by name
+		if names[entries[i].Name] {
+			return fmt.Errorf("duplicate source name '%s': give each source a unique name", entries[i].Name)
+		}
+		names[entries[i].Name] = true
+
+		if entries[i].Type == types.SourceGit {
+			gitSources++
+			if !entries[i].StripPath {
+				unstableNames = true
+			}
 		}
 	}
 
+	if cfg.GitLFS != "" && cfg.GitLFS != gitsource.LFSSkip && cfg.GitLFS != gitsource.LFSFetch {
+		return fmt.Errorf("invalid --git-lfs '%s' (expected %s or %s)", cfg.GitLFS, gitsource.LFSSkip, gitsource.LFSFetch)
+	}
+	if cfg.GitBackend != "" && cfg.GitBackend != gitsource.BackendExec && cfg.GitBackend != gitsource.BackendGoGit {
+		return fmt.Errorf("invalid --git-backend '%s' (expected %s or %s)", cfg.GitBackend, gitsource.BackendExec, gitsource.BackendGoGit)
+	}
+	if cfg.GitBackend == gitsource.BackendGoGit && cfg.GitLFS == gitsource.LFSFetch {
+		return fmt.Errorf("--git-lfs %s requires --git-backend %s", gitsource.LFSFetch, gitsource.BackendExec)
+	}
+
+	// Incremental loads find the last loaded commit in the state table or
+	// run log, and match changed files to rows by filename
+	if cfg.GitIncremental {
+		if gitSources == 0 {
+			return fmt.Errorf("--git-incremental requires a Git source")
+		}
+		if cfg.StateTable == "" && cfg.RunLogTable == "" {
+			return fmt.Errorf("--git-incremental requires --state-table or --run-log-table")
+		}
+		if cfg.ColumnFileName == "" {
+			return fmt.Errorf("--git-incremental requires --col-file-name")
+		}
+		// File names include the clone directory unless the path is
+		// stripped, so a temporary clone would match no rows next time
+		if unstableNames && cfg.GitCloneDir == "" {
+			return fmt.Errorf("--git-incremental requires --git-clone-dir or --strip-path to keep file names stable between runs")
+		}
+		// The run log records the refs of a run together, so the commit of
+		// each ref can only be found in the state table
+		if cfg.StateTable == "" && (gitSources > 1 || len(cfg.GitRefs) > 0 || hasGitRefs(cfg.Sources)) {
+			return fmt.Errorf("--git-incremental with several Git sources or --git-refs requires --state-table")
+		}
+	}
+
+	return nil
+}
+
+// validateDatabase validates the database and column configuration
+func validateDatabase(cfg *types.Config) error {
 	if cfg.DBHost == "" {
 		return fmt.Errorf("database host is required")
 	}
@@ -257,9 +597,26 @@ func validate(cfg *types.Config) error {
 		cfg.ColumnFileCreated == "" &&
 		cfg.ColumnFileModified == "" &&
 		cfg.ColumnRowCreated == "" &&
-		cfg.ColumnRowUpdated == "" {
+		cfg.ColumnRowUpdated == "" &&
+		cfg.ColumnRunID == "" &&
+		cfg.ColumnAuthor == "" &&
+		cfg.ColumnCommit == "" &&
+		cfg.ColumnGitRef == "" &&
+		cfg.ColumnMetadata == "" &&
+		len(cfg.FrontMatterColumns) == 0 {
 		return fmt.Errorf("at least one column mapping must be specified")
 	}
 
+	for colName, colType := range cfg.CustomColumnTypes {
+		if !types.IsSupportedColumnType(colType) {
+			return fmt.Errorf("unsupported type '%s' for custom column '%s'", colType, colName)
+		}
+	}
+
+	// History rows are matched to documents by filename
+	if cfg.HistoryTable != "" && cfg.ColumnFileName == "" {
+		return fmt.Errorf("--history-table requires --col-file-name")
+	}
+
 	return nil
 }
@@ -83,6 +83,135 @@ func TestValidate(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"Files from only",
+			&types.Config{
+				FilesFrom:        "files.txt",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			false,
+		},
+		{
+			"Stdin only",
+			&types.Config{
+				Stdin:            true,
+				StdinName:        "generated.md",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			false,
+		},
+		{
+			"Stdin without name",
+			&types.Config{
+				Stdin:            true,
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Stdin with unsupported name",
+			&types.Config{
+				Stdin:            true,
+				StdinName:        "generated.png",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Stdin and files from stdin",
+			&types.Config{
+				FilesFrom:        "-",
+				Stdin:            true,
+				StdinName:        "generated.md",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Stdin name without stdin",
+			&types.Config{
+				Source:           []string{"/path/to/source"},
+				StdinName:        "generated.md",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Include and exclude patterns",
+			&types.Config{
+				Source:           []string{"/path/to/source"},
+				Include:          []string{"**/*.md", "docs/{a,b}"},
+				Exclude:          []string{"node_modules/", "_build"},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			false,
+		},
+		{
+			"Invalid exclude pattern",
+			&types.Config{
+				Source:           []string{"/path/to/source"},
+				Exclude:          []string{"drafts/[a-"},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Invalid source pattern",
+			&types.Config{
+				Source:           []string{"/path/to/docs/**/*.{md,rst"},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Invalid git doc path pattern",
+			&types.Config{
+				GitURL:           "https://github.com/org/repo.git",
+				GitDocPath:       []string{"docs/[a-"},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
 		{
 			"Valid git source",
 			&types.Config{
@@ -106,6 +235,54 @@ func TestValidate(t *testing.T) {
 				DBTable:          "testtable",
 				ColumnDocContent: "content",
 			},
+			false,
+		},
+		{
+			"Sources list only",
+			&types.Config{
+				Sources: []types.SourceConfig{
+					{Name: "a", Type: types.SourceGit, GitURL: "https://github.com/org/a.git"},
+					{Name: "b", Type: types.SourceLocal, Paths: []string{"/path/to/b"}},
+				},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			false,
+		},
+		{
+			"Duplicate source names",
+			&types.Config{
+				Sources: []types.SourceConfig{
+					{Name: "docs", Type: types.SourceGit, GitURL: "https://github.com/org/a.git"},
+					{Name: "docs", Type: types.SourceGit, GitURL: "https://github.com/org/b.git"},
+				},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Incremental with several Git sources and run log table",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitIncremental: true,
+				GitCloneDir:    "/var/cache/docloader/repos",
+				Sources: []types.SourceConfig{
+					{Name: "other", Type: types.SourceGit, GitURL: "https://github.com/org/other.git"},
+				},
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				RunLogTable:    "docloader_runs",
+			},
 			true,
 		},
 		{
@@ -192,6 +369,175 @@ func TestValidate(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"Unsupported custom column type",
+			&types.Config{
+				Source:            []string{"/path/to/source"},
+				DBHost:            "localhost",
+				DBName:            "testdb",
+				DBUser:            "testuser",
+				DBTable:           "testtable",
+				ColumnDocContent:  "content",
+				CustomColumns:     map[string]string{"weight": "10"},
+				CustomColumnTypes: map[string]string{"weight": "integer; DROP TABLE x"},
+			},
+			true,
+		},
+		{
+			"History table without file name column",
+			&types.Config{
+				Source:           []string{"/path/to/source"},
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+				HistoryTable:     "testtable_history",
+			},
+			true,
+		},
+		{
+			"Incremental git source",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitIncremental: true,
+				GitCloneDir:    "/var/cache/docloader/repos",
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				StateTable:     "docloader_state",
+			},
+			false,
+		},
+		{
+			"Incremental git source with temporary clone",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitIncremental: true,
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				StateTable:     "docloader_state",
+			},
+			true,
+		},
+		{
+			"Incremental git source with stripped paths",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitIncremental: true,
+				StripPath:      true,
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				StateTable:     "docloader_state",
+			},
+			false,
+		},
+		{
+			"Git refs with branch",
+			&types.Config{
+				GitURL:           "https://github.com/org/repo.git",
+				GitRefs:          []string{"v*"},
+				GitBranch:        "main",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Incremental git refs with run log table",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitRefs:        []string{"v15", "v16"},
+				GitIncremental: true,
+				GitCloneDir:    "/var/cache/docloader/repos",
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				RunLogTable:    "docloader_runs",
+			},
+			true,
+		},
+		{
+			"Incremental without state or run log table",
+			&types.Config{
+				GitURL:         "https://github.com/org/repo.git",
+				GitIncremental: true,
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+			},
+			true,
+		},
+		{
+			"Incremental without file name column",
+			&types.Config{
+				GitURL:           "https://github.com/org/repo.git",
+				GitIncremental:   true,
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+				RunLogTable:      "docloader_runs",
+			},
+			true,
+		},
+		{
+			"Incremental local source",
+			&types.Config{
+				Source:         []string{"/path/to/source"},
+				GitIncremental: true,
+				DBHost:         "localhost",
+				DBName:         "testdb",
+				DBUser:         "testuser",
+				DBTable:        "testtable",
+				ColumnFileName: "filename",
+				StateTable:     "docloader_state",
+			},
+			true,
+		},
+		{
+			"Unknown Git backend",
+			&types.Config{
+				GitURL:           "https://github.com/org/docs.git",
+				GitBackend:       "libgit2",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
+		{
+			"Git LFS fetch with go-git backend",
+			&types.Config{
+				GitURL:           "https://github.com/org/docs.git",
+				GitBackend:       "go-git",
+				GitLFS:           "fetch",
+				DBHost:           "localhost",
+				DBName:           "testdb",
+				DBUser:           "testuser",
+				DBTable:          "testtable",
+				ColumnDocContent: "content",
+			},
+			true,
+		},
 		{
 			"Missing all columns",
 			&types.Config{
@@ -218,6 +564,107 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateDatabase(t *testing.T) {
+	// Database-only commands do not need a source
+	cfg := &types.Config{
+		DBHost:         "localhost",
+		DBName:         "testdb",
+		DBUser:         "testuser",
+		DBTable:        "testtable",
+		ColumnFileName: "filename",
+		HistoryTable:   "testtable_history",
+	}
+
+	if err := validateDatabase(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := validate(cfg); err == nil {
+		t.Error("expected error for missing source, got nil")
+	}
+}
+
+func TestParseSetColumn(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		colName   string
+		colType   string
+		value     string
+		shouldErr bool
+	}{
+		{"Plain value", "product=pgAdmin 4", "product", "", "pgAdmin 4", false},
+		{"Typed value", "weight:integer=10", "weight", "integer", "10", false},
+		{"Array type", "tags:text[]=a,b", "tags", "text[]", "a,b", false},
+		{"Template value", "section={{.FileName | dir}}", "section", "", "{{.FileName | dir}}", false},
+		{"Missing equals", "product", "", "", "", true},
+		{"Empty name", ":integer=10", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colName, colType, value, err := parseSetColumn(tt.input)
+			if tt.shouldErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if colName != tt.colName || colType != tt.colType || value != tt.value {
+				t.Errorf("expected (%s, %s, %s), got (%s, %s, %s)",
+					tt.colName, tt.colType, tt.value, colName, colType, value)
+			}
+		})
+	}
+}
+
+func TestLoadCustomColumns(t *testing.T) {
+	cfg := &types.Config{
+		CustomColumns:     make(map[string]string),
+		CustomColumnTypes: make(map[string]string),
+	}
+
+	raw := map[string]interface{}{
+		"product": "pgAdmin 4",
+		"weight": map[string]interface{}{
+			"value": 10,
+			"type":  "integer",
+		},
+		"tags": map[string]interface{}{
+			"value": []interface{}{"admin", "gui"},
+			"type":  "text[]",
+		},
+	}
+
+	if err := loadCustomColumns(cfg.CustomColumns, cfg.CustomColumnTypes, raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.CustomColumns["product"] != "pgAdmin 4" {
+		t.Errorf("expected product 'pgAdmin 4', got '%s'", cfg.CustomColumns["product"])
+	}
+	if _, ok := cfg.CustomColumnTypes["product"]; ok {
+		t.Error("expected no type for plain product column")
+	}
+	if cfg.CustomColumns["weight"] != "10" || cfg.CustomColumnTypes["weight"] != "integer" {
+		t.Errorf("unexpected weight column: %s (%s)", cfg.CustomColumns["weight"], cfg.CustomColumnTypes["weight"])
+	}
+	if cfg.CustomColumns["tags"] != `["admin","gui"]` {
+		t.Errorf("expected tags as JSON array, got '%s'", cfg.CustomColumns["tags"])
+	}
+
+	// A map entry must have a value
+	err := loadCustomColumns(cfg.CustomColumns, cfg.CustomColumnTypes, map[string]interface{}{
+		"broken": map[string]interface{}{"type": "integer"},
+	})
+	if err == nil {
+		t.Error("expected error for entry without value, got nil")
+	}
+}
+
 func TestReadPgPass(t *testing.T) {
 	// Create a temporary .pgpass file
 	tmpDir := t.TempDir()
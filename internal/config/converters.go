@@ -0,0 +1,106 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// loadConverters loads the external converters from the config file,
+// before the format mappings that may name them
+func loadConverters(cfg *types.Config) error {
+	cfg.Converters = nil
+	if viper.IsSet("converters") {
+		list, ok := viper.Get("converters").([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid converters: expected a list of converters")
+		}
+
+		for i, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid converter %d: expected a map", i+1)
+			}
+			conv, err := loadConverter(entry)
+			if err != nil {
+				return fmt.Errorf("invalid converter %d: %w", i+1, err)
+			}
+
+			// A command given as a relative path is relative to the
+			// config file; a bare program name is found on the PATH
+			if cfg.ConfigFile != "" && strings.ContainsRune(conv.Command[0], filepath.Separator) {
+				conv.Command[0] = resolvePath(conv.Command[0], filepath.Dir(cfg.ConfigFile))
+			}
+			cfg.Converters = append(cfg.Converters, conv)
+		}
+	}
+
+	return nil
+}
+
+// loadConverter loads a single entry of the converters list
+func loadConverter(entry map[string]interface{}) (types.ConverterConfig, error) {
+	var conv types.ConverterConfig
+	for key, value := range entry {
+		var err error
+		switch key {
+		case "name":
+			conv.Name = fmt.Sprint(value)
+		case "extensions":
+			conv.Extensions, err = stringList(key, value)
+		case "command":
+			// A single string is split on white space; give a list to
+			// pass arguments containing spaces
+			if s, ok := value.(string); ok {
+				conv.Command = strings.Fields(s)
+			} else {
+				conv.Command, err = stringList(key, value)
+			}
+		case "output":
+			conv.Output = fmt.Sprint(value)
+		case "timeout":
+			conv.Timeout, err = durationValue(key, value)
+		default:
+			return conv, fmt.Errorf("unknown key '%s'", key)
+		}
+		if err != nil {
+			return conv, err
+		}
+	}
+
+	if len(conv.Command) == 0 {
+		return conv, fmt.Errorf("command is required")
+	}
+	return conv, nil
+}
+
+// durationValue converts a config file value that must be a duration, such
+// as "30s", or a number of seconds
+func durationValue(key string, value interface{}) (time.Duration, error) {
+	if s, ok := value.(string); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a duration such as 30s: %w", key, err)
+		}
+		return d, nil
+	}
+	seconds, err := floatValue(key, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration such as 30s, or a number of seconds", key)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,66 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConverter(t *testing.T) {
+	conv, err := loadConverter(map[string]interface{}{
+		"name":       "Javadoc",
+		"extensions": []interface{}{".java"},
+		"command":    "./scripts/javadoc-extract {file}",
+		"output":     "json",
+		"timeout":    "30s",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conv.Name != "Javadoc" || len(conv.Extensions) != 1 || conv.Output != "json" || conv.Timeout != 30*time.Second {
+		t.Errorf("unexpected converter: %+v", conv)
+	}
+	if strings.Join(conv.Command, " ") != "./scripts/javadoc-extract {file}" || len(conv.Command) != 2 {
+		t.Errorf("unexpected command: %q", conv.Command)
+	}
+
+	conv, err = loadConverter(map[string]interface{}{
+		"name":       "Pandoc",
+		"extensions": ".docx",
+		"command":    []interface{}{"pandoc", "--to", "gfm", "--metadata", "title=My Docs"},
+		"timeout":    90,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conv.Command) != 5 || conv.Command[4] != "title=My Docs" || conv.Timeout != 90*time.Second {
+		t.Errorf("unexpected converter: %+v", conv)
+	}
+
+	tests := []struct {
+		name  string
+		entry map[string]interface{}
+		err   string
+	}{
+		{"Unknown key", map[string]interface{}{"name": "X", "command": "x", "args": "y"}, "unknown key 'args'"},
+		{"No command", map[string]interface{}{"name": "X"}, "command is required"},
+		{"Bad timeout", map[string]interface{}{"name": "X", "command": "x", "timeout": "soon"}, "timeout must be a duration"},
+		{"Bad extensions", map[string]interface{}{"name": "X", "command": "x", "extensions": 7}, "extensions must be a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadConverter(tt.entry); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
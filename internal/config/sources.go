@@ -0,0 +1,373 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/gitsource"
+	"github.com/pgedge/pgedge-docloader/internal/glob"
+	"github.com/pgedge/pgedge-docloader/internal/s3source"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+	"github.com/pgedge/pgedge-docloader/internal/websource"
+)
+
+// loadSources loads the sources list from the config file. Each entry is a
+// map describing a local, Git, web or S3 source; strip-path, the crawl
+// limits and the S3 connection settings default to the global settings
+// given in defaults.
+func loadSources(raw interface{}, defaults types.SourceConfig) ([]types.SourceConfig, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid sources: expected a list of sources")
+	}
+
+	sources := make([]types.SourceConfig, 0, len(list))
+	for i, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid source %d: expected a map", i+1)
+		}
+
+		src, err := loadSource(entry, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source %d: %w", i+1, err)
+		}
+		sources = append(sources, src)
+	}
+
+	return sources, nil
+}
+
+// loadSource loads a single entry of the sources list
+func loadSource(entry map[string]interface{}, defaults types.SourceConfig) (types.SourceConfig, error) {
+	src := types.SourceConfig{
+		StripPath:         defaults.StripPath,
+		WebMaxDepth:       defaults.WebMaxDepth,
+		WebMaxPages:       defaults.WebMaxPages,
+		WebRateLimit:      defaults.WebRateLimit,
+		S3Endpoint:        defaults.S3Endpoint,
+		S3Region:          defaults.S3Region,
+		S3PathStyle:       defaults.S3PathStyle,
+		S3Profile:         defaults.S3Profile,
+		CustomColumns:     make(map[string]string),
+		CustomColumnTypes: make(map[string]string),
+	}
+
+	// A Git source has one URL, a web source may start from several; a web
+	// source's prefixes are URLs, an S3 source's are key prefixes
+	var urls, prefixes []string
+
+	for key, value := range entry {
+		var err error
+		switch key {
+		case "name":
+			src.Name = fmt.Sprint(value)
+		case "type":
+			src.Type = strings.ToLower(fmt.Sprint(value))
+		case "path":
+			src.Paths, err = stringList(key, value)
+		case "url":
+			urls, err = stringList(key, value)
+		case "branch":
+			src.GitBranch = fmt.Sprint(value)
+		case "tag":
+			src.GitTag = fmt.Sprint(value)
+		case "refs":
+			src.GitRefs, err = stringList(key, value)
+		case "doc-path":
+			src.GitDocPath, err = stringList(key, value)
+		case "token-env":
+			src.GitTokenEnv = fmt.Sprint(value)
+		case "username":
+			src.GitUsername = fmt.Sprint(value)
+		case "ssh-key":
+			src.GitSSHKey = fmt.Sprint(value)
+		case "sitemap":
+			src.WebSitemap = fmt.Sprint(value)
+		case "prefix":
+			prefixes, err = stringList(key, value)
+		case "max-depth":
+			src.WebMaxDepth, err = intValue(key, value)
+		case "max-pages":
+			src.WebMaxPages, err = intValue(key, value)
+		case "rate-limit":
+			src.WebRateLimit, err = floatValue(key, value)
+		case "bucket":
+			src.S3Bucket = fmt.Sprint(value)
+		case "endpoint":
+			src.S3Endpoint = fmt.Sprint(value)
+		case "region":
+			src.S3Region = fmt.Sprint(value)
+		case "path-style":
+			v, ok := value.(bool)
+			if !ok {
+				return src, fmt.Errorf("path-style must be true or false")
+			}
+			src.S3PathStyle = v
+		case "profile":
+			src.S3Profile = fmt.Sprint(value)
+		case "strip-path":
+			v, ok := value.(bool)
+			if !ok {
+				return src, fmt.Errorf("strip-path must be true or false")
+			}
+			src.StripPath = v
+		case "custom-columns":
+			err = loadCustomColumns(src.CustomColumns, src.CustomColumnTypes, value)
+		default:
+			return src, fmt.Errorf("unknown key '%s'", key)
+		}
+		if err != nil {
+			return src, err
+		}
+	}
+
+	// The type may be left out when it is clear from the other keys
+	if src.Type == "" {
+		switch {
+		case src.S3Bucket != "":
+			src.Type = types.SourceS3
+		case src.WebSitemap != "":
+			src.Type = types.SourceWeb
+		case len(urls) > 0:
+			src.Type = types.SourceGit
+		default:
+			src.Type = types.SourceLocal
+		}
+	}
+
+	if src.Type == types.SourceS3 {
+		src.S3Prefixes = prefixes
+	} else {
+		src.WebPrefixes = prefixes
+	}
+	if src.Type == types.SourceWeb {
+		src.WebURLs = urls
+	} else if len(urls) > 1 {
+		return src, fmt.Errorf("url must be a single URL for a %s source", src.Type)
+	} else if len(urls) == 1 {
+		src.GitURL = urls[0]
+	}
+
+	if src.Name == "" {
+		src.Name = defaultSourceName(&src)
+	}
+
+	return src, nil
+}
+
+// defaultSourceName names a source after its paths, its repository URL
+// (without credentials) and ref, its sitemap or start URLs, or its bucket
+// and key prefixes
+func defaultSourceName(src *types.SourceConfig) string {
+	if src.Type == types.SourceS3 {
+		return types.S3SourceName(src.S3Bucket, src.S3Prefixes)
+	}
+	if src.Type == types.SourceWeb {
+		if src.WebSitemap != "" {
+			return src.WebSitemap
+		}
+		return strings.Join(src.WebURLs, ",")
+	}
+	if src.Type != types.SourceGit {
+		return strings.Join(src.Paths, ",")
+	}
+
+	name := gitsource.RedactURL(src.GitURL)
+	switch {
+	case src.GitBranch != "":
+		name += "@" + src.GitBranch
+	case src.GitTag != "":
+		name += "@" + src.GitTag
+	case len(src.GitRefs) > 0:
+		name += "@" + strings.Join(src.GitRefs, ",")
+	}
+	return name
+}
+
+// stringList converts a config file value that may be a single string or a
+// list of strings
+func stringList(key string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string or a list of strings", key)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%s must be a string or a list of strings", key)
+	}
+}
+
+// intValue converts a config file value that must be a whole number
+func intValue(key string, value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("%s must be a whole number", key)
+}
+
+// floatValue converts a config file value that must be a number
+func floatValue(key string, value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("%s must be a number", key)
+}
+
+// validatePatterns checks the glob patterns among source paths; a path that
+// exists is taken literally, even if it contains pattern characters
+func validatePatterns(paths []string) error {
+	for _, p := range paths {
+		if !glob.HasMeta(p) {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			continue
+		}
+		if err := glob.Validate(filepath.ToSlash(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSourceEntry validates a single source
+func validateSourceEntry(src *types.SourceConfig) error {
+	switch src.Type {
+	case types.SourceLocal:
+		if len(src.Paths) == 0 {
+			return fmt.Errorf("source '%s': a local source requires a path", src.Name)
+		}
+		if hasGitOptions(src) {
+			return fmt.Errorf("source '%s': Git options are not valid for a local source", src.Name)
+		}
+		if hasWebOptions(src) {
+			return fmt.Errorf("source '%s': web options are not valid for a local source", src.Name)
+		}
+		if src.S3Bucket != "" || len(src.S3Prefixes) > 0 {
+			return fmt.Errorf("source '%s': S3 options are not valid for a local source", src.Name)
+		}
+		if err := validatePatterns(src.Paths); err != nil {
+			return fmt.Errorf("source '%s': %w", src.Name, err)
+		}
+	case types.SourceGit:
+		if src.GitURL == "" {
+			return fmt.Errorf("source '%s': a Git source requires a url", src.Name)
+		}
+		if len(src.Paths) > 0 {
+			return fmt.Errorf("source '%s': use doc-path rather than path for a Git source", src.Name)
+		}
+		if src.GitBranch != "" && src.GitTag != "" {
+			return fmt.Errorf("source '%s': branch and tag are mutually exclusive", src.Name)
+		}
+		if len(src.GitRefs) > 0 && (src.GitBranch != "" || src.GitTag != "") {
+			return fmt.Errorf("source '%s': refs are mutually exclusive with branch and tag", src.Name)
+		}
+		if hasWebOptions(src) {
+			return fmt.Errorf("source '%s': web options are not valid for a Git source", src.Name)
+		}
+		if src.S3Bucket != "" || len(src.S3Prefixes) > 0 {
+			return fmt.Errorf("source '%s': S3 options are not valid for a Git source", src.Name)
+		}
+		if err := validatePatterns(src.GitDocPath); err != nil {
+			return fmt.Errorf("source '%s': %w", src.Name, err)
+		}
+	case types.SourceWeb:
+		if len(src.WebURLs) == 0 && src.WebSitemap == "" {
+			return fmt.Errorf("source '%s': a web source requires a url or sitemap", src.Name)
+		}
+		if len(src.Paths) > 0 {
+			return fmt.Errorf("source '%s': path is not valid for a web source", src.Name)
+		}
+		if hasGitOptions(src) {
+			return fmt.Errorf("source '%s': Git options are not valid for a web source", src.Name)
+		}
+		if err := websource.ValidateURLs(src.WebURLs, src.WebSitemap, src.WebPrefixes); err != nil {
+			return fmt.Errorf("source '%s': %w", src.Name, err)
+		}
+		if src.WebMaxDepth < 0 || src.WebMaxPages < 0 || src.WebRateLimit < 0 {
+			return fmt.Errorf("source '%s': max-depth, max-pages and rate-limit must not be negative", src.Name)
+		}
+		if src.S3Bucket != "" || len(src.S3Prefixes) > 0 {
+			return fmt.Errorf("source '%s': S3 options are not valid for a web source", src.Name)
+		}
+	case types.SourceS3:
+		if src.S3Bucket == "" {
+			return fmt.Errorf("source '%s': an S3 source requires a bucket", src.Name)
+		}
+		if len(src.Paths) > 0 {
+			return fmt.Errorf("source '%s': use prefix rather than path for an S3 source", src.Name)
+		}
+		if hasGitOptions(src) || hasWebOptions(src) {
+			return fmt.Errorf("source '%s': Git and web options are not valid for an S3 source", src.Name)
+		}
+		if err := s3source.ValidateEndpoint(src.S3Endpoint); err != nil {
+			return fmt.Errorf("source '%s': %w", src.Name, err)
+		}
+	default:
+		return fmt.Errorf("source '%s': unknown type '%s' (expected %s, %s, %s or %s)",
+			src.Name, src.Type, types.SourceLocal, types.SourceGit, types.SourceWeb, types.SourceS3)
+	}
+
+	for colName, colType := range src.CustomColumnTypes {
+		if !types.IsSupportedColumnType(colType) {
+			return fmt.Errorf("source '%s': unsupported type '%s' for custom column '%s'", src.Name, colType, colName)
+		}
+	}
+
+	return nil
+}
+
+// hasGitOptions returns true if any Git source option is set
+func hasGitOptions(src *types.SourceConfig) bool {
+	return src.GitURL != "" || src.GitBranch != "" || src.GitTag != "" ||
+		len(src.GitRefs) > 0 || len(src.GitDocPath) > 0 ||
+		src.GitTokenEnv != "" || src.GitUsername != "" || src.GitSSHKey != ""
+}
+
+// hasWebOptions returns true if any web source option other than the
+// crawl limits (which default to the global settings) is set
+func hasWebOptions(src *types.SourceConfig) bool {
+	return len(src.WebURLs) > 0 || src.WebSitemap != "" || len(src.WebPrefixes) > 0
+}
+
+// hasGitRefs returns true if any source loads several Git refs
+func hasGitRefs(sources []types.SourceConfig) bool {
+	for _, src := range sources {
+		if len(src.GitRefs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,931 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(asciidocConverter{}, "adoc")
+}
+
+// asciidocConverter converts AsciiDoc documents
+type asciidocConverter struct{}
+
+func (asciidocConverter) Name() string { return string(types.TypeAsciiDoc) }
+
+// Extensions leaves out .asc, which is more often an ASCII-armored PGP
+// signature or key than AsciiDoc
+func (asciidocConverter) Extensions() []string { return []string{".adoc", ".asciidoc"} }
+
+// Sniff recognizes documents whose first line, after any comments and
+// attribute entries, is an AsciiDoc document title (= Title)
+func (asciidocConverter) Sniff(content []byte) bool {
+	text := bytes.TrimPrefix(content, utf8BOM)
+	for len(text) > 0 {
+		var line []byte
+		line, text, _ = bytes.Cut(text, []byte("\n"))
+		s := strings.TrimRight(string(line), " \t\r")
+		if s == "" || strings.HasPrefix(s, "//") || adocAttributeRe.MatchString(s) {
+			continue
+		}
+		return adocDocTitleRe.MatchString(s)
+	}
+	return false
+}
+
+func (asciidocConverter) Convert(content []byte) (*Result, error) {
+	return convertAsciiDoc(content), nil
+}
+
+var (
+	adocDocTitleRe   = regexp.MustCompile(`^=\s+(\S.*)$`)
+	adocSectionRe    = regexp.MustCompile(`^(={1,6})\s+(\S.*)$`)
+	adocAttributeRe  = regexp.MustCompile(`^:(!?)(\w[\w-]*)(!?):(?:\s+(.*))?$`)
+	adocAttrRefRe    = regexp.MustCompile(`\\?\{(\w[\w-]*)\}`)
+	adocAnchorRe     = regexp.MustCompile(`^\[\[[^\]]*\]\]$`)
+	adocBlockAttrRe  = regexp.MustCompile(`^\[([^\[\]]*)\]$`)
+	adocBlockTitleRe = regexp.MustCompile(`^\.([^.\s].*)$`)
+	adocAdmonitionRe = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|CAUTION|WARNING):\s+(.*)$`)
+	adocIncludeRe    = regexp.MustCompile(`^include::([^\[]+)\[[^\]]*\]$`)
+	adocImageRe      = regexp.MustCompile(`^image::([^\[]+)\[([^\]]*)\]$`)
+	adocListRe       = regexp.MustCompile(`^(\*{1,5}|-|\.{1,5})\s+(.*)$`)
+	adocDescListRe   = regexp.MustCompile(`^(\S.*?)(?:::|;;)(?:\s+(.*))?$`)
+	adocConditionRe  = regexp.MustCompile(`^(ifdef|ifndef|ifeval|endif)::([^\[]*)\[(.*)\]$`)
+	adocCellSpecRe   = regexp.MustCompile(`^(?:\d+\*)?(?:\d*\.?\d*\+)?[<^>]?(?:\.[<^>])?[adehlmsv]?$`)
+
+	// Spans that inline formatting must not change: code, cross
+	// references and the targets of macros such as links
+	adocProtectedRe = regexp.MustCompile("`[^`]*`|<<[^>]*>>|\\b(?:https?|ftp|mailto|link|image|xref|kbd|btn|menu):[^\\s\\[]*")
+
+	adocLiteralRe       = regexp.MustCompile("`\\+([^`]*)\\+`")
+	adocPassthroughRe   = regexp.MustCompile(`(^|[^\w+])\+([^+\s](?:[^+]*[^+\s])?)\+([^\w+]|$)`)
+	adocStrongRe        = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*([^\w*]|$)`)
+	adocEmphasisRe      = regexp.MustCompile(`(^|[^\w_])_([^_\s](?:[^_]*[^_\s])?)_([^\w_]|$)`)
+	adocUnconstrainedRe = regexp.MustCompile(`__([^_]+)__`)
+	adocXrefRe          = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]*))?>>`)
+	adocXrefMacroRe     = regexp.MustCompile(`xref:([^\s\[]+)\[([^\]]*)\]`)
+	adocInlineImageRe   = regexp.MustCompile(`image:([^\s\[:][^\s\[]*)\[([^\]]*)\]`)
+	adocLinkMacroRe     = regexp.MustCompile(`link:([^\s\[]+)\[([^\]]*)\]`)
+	adocURLRe           = regexp.MustCompile(`\b((?:https?|ftp)://[^\s\[]+|mailto:[^\s\[]+)\[([^\]]*)\]`)
+	adocKbdRe           = regexp.MustCompile(`kbd:\[([^\]]*)\]`)
+	adocBtnRe           = regexp.MustCompile(`btn:\[([^\]]*)\]`)
+	adocMenuRe          = regexp.MustCompile(`menu:([^\s\[]+)\[([^\]]*)\]`)
+	adocInlineAnchorRe  = regexp.MustCompile(`\[\[[^\]]*\]\]`)
+	adocBlankLinesRe    = regexp.MustCompile(`\n{3,}`)
+)
+
+// adocAdmonitions are the labels of the admonition styles
+var adocAdmonitions = map[string]string{
+	"NOTE":      "Note",
+	"TIP":       "Tip",
+	"IMPORTANT": "Important",
+	"CAUTION":   "Caution",
+	"WARNING":   "Warning",
+}
+
+// adocBuiltinAttributes are the character replacement attributes that are
+// always defined
+var adocBuiltinAttributes = map[string]string{
+	"nbsp":  " ",
+	"sp":    " ",
+	"empty": "",
+	"amp":   "&",
+	"lt":    "<",
+	"gt":    ">",
+}
+
+// adocDocument holds the state of an AsciiDoc conversion
+type adocDocument struct {
+	attributes   map[string]string
+	firstSection string // Title of the first section, if there is no document title
+}
+
+// adocBlockAttributes are the attributes given in a [...] line before a
+// block, such as [source,go] or [cols="1,2",options="header"]
+type adocBlockAttributes struct {
+	style      string
+	positional []string
+	named      map[string]string
+	options    map[string]bool
+}
+
+// convertAsciiDoc converts AsciiDoc to Markdown, taking the title from the
+// document title (or the first section's) and the metadata from the attributes
+// in the document header
+func convertAsciiDoc(content []byte) *Result {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(content, utf8BOM)), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	doc := &adocDocument{attributes: make(map[string]string)}
+	title, metadata, body := doc.parseHeader(lines)
+	body = doc.preprocess(body)
+
+	var out []string
+	if title != "" {
+		out = append(out, "# "+doc.inline(title), "")
+	}
+	out = append(out, doc.convertBlocks(body)...)
+
+	markdown := adocBlankLinesRe.ReplaceAllString(strings.Join(out, "\n"), "\n\n")
+
+	if title == "" {
+		title = doc.firstSection
+	}
+	result := &Result{
+		Markdown: strings.TrimSpace(markdown) + "\n",
+		Title:    strings.TrimSpace(doc.substitute(title)),
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+	return result
+}
+
+// parseHeader reads the document header: the document title, followed by
+// optional author and revision lines and attribute entries. It returns the
+// title, the header's metadata and the rest of the document.
+func (d *adocDocument) parseHeader(lines []string) (string, map[string]interface{}, []string) {
+	metadata := make(map[string]interface{})
+	i := 0
+
+	// Attribute entries and comments may come before the title
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if line == "" || (strings.HasPrefix(line, "//") && !isAdocDelimiter(line)) {
+			continue
+		}
+		if !d.setAttribute(line, metadata) {
+			break
+		}
+	}
+	if i == len(lines) {
+		return "", metadata, nil
+	}
+
+	m := adocDocTitleRe.FindStringSubmatch(strings.TrimRight(lines[i], " \t"))
+	if m == nil {
+		return "", metadata, lines[i:]
+	}
+	title := m[1]
+	d.attributes["doctitle"] = title
+
+	// The header ends at the first blank line
+	headerLine := 0
+	for i++; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "//") || d.setAttribute(line, metadata) {
+			continue
+		}
+		headerLine++
+		switch headerLine {
+		case 1:
+			parseAdocAuthor(line, metadata)
+		case 2:
+			parseAdocRevision(line, metadata)
+		}
+	}
+	for key, value := range metadata {
+		if s, ok := value.(string); ok {
+			d.attributes[key] = s
+		}
+	}
+	return title, metadata, lines[i:]
+}
+
+// parseAdocAuthor reads an author line, such as "Jane Doe <jane@example.com>"
+func parseAdocAuthor(line string, metadata map[string]interface{}) {
+	author, email, found := strings.Cut(line, "<")
+	metadata["author"] = strings.TrimSpace(author)
+	if found {
+		metadata["email"] = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">"))
+	}
+}
+
+// parseAdocRevision reads a revision line, such as "v1.2, 2026-01-15: Draft"
+func parseAdocRevision(line string, metadata map[string]interface{}) {
+	rest, remark, found := strings.Cut(line, ":")
+	if found {
+		metadata["revremark"] = strings.TrimSpace(remark)
+	}
+	number, date, found := strings.Cut(rest, ",")
+	if !found && !strings.HasPrefix(strings.TrimSpace(number), "v") {
+		number, date = "", number
+	}
+	if number = strings.TrimPrefix(strings.TrimSpace(number), "v"); number != "" {
+		metadata["revnumber"] = number
+	}
+	if date = strings.TrimSpace(date); date != "" {
+		metadata["revdate"] = date
+	}
+}
+
+// setAttribute applies an attribute entry (:name: value, or :name!: to
+// unset it), recording it in metadata if that is not nil. It returns false
+// if the line is not an attribute entry.
+func (d *adocDocument) setAttribute(line string, metadata map[string]interface{}) bool {
+	m := adocAttributeRe.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	name := strings.ToLower(m[2])
+	if m[1] == "!" || m[3] == "!" {
+		delete(d.attributes, name)
+		if metadata != nil {
+			delete(metadata, name)
+		}
+		return true
+	}
+
+	value := d.substitute(strings.TrimSpace(m[4]))
+	d.attributes[name] = value
+	if metadata != nil {
+		metadata[name] = value
+	}
+	return true
+}
+
+// preprocess applies the conditional directives (ifdef, ifndef, ifeval and
+// endif), using the attributes defined so far. ifeval expressions are not
+// evaluated, so their content is kept.
+func (d *adocDocument) preprocess(lines []string) []string {
+	defined := make(map[string]bool)
+	for name := range d.attributes {
+		defined[name] = true
+	}
+
+	var out []string
+	var stack []bool // Whether each enclosing conditional includes its content
+	skipping := func() bool {
+		for _, include := range stack {
+			if !include {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, " \t")
+		m := adocConditionRe.FindStringSubmatch(trimmed)
+		if m == nil {
+			if !skipping() {
+				if a := adocAttributeRe.FindStringSubmatch(trimmed); a != nil {
+					defined[strings.ToLower(a[2])] = a[1] == "" && a[3] == ""
+				}
+				out = append(out, line)
+			}
+			continue
+		}
+
+		directive, target, inlineContent := m[1], m[2], m[3]
+		if directive == "endif" {
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		include := true
+		if directive != "ifeval" {
+			include = adocCondition(target, defined)
+			if directive == "ifndef" {
+				include = !include
+			}
+		}
+		if inlineContent != "" && directive != "ifeval" {
+			// A single-line conditional, as in ifdef::env-github[Text]
+			if include && !skipping() {
+				out = append(out, inlineContent)
+			}
+			continue
+		}
+		stack = append(stack, include)
+	}
+	return out
+}
+
+// adocCondition evaluates the attribute names of an ifdef directive: any of
+// a,b or all of a+b
+func adocCondition(target string, defined map[string]bool) bool {
+	if strings.Contains(target, "+") {
+		for _, name := range strings.Split(target, "+") {
+			if !defined[strings.ToLower(strings.TrimSpace(name))] {
+				return false
+			}
+		}
+		return true
+	}
+	for _, name := range strings.Split(target, ",") {
+		if defined[strings.ToLower(strings.TrimSpace(name))] {
+			return true
+		}
+	}
+	return false
+}
+
+// convertBlocks converts a sequence of AsciiDoc lines to Markdown
+func (d *adocDocument) convertBlocks(lines []string) []string {
+	var out []string
+	var attrs *adocBlockAttributes
+	previousBlank := true
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+
+		if isAdocDelimiter(line) {
+			end := i + 1
+			for end < len(lines) && strings.TrimRight(lines[end], " \t") != line {
+				end++
+			}
+			out = append(out, d.convertDelimited(line, lines[i+1:min(end, len(lines))], attrs)...)
+			out = append(out, "")
+			attrs = nil
+			i = end
+			previousBlank = true
+			continue
+		}
+
+		switch {
+		case line == "":
+			out = append(out, "")
+			previousBlank = true
+			continue
+		case strings.HasPrefix(line, "//"):
+			continue
+		case d.setAttribute(line, nil):
+			continue
+		case adocAnchorRe.MatchString(line):
+			continue
+		case adocBlockAttrRe.MatchString(line):
+			attrs = parseAdocBlockAttributes(adocBlockAttrRe.FindStringSubmatch(line)[1])
+			continue
+		case adocBlockTitleRe.MatchString(line) && previousBlank:
+			out = append(out, "**"+d.inline(adocBlockTitleRe.FindStringSubmatch(line)[1])+"**", "")
+			continue
+		}
+
+		// A paragraph given a style by a [...] line, such as [source,go]
+		// or [NOTE], runs to the next blank line
+		if attrs != nil && attrs.style != "" && previousBlank {
+			end := i
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			kind := ""
+			switch {
+			case attrs.style == "source" || attrs.style == "listing":
+				kind = "----"
+			case attrs.style == "literal":
+				kind = "...."
+			case attrs.style == "quote" || attrs.style == "verse":
+				kind = "____"
+			case adocAdmonitions[attrs.style] != "":
+				kind = "===="
+			}
+			if kind != "" {
+				out = append(out, d.convertDelimited(kind, lines[i:end], attrs)...)
+				attrs = nil
+				i = end - 1
+				previousBlank = false
+				continue
+			}
+		}
+		attrs = nil
+
+		// An indented paragraph is literal text, though list items may be
+		// indented
+		trimmed := strings.TrimLeft(line, " \t")
+		if previousBlank && trimmed != line && !adocListRe.MatchString(trimmed) {
+			end := i
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			out = append(out, adocFence(lines[i:end], "", true)...)
+			i = end - 1
+			previousBlank = false
+			continue
+		}
+
+		if m := adocAdmonitionRe.FindStringSubmatch(line); m != nil {
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && !isAdocDelimiter(strings.TrimRight(lines[end], " \t")) {
+				end++
+			}
+			paragraph := append([]string{m[2]}, lines[i+1:end]...)
+			out = append(out, adocAdmonition(m[1], d.convertBlocks(paragraph))...)
+			i = end - 1
+			previousBlank = false
+			continue
+		}
+
+		out = append(out, d.convertLine(trimmed))
+		previousBlank = false
+	}
+	return out
+}
+
+// convertLine converts a single line outside a delimited block
+func (d *adocDocument) convertLine(line string) string {
+	if m := adocSectionRe.FindStringSubmatch(line); m != nil {
+		if d.firstSection == "" {
+			d.firstSection = m[2]
+		}
+		return strings.Repeat("#", len(m[1])) + " " + d.inline(m[2])
+	}
+	if m := adocIncludeRe.FindStringSubmatch(line); m != nil {
+		// The included file can't be read here, since the document may
+		// not come from a filesystem; link to it instead
+		target := d.substitute(m[1])
+		return "[" + path.Base(target) + "](" + target + ")"
+	}
+	if m := adocImageRe.FindStringSubmatch(line); m != nil {
+		alt, _, _ := strings.Cut(m[2], ",")
+		return "![" + d.substitute(alt) + "](" + d.substitute(m[1]) + ")"
+	}
+
+	switch line {
+	case "'''", "---", "***":
+		return "---"
+	case "<<<":
+		return ""
+	case "+":
+		// A list continuation
+		return ""
+	}
+
+	if m := adocListRe.FindStringSubmatch(line); m != nil {
+		level := len(m[1])
+		if m[1] == "-" {
+			level = 1
+		}
+		marker, indent := "- ", strings.Repeat("  ", level-1)
+		if m[1][0] == '.' {
+			marker, indent = "1. ", strings.Repeat("   ", level-1)
+		}
+		return indent + marker + d.inline(m[2])
+	}
+	if m := adocDescListRe.FindStringSubmatch(line); m != nil {
+		term := "- **" + d.inline(m[1]) + "**"
+		if m[2] == "" {
+			return term
+		}
+		return term + ": " + d.inline(m[2])
+	}
+
+	// A trailing + is a hard line break
+	if strings.HasSuffix(line, " +") {
+		return d.inline(strings.TrimSuffix(line, " +")) + "\\"
+	}
+	return d.inline(line)
+}
+
+// convertDelimited converts a delimited block, given its opening delimiter,
+// its lines and the attributes given before it
+func (d *adocDocument) convertDelimited(delimiter string, lines []string, attrs *adocBlockAttributes) []string {
+	if attrs == nil {
+		attrs = &adocBlockAttributes{}
+	}
+
+	switch {
+	case delimiter == "--":
+		if adocAdmonitions[attrs.style] != "" {
+			return adocAdmonition(attrs.style, d.convertBlocks(lines))
+		}
+		return d.convertBlocks(lines)
+	case strings.HasPrefix(delimiter, "|="):
+		return d.convertTable(lines, attrs)
+	}
+
+	switch delimiter[0] {
+	case '-':
+		language := ""
+		if attrs.style == "source" {
+			language = d.attributes["source-language"]
+			if len(attrs.positional) > 1 {
+				language = attrs.positional[1]
+			}
+		}
+		return adocFence(lines, language, false)
+	case '.':
+		return adocFence(lines, "", false)
+	case '+':
+		return lines
+	case '/':
+		return nil
+	case '=':
+		if adocAdmonitions[attrs.style] != "" {
+			return adocAdmonition(attrs.style, d.convertBlocks(lines))
+		}
+		return d.convertBlocks(lines)
+	case '_', '*':
+		quoted := adocBlockquote(d.convertBlocks(lines))
+		if attrs.style == "quote" || attrs.style == "verse" {
+			var attribution []string
+			for _, s := range attrs.positional[min(1, len(attrs.positional)):] {
+				if s != "" {
+					attribution = append(attribution, d.inline(s))
+				}
+			}
+			if len(attribution) > 0 {
+				quoted = append(quoted, ">", "> — "+strings.Join(attribution, ", "))
+			}
+		}
+		return quoted
+	}
+	return d.convertBlocks(lines)
+}
+
+// convertTable converts a table (between |=== delimiters) to a Markdown
+// table. Markdown tables need a header row, so one without a header is
+// given an empty one.
+func (d *adocDocument) convertTable(lines []string, attrs *adocBlockAttributes) []string {
+	var cells []string
+	firstRowCells := 0
+	implicitHeader := false
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := splitAdocCells(line)
+		if len(parts) == 1 {
+			// A continuation of the previous cell
+			if len(cells) > 0 {
+				cells[len(cells)-1] += " " + line
+			}
+			continue
+		}
+
+		first := strings.TrimSpace(parts[0])
+		if first != "" && !adocCellSpecRe.MatchString(first) && len(cells) > 0 {
+			cells[len(cells)-1] += " " + first
+		}
+		if len(cells) == 0 {
+			firstRowCells = len(parts) - 1
+			implicitHeader = i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == ""
+		}
+		for _, cell := range parts[1:] {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+	}
+
+	columns := adocColumnCount(attrs.named["cols"])
+	if columns == 0 {
+		columns = firstRowCells
+	}
+	if columns == 0 || len(cells) == 0 {
+		return nil
+	}
+
+	header := attrs.options["header"] || (implicitHeader && !attrs.options["noheader"])
+	var rows [][]string
+	for start := 0; start < len(cells); start += columns {
+		row := make([]string, columns)
+		for j := 0; j < columns && start+j < len(cells); j++ {
+			row[j] = strings.ReplaceAll(d.inline(cells[start+j]), "|", "\\|")
+		}
+		rows = append(rows, row)
+	}
+	if !header {
+		rows = append([][]string{make([]string, columns)}, rows...)
+	}
+
+	separator := make([]string, columns)
+	for j := range separator {
+		separator[j] = "---"
+	}
+	out := []string{adocTableRow(rows[0]), adocTableRow(separator)}
+	for _, row := range rows[1:] {
+		out = append(out, adocTableRow(row))
+	}
+	return out
+}
+
+// adocTableRow formats a row of a Markdown table
+func adocTableRow(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// splitAdocCells splits a table line at the cell separators, which are not
+// escaped with a backslash. The first part is the text before the first
+// separator.
+func splitAdocCells(line string) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case line[i] == '|':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	return append(parts, current.String())
+}
+
+// adocColumnCount returns the number of columns given by a table's cols
+// attribute, such as "1,2,1" or "3*", or 0 if there is none
+func adocColumnCount(cols string) int {
+	cols = strings.Trim(strings.TrimSpace(cols), `"'`)
+	if cols == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(cols); err == nil {
+		return n
+	}
+
+	count := 0
+	for _, col := range strings.Split(cols, ",") {
+		if repeat, _, found := strings.Cut(strings.TrimSpace(col), "*"); found {
+			if n, err := strconv.Atoi(repeat); err == nil {
+				count += n
+				continue
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// parseAdocBlockAttributes parses the contents of a block attribute line.
+// The first positional attribute is the block's style, and may carry
+// #id, .role and %option shorthands.
+func parseAdocBlockAttributes(list string) *adocBlockAttributes {
+	attrs := &adocBlockAttributes{
+		named:   make(map[string]string),
+		options: make(map[string]bool),
+	}
+
+	for i, part := range splitAdocAttributes(list) {
+		if name, value, found := strings.Cut(part, "="); found && !strings.HasPrefix(part, `"`) {
+			name = strings.TrimSpace(name)
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			attrs.named[name] = value
+			if name == "options" || name == "opts" {
+				for _, option := range strings.Split(value, ",") {
+					attrs.options[strings.TrimSpace(option)] = true
+				}
+			}
+			continue
+		}
+
+		part = strings.Trim(part, `"'`)
+		if i == 0 {
+			style := part
+			if j := strings.IndexAny(style, "#.%"); j >= 0 {
+				for _, option := range strings.Split(style[j:], "%")[1:] {
+					option, _, _ = strings.Cut(option, ".")
+					option, _, _ = strings.Cut(option, "#")
+					attrs.options[option] = true
+				}
+				style = style[:j]
+			}
+			if adocAdmonitions[style] == "" {
+				style = strings.ToLower(style)
+			}
+			attrs.style = style
+			part = style
+		}
+		attrs.positional = append(attrs.positional, part)
+	}
+	return attrs
+}
+
+// splitAdocAttributes splits an attribute list at the commas outside
+// quotes
+func splitAdocAttributes(list string) []string {
+	var parts []string
+	var current strings.Builder
+	var quote byte
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	return append(parts, strings.TrimSpace(current.String()))
+}
+
+// isAdocDelimiter returns true if a line opens or closes a delimited block
+func isAdocDelimiter(line string) bool {
+	if line == "--" {
+		return true
+	}
+	if strings.HasPrefix(line, "|===") {
+		return strings.Trim(line[1:], "=") == ""
+	}
+	if len(line) < 4 || !strings.ContainsRune("-.=*_/+", rune(line[0])) {
+		return false
+	}
+	return strings.Trim(line, line[:1]) == ""
+}
+
+// adocFence formats lines as a fenced code block. Indented literal
+// paragraphs have their common indentation removed.
+func adocFence(lines []string, language string, dedent bool) []string {
+	if dedent {
+		indent := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		indent = max(indent, 0)
+		dedented := make([]string, len(lines))
+		for i, line := range lines {
+			if len(line) >= indent {
+				dedented[i] = line[indent:]
+			}
+		}
+		lines = dedented
+	}
+
+	fence := "```"
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fence = "~~~"
+		}
+	}
+	out := append([]string{fence + language}, lines...)
+	return append(out, fence)
+}
+
+// adocAdmonition formats converted Markdown as a quoted admonition, such as
+// "> **Note**"
+func adocAdmonition(style string, lines []string) []string {
+	return append([]string{"> **" + adocAdmonitions[style] + "**", ">"}, adocBlockquote(lines)...)
+}
+
+// adocBlockquote quotes converted Markdown, dropping leading and trailing
+// blank lines
+func adocBlockquote(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	quoted := make([]string, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			quoted[i] = ">"
+		} else {
+			quoted[i] = "> " + line
+		}
+	}
+	return quoted
+}
+
+// substitute replaces attribute references, such as {version}, with their
+// values; references to undefined attributes, and escaped references
+// (\{name}), are left as they are
+func (d *adocDocument) substitute(text string) string {
+	if !strings.Contains(text, "{") {
+		return text
+	}
+	return adocAttrRefRe.ReplaceAllStringFunc(text, func(ref string) string {
+		if strings.HasPrefix(ref, `\`) {
+			return ref[1:]
+		}
+		name := strings.ToLower(ref[1 : len(ref)-1])
+		if value, ok := d.attributes[name]; ok {
+			return value
+		}
+		if value, ok := adocBuiltinAttributes[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// inline converts the inline markup of a line of text: attribute
+// references, emphasis, links, images, cross references and UI macros
+func (d *adocDocument) inline(text string) string {
+	text = d.substitute(text)
+	text = adocInlineAnchorRe.ReplaceAllString(text, "")
+
+	// Literal monospace (`+text+`) and passthroughs (+text+) become code
+	text = adocLiteralRe.ReplaceAllString(text, "`$1`")
+	text = adocPassthroughRe.ReplaceAllString(text, "$1`$2`$3")
+
+	// Change emphasis only outside code spans and macro targets
+	var b strings.Builder
+	last := 0
+	for _, span := range adocProtectedRe.FindAllStringIndex(text, -1) {
+		b.WriteString(adocFormat(text[last:span[0]]))
+		b.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(adocFormat(text[last:]))
+	text = b.String()
+
+	text = adocXrefRe.ReplaceAllStringFunc(text, func(ref string) string {
+		m := adocXrefRe.FindStringSubmatch(ref)
+		label := strings.TrimSpace(m[2])
+		if label == "" {
+			label = m[1]
+		}
+		return "[" + label + "](#" + strings.TrimSpace(m[1]) + ")"
+	})
+	text = adocXrefMacroRe.ReplaceAllStringFunc(text, func(ref string) string {
+		m := adocXrefMacroRe.FindStringSubmatch(ref)
+		target, label := m[1], m[2]
+		if label == "" {
+			label = target
+		}
+		if !strings.Contains(target, "#") && !strings.Contains(target, ".") {
+			target = "#" + target
+		}
+		return "[" + label + "](" + target + ")"
+	})
+	text = adocInlineImageRe.ReplaceAllStringFunc(text, func(ref string) string {
+		m := adocInlineImageRe.FindStringSubmatch(ref)
+		alt, _, _ := strings.Cut(m[2], ",")
+		return "![" + alt + "](" + m[1] + ")"
+	})
+	text = adocLinkMacroRe.ReplaceAllStringFunc(text, adocLink(adocLinkMacroRe))
+	text = adocURLRe.ReplaceAllStringFunc(text, adocLink(adocURLRe))
+	text = adocKbdRe.ReplaceAllString(text, "`$1`")
+	text = adocBtnRe.ReplaceAllString(text, "**$1**")
+	text = adocMenuRe.ReplaceAllStringFunc(text, func(ref string) string {
+		m := adocMenuRe.FindStringSubmatch(ref)
+		items := []string{m[1]}
+		for _, item := range strings.Split(m[2], ">") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return "**" + strings.Join(items, " > ") + "**"
+	})
+	return text
+}
+
+// adocLink returns a function that converts a link macro matched by re to
+// a Markdown link
+func adocLink(re *regexp.Regexp) func(string) string {
+	return func(ref string) string {
+		m := re.FindStringSubmatch(ref)
+		label, _, _ := strings.Cut(m[2], ",")
+		label = strings.TrimSuffix(strings.Trim(label, `"`), "^")
+		if label == "" {
+			return "<" + m[1] + ">"
+		}
+		return "[" + label + "](" + m[1] + ")"
+	}
+}
+
+// adocFormat converts AsciiDoc emphasis to Markdown: *strong* to **strong**
+// and _emphasis_ to *emphasis*
+func adocFormat(text string) string {
+	if !strings.ContainsAny(text, "*_") {
+		return text
+	}
+	// Each pattern consumes the character after a match, so apply them
+	// twice to catch adjacent spans
+	for range 2 {
+		text = adocStrongRe.ReplaceAllString(text, "$1**$2**$3")
+	}
+	text = adocUnconstrainedRe.ReplaceAllString(text, "*$1*")
+	for range 2 {
+		text = adocEmphasisRe.ReplaceAllString(text, "$1*$2*$3")
+	}
+	return text
+}
@@ -0,0 +1,183 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestConvertAsciiDocSample(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/sample.adoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Default().Convert(content, types.TypeAsciiDoc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "Getting Started with pgEdge Widgets" {
+		t.Errorf("unexpected title: %q", result.Title)
+	}
+	for key, expected := range map[string]string{
+		"author":      "Jane Doe",
+		"email":       "jane@example.com",
+		"revnumber":   "2.1",
+		"revdate":     "2026-01-15",
+		"description": "How to install and configure the widgets",
+	} {
+		if result.Metadata[key] != expected {
+			t.Errorf("expected metadata %s = %q, got %v", key, expected, result.Metadata[key])
+		}
+	}
+
+	for _, expected := range []string{
+		"# Getting Started with pgEdge Widgets\n\nThis guide shows how to install **pgEdge Widgets** and configure it for *production* use.",
+		"See [configuration](#configuration) for the settings, or [Troubleshooting](#troubleshooting) if something goes wrong.",
+		"> **Note**\n>\n> Version 2.1 requires PostgreSQL 16 or later.",
+		"## Installation",
+		"```bash\nsudo dnf install widgets\n```",
+		"**Checking the service**\n\n```python\nimport widgets\n",
+		"> **Warning**\n>\n> Do not run the installer as root.\n>\n> It changes the ownership of `/var/lib/widgets`.",
+		"### Supported Platforms",
+		"| Platform | Versions |\n| --- | --- |\n| Rocky Linux | 8, 9 |\n| Ubuntu | 22.04, 24.04 |",
+		"[the reference](reference.adoc#settings)",
+		"[project site](https://example.com/widgets)",
+		"- Set `listen_address` to the address to listen on\n  - Use `*` for all addresses",
+		"`Ctrl+R` or **Service > Restart**",
+		"1. Edit the configuration file\n1. Reload the service",
+		"[advanced.adoc](partials/advanced.adoc)",
+		"This text appears everywhere else.",
+		"- **timeout**: Increase the `timeout` setting.",
+		"> Widgets make everything better.\n>\n> — Widget Team, Release Notes",
+		"![Widget architecture](images/architecture.png)",
+	} {
+		if !strings.Contains(result.Markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, result.Markdown)
+		}
+	}
+
+	for _, unexpected := range []string{":product:", "// Header", "on GitHub", "block comment", "[[", "ifdef", "|==="} {
+		if strings.Contains(result.Markdown, unexpected) {
+			t.Errorf("expected markdown not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestConvertAsciiDoc(t *testing.T) {
+	tests := []struct {
+		name     string
+		adoc     string
+		expected string
+		title    string
+	}{
+		{
+			name:     "Title from the first section",
+			adoc:     "== First Section\n\nText with a {missing} attribute.\n",
+			expected: "## First Section\n\nText with a {missing} attribute.\n",
+			title:    "First Section",
+		},
+		{
+			name:     "Section levels",
+			adoc:     "= Doc\n\n== Two\n\n=== Three\n\n===== Five\n",
+			expected: "# Doc\n\n## Two\n\n### Three\n\n##### Five\n",
+			title:    "Doc",
+		},
+		{
+			name:     "Attributes in the body",
+			adoc:     ":version: 1.0\n\n= Doc\n\nVersion {version}.\n\n:version: 2.0\nNow {version}, not \\{version}.\n",
+			expected: "# Doc\n\nVersion 1.0.\n\nNow 2.0, not {version}.\n",
+			title:    "Doc",
+		},
+		{
+			name:     "Admonition paragraph and block",
+			adoc:     "TIP: Use the\ndefault settings.\n\n[CAUTION]\nBack up first.\n",
+			expected: "> **Tip**\n>\n> Use the\n> default settings.\n\n> **Caution**\n>\n> Back up first.\n",
+		},
+		{
+			name:     "Literal paragraph and block",
+			adoc:     "Run:\n\n  $ widgets start\n  $ widgets status\n\n....\nraw *text*\n....\n",
+			expected: "Run:\n\n```\n$ widgets start\n$ widgets status\n```\n\n```\nraw *text*\n```\n",
+		},
+		{
+			name:     "Listing with a code fence",
+			adoc:     "[source,markdown]\n----\n```go\nx := 1\n```\n----\n",
+			expected: "~~~markdown\n```go\nx := 1\n```\n~~~\n",
+		},
+		{
+			name:     "Table without a header",
+			adoc:     "[cols=\"2*\"]\n|===\n|a |b\n|c |d\n|===\n",
+			expected: "|  |  |\n| --- | --- |\n| a | b |\n| c | d |\n",
+		},
+		{
+			name:     "Table cells with specifiers and pipes",
+			adoc:     "[%header,cols=2]\n|===\n|Name\n|Value\n\na|`x \\| y`\n|multi\nline\n|===\n",
+			expected: "| Name | Value |\n| --- | --- |\n| `x \\| y` | multi line |\n",
+		},
+		{
+			name:     "Inline markup",
+			adoc:     "A *bold* _italic_ and __word__s with `*code*`, C++ and link:docs/guide.html[the guide] or https://example.com[].\n",
+			expected: "A **bold** *italic* and *word*s with `*code*`, C++ and [the guide](docs/guide.html) or <https://example.com>.\n",
+		},
+		{
+			name:     "Cross references",
+			adoc:     "See <<intro>>, <<setup,Setup>> and xref:install[Installing] or xref:other.adoc[].\n",
+			expected: "See [intro](#intro), [Setup](#setup) and [Installing](#install) or [other.adoc](other.adoc).\n",
+		},
+		{
+			name:     "Hard line breaks and breaks",
+			adoc:     "First +\nsecond\n\n'''\n\n<<<\n",
+			expected: "First\\\nsecond\n\n---\n",
+		},
+		{
+			name:     "Conditionals",
+			adoc:     ":backend: postgres\n\nifdef::backend[Has a backend.]\nifdef::missing,backend[]\nAny.\nendif::[]\nifdef::missing+backend[]\nAll.\nendif::[]\nifeval::[{x} > 1]\nEvaluated.\nendif::[]\n",
+			expected: "Has a backend.\nAny.\nEvaluated.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertAsciiDoc([]byte(tt.adoc))
+			if result.Markdown != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result.Markdown)
+			}
+			if result.Title != tt.title {
+				t.Errorf("expected title %q, got %q", tt.title, result.Title)
+			}
+		})
+	}
+}
+
+func TestSniffAsciiDoc(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{"= User Guide\n\nText", true},
+		{"// Comment\n:toc:\n\n= User Guide\n", true},
+		{"== Section\n", false},
+		{"Plain text\n= Not a title\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := (asciidocConverter{}).Sniff([]byte(tt.content)); got != tt.expected {
+			t.Errorf("Sniff(%q) = %v, expected %v", tt.content, got, tt.expected)
+		}
+	}
+	if got := Default().DetectContentType("README", []byte("= Widgets\n\nText")); got != types.TypeAsciiDoc {
+		t.Errorf("expected an extension-less AsciiDoc file to be detected, got %v", got)
+	}
+}
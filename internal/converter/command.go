@@ -0,0 +1,220 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// DefaultCommandTimeout is the time an external converter is allowed for
+// each document, unless configured otherwise
+const DefaultCommandTimeout = time.Minute
+
+// FilePlaceholder in a converter command's arguments is replaced by the
+// path of a temporary file holding the document
+const FilePlaceholder = "{file}"
+
+// WithCommands returns a copy of the registry with the configured external
+// converters added, after the converters of r
+func (r *Registry) WithCommands(configs []types.ConverterConfig) (*Registry, error) {
+	reg := r.clone()
+	for _, cfg := range configs {
+		c, err := newCommandConverter(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("converter '%s': %w", cfg.Name, err)
+		}
+		if err := reg.register(c); err != nil {
+			return nil, fmt.Errorf("converter '%s': %w", cfg.Name, err)
+		}
+	}
+	return reg, nil
+}
+
+// commandConverter converts documents by running an external program,
+// which writes Markdown or a JSON object to stdout
+type commandConverter struct {
+	types.ConverterConfig
+}
+
+// commandOutput is the JSON object an external converter may write
+type commandOutput struct {
+	Title    string                 `json:"title"`
+	Content  string                 `json:"content"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// newCommandConverter checks an external converter's configuration and
+// fills in its defaults
+func newCommandConverter(cfg types.ConverterConfig) (*commandConverter, error) {
+	cfg.Name = strings.TrimSpace(cfg.Name)
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("a name is required")
+	}
+	if len(cfg.Command) == 0 || strings.TrimSpace(cfg.Command[0]) == "" {
+		return nil, fmt.Errorf("a command is required")
+	}
+
+	exts := make([]string, 0, len(cfg.Extensions))
+	for _, ext := range cfg.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+	cfg.Extensions = exts
+
+	switch cfg.Output = strings.ToLower(cfg.Output); cfg.Output {
+	case "":
+		cfg.Output = types.ConverterOutputMarkdown
+	case types.ConverterOutputMarkdown, types.ConverterOutputJSON:
+	default:
+		return nil, fmt.Errorf("invalid output '%s': expected %s or %s",
+			cfg.Output, types.ConverterOutputMarkdown, types.ConverterOutputJSON)
+	}
+
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("invalid timeout %s", cfg.Timeout)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultCommandTimeout
+	}
+
+	return &commandConverter{cfg}, nil
+}
+
+func (c *commandConverter) Name() string { return c.ConverterConfig.Name }
+
+func (c *commandConverter) Extensions() []string { return c.ConverterConfig.Extensions }
+
+// Sniff recognizes nothing: files are given to an external converter by
+// their extension or a format mapping
+func (c *commandConverter) Sniff(content []byte) bool { return false }
+
+// Convert runs the command, giving it the document on stdin or, if an
+// argument is {file}, in a temporary file. Anything the command writes to
+// stderr is returned as a warning.
+func (c *commandConverter) Convert(content []byte) (*Result, error) {
+	args := make([]string, len(c.Command)-1)
+	copy(args, c.Command[1:])
+
+	var stdin *bytes.Reader
+	usesFile := false
+	for _, arg := range args {
+		if strings.Contains(arg, FilePlaceholder) {
+			usesFile = true
+		}
+	}
+	if usesFile {
+		file, err := writeTempFile(content, c.tempFileExtension())
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(file)
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, FilePlaceholder, file)
+		}
+	} else {
+		stdin = bytes.NewReader(content)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Command[0], args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for programs the command started that still hold its
+	// output open once it has been killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s timed out after %s", c.Command[0], c.Timeout)
+	}
+	message := strings.TrimSpace(stderr.String())
+	if err != nil {
+		if message != "" {
+			return nil, fmt.Errorf("%s: %w: %s", c.Command[0], err, message)
+		}
+		return nil, fmt.Errorf("%s: %w", c.Command[0], err)
+	}
+
+	result, err := c.parseOutput(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Command[0], err)
+	}
+	if message != "" {
+		result.Warnings = append(result.Warnings, message)
+	}
+	return result, nil
+}
+
+// parseOutput reads the Markdown, or JSON object, the command wrote
+func (c *commandConverter) parseOutput(output []byte) (*Result, error) {
+	if c.Output == types.ConverterOutputMarkdown {
+		// Treat the output as a Markdown document, with its title and
+		// any front matter
+		return markdownConverter{}.Convert(output)
+	}
+
+	var out commandOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("invalid JSON output: %w", err)
+	}
+	title := strings.TrimSpace(out.Title)
+	if title == "" {
+		title = extractMarkdownTitle(out.Content)
+	}
+	return &Result{Markdown: out.Content, Title: title, Metadata: out.Metadata}, nil
+}
+
+// tempFileExtension returns the extension to give a temporary file holding
+// a document, so that programs that look at it can tell the format
+func (c *commandConverter) tempFileExtension() string {
+	if len(c.ConverterConfig.Extensions) > 0 {
+		return c.ConverterConfig.Extensions[0]
+	}
+	return ""
+}
+
+// writeTempFile writes a document to a temporary file, returning its path
+func writeTempFile(content []byte, ext string) (string, error) {
+	file, err := os.CreateTemp("", "pgedge-docloader-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return file.Name(), nil
+}
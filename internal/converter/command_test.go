@@ -0,0 +1,168 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestCommandConverter(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	tests := []struct {
+		name     string
+		cfg      types.ConverterConfig
+		content  string
+		markdown string
+		title    string
+		metadata string // Value of the "source" metadata key
+		warnings int
+		err      string
+	}{
+		{
+			name:     "Markdown from stdin",
+			cfg:      types.ConverterConfig{Command: []string{"sh", "-c", "echo '# Converted'; cat"}},
+			content:  "body text",
+			markdown: "# Converted\nbody text",
+			title:    "Converted",
+		},
+		{
+			name:     "Markdown front matter",
+			cfg:      types.ConverterConfig{Command: []string{"sh", "-c", "printf -- '---\\nsource: script\\n---\\n\\n# Title\\n'"}},
+			markdown: "---\nsource: script\n---\n\n# Title\n",
+			title:    "Title",
+			metadata: "script",
+		},
+		{
+			name:     "File argument",
+			cfg:      types.ConverterConfig{Extensions: []string{"wiki"}, Command: []string{"sh", "-c", `case "$1" in *.wiki) cat "$1";; esac`, "sh", "{file}"}},
+			content:  "# From a file",
+			markdown: "# From a file",
+			title:    "From a file",
+		},
+		{
+			name:     "JSON output",
+			cfg:      types.ConverterConfig{Output: "JSON", Command: []string{"sh", "-c", `echo '{"title": "Class Foo", "content": "Docs", "metadata": {"source": "javadoc"}}'`}},
+			markdown: "Docs",
+			title:    "Class Foo",
+			metadata: "javadoc",
+		},
+		{
+			name:     "JSON title from content",
+			cfg:      types.ConverterConfig{Output: "json", Command: []string{"sh", "-c", `printf '%s' '{"content": "# Heading\n\nText"}'`}},
+			markdown: "# Heading\n\nText",
+			title:    "Heading",
+		},
+		{
+			name:     "Stderr warning",
+			cfg:      types.ConverterConfig{Command: []string{"sh", "-c", "echo 'unknown macro' >&2; cat"}},
+			content:  "text",
+			markdown: "text",
+			warnings: 1,
+		},
+		{
+			name: "Invalid JSON",
+			cfg:  types.ConverterConfig{Output: "json", Command: []string{"sh", "-c", "echo not json"}},
+			err:  "invalid JSON output",
+		},
+		{
+			name: "Failure",
+			cfg:  types.ConverterConfig{Command: []string{"sh", "-c", "echo 'bad input' >&2; exit 3"}},
+			err:  "exit status 3: bad input",
+		},
+		{
+			name: "Timeout",
+			cfg:  types.ConverterConfig{Command: []string{"sh", "-c", "sleep 5"}, Timeout: 100 * time.Millisecond},
+			err:  "timed out after 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Name = "Script"
+			c, err := newCommandConverter(tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := c.Convert([]byte(tt.content))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.TrimSpace(result.Markdown) != strings.TrimSpace(tt.markdown) || result.Title != tt.title {
+				t.Errorf("unexpected result: %q %q", result.Markdown, result.Title)
+			}
+			if source, _ := result.Metadata["source"].(string); source != tt.metadata {
+				t.Errorf("expected source metadata %q, got %v", tt.metadata, result.Metadata)
+			}
+			if len(result.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestWithCommands(t *testing.T) {
+	formats, err := Default().WithCommands([]types.ConverterConfig{
+		{Name: "Javadoc", Extensions: []string{".JAVA"}, Command: []string{"javadoc-extract"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := formats.DetectDocumentType("src/Foo.java"); got != "Javadoc" {
+		t.Errorf("expected .java files to use the converter, got %v", got)
+	}
+	if _, err := formats.ParseFormat("javadoc"); err != nil {
+		t.Errorf("expected the converter to be usable in format mappings: %v", err)
+	}
+	if mapped, err := formats.WithFormatMap(map[string]string{"*.jav": "javadoc"}); err != nil ||
+		mapped.DetectDocumentType("Foo.jav") != "Javadoc" {
+		t.Errorf("expected a mapping to the converter to apply, got %v", err)
+	}
+
+	// The registry the converters were added to is unchanged
+	if got := Default().DetectDocumentType("src/Foo.java"); got != types.TypeUnknown {
+		t.Errorf("expected the default registry not to have the converter, got %v", got)
+	}
+	if _, err := Default().ParseFormat("javadoc"); err == nil {
+		t.Error("expected the default registry not to know the converter's name")
+	}
+
+	for _, tt := range []struct {
+		name string
+		cfg  types.ConverterConfig
+		err  string
+	}{
+		{"No name", types.ConverterConfig{Command: []string{"x"}}, "a name is required"},
+		{"No command", types.ConverterConfig{Name: "X"}, "a command is required"},
+		{"Bad output", types.ConverterConfig{Name: "X", Command: []string{"x"}, Output: "html"}, "invalid output 'html'"},
+		{"Built-in name", types.ConverterConfig{Name: "markdown", Command: []string{"x"}}, "format 'markdown' is already registered"},
+		{"Built-in extension", types.ConverterConfig{Name: "X", Extensions: []string{".rst"}, Command: []string{"x"}}, "extension .rst is already registered"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Default().WithCommands([]types.ConverterConfig{tt.cfg}); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
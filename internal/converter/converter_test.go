@@ -28,14 +28,24 @@ func TestDetectDocumentType(t *testing.T) {
 		{"RST file", "test.rst", types.TypeReStructuredText},
 		{"SGML file", "test.sgml", types.TypeSGML},
 		{"SGM file", "test.sgm", types.TypeSGML},
-		{"XML file", "test.xml", types.TypeSGML},
-		{"Unknown file", "test.txt", types.TypeUnknown},
+		{"AsciiDoc file", "guide.ADOC", types.TypeAsciiDoc},
+		{"AsciiDoc long extension", "guide.asciidoc", types.TypeAsciiDoc},
+		{"PGP armor not AsciiDoc", "release.tar.gz.asc", types.TypeUnknown},
+		{"Markdown long extension", "test.markdown", types.TypeMarkdown},
+		{"MDX file", "docs/test.MDX", types.TypeMarkdown},
+		{"MKD file", "test.mkd", types.TypeMarkdown},
+		{"XHTML file", "test.xhtml", types.TypeHTML},
+		{"Man page", "widgetctl.8", types.TypeManPage},
+		{"Text file with a text extension", "notes.text", types.TypePlainText},
+		{"XML file is sniffed", "test.xml", types.TypeUnknown},
+		{"Text file is sniffed", "test.txt", types.TypeUnknown},
 		{"No extension", "test", types.TypeUnknown},
+		{"Unknown file", "test.png", types.TypeUnknown},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := DetectDocumentType(tt.filename)
+			result := Default().DetectDocumentType(tt.filename)
 			if result != tt.expected {
 				t.Errorf("expected %v, got %v", tt.expected, result)
 			}
@@ -145,18 +155,22 @@ title: Frontmatter Title
 This is content.
 `)
 
-	result, title, err := processMarkdown(markdown)
+	result, err := markdownConverter{}.Convert(markdown)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if title != "Main Title" {
-		t.Errorf("expected title 'Main Title', got '%s'", title)
+	if result.Title != "Main Title" {
+		t.Errorf("expected title 'Main Title', got '%s'", result.Title)
 	}
 
-	if result != string(markdown) {
+	if result.Markdown != string(markdown) {
 		t.Error("markdown should be unchanged")
 	}
+
+	if result.Metadata["title"] != "Frontmatter Title" {
+		t.Errorf("expected the front matter as metadata, got %v", result.Metadata)
+	}
 }
 
 func TestExtractMarkdownTitle(t *testing.T) {
@@ -266,14 +280,19 @@ func TestIsSupported(t *testing.T) {
 		{"RST supported", "test.rst", true},
 		{"SGML supported", "test.sgml", true},
 		{"SGM supported", "test.sgm", true},
+		{"AsciiDoc supported", "test.adoc", true},
+		{"Notebook supported", "test.ipynb", true},
+		{"Man page supported", "tool.1", true},
 		{"XML supported", "test.xml", true},
-		{"TXT not supported", "test.txt", false},
-		{"Unknown not supported", "test", false},
+		{"TXT sniffed", "test.txt", true},
+		{"No extension sniffed", "docs/README", true},
+		{"Dot file not supported", ".gitignore", false},
+		{"Unknown not supported", "test.png", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := IsSupported(tt.filename)
+			result := Default().IsSupported(tt.filename)
 			if result != tt.expected {
 				t.Errorf("expected %v, got %v", tt.expected, result)
 			}
@@ -282,9 +301,11 @@ func TestIsSupported(t *testing.T) {
 }
 
 func TestGetSupportedExtensions(t *testing.T) {
-	exts := GetSupportedExtensions()
+	exts := Default().GetSupportedExtensions()
 
-	expected := []string{".html", ".htm", ".md", ".rst", ".sgml", ".sgm", ".xml"}
+	expected := []string{".adoc", ".asciidoc", ".html", ".htm", ".xhtml", ".ipynb",
+		".1", ".2", ".3", ".4", ".5", ".6", ".7", ".8", ".9", ".man", ".md", ".markdown",
+		".mdx", ".mkd", ".rst", ".sgml", ".sgm", ".text", ".xml", ".txt"}
 
 	if len(exts) != len(expected) {
 		t.Errorf("expected %d extensions, got %d", len(expected), len(exts))
@@ -817,3 +838,84 @@ func TestConvertSGMLTags(t *testing.T) {
 		})
 	}
 }
+
+// testConverter is a converter for a made-up format, registered by
+// TestRegister
+type testConverter struct{}
+
+func (testConverter) Name() string { return "Test Format" }
+
+func (testConverter) Extensions() []string { return []string{".testfmt"} }
+
+func (testConverter) Sniff(content []byte) bool {
+	return strings.HasPrefix(string(content), "TESTFMT\n")
+}
+
+func (testConverter) Convert(content []byte) (*Result, error) {
+	body := strings.TrimPrefix(string(content), "TESTFMT\n")
+	return &Result{Markdown: "# " + body, Title: body, Metadata: map[string]interface{}{"format": "test"}}, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register(testConverter{}, "testfmt")
+	testType := types.DocumentType("Test Format")
+
+	if got := Default().DetectDocumentType("notes.TESTFMT"); got != testType {
+		t.Errorf("expected the registered extension to be detected, got %v", got)
+	}
+	if got := Default().DetectContentType("notes", []byte("TESTFMT\nSniffed")); got != testType {
+		t.Errorf("expected the registered converter to sniff content, got %v", got)
+	}
+	for _, name := range []string{"Test Format", "testfmt"} {
+		if got, err := Default().ParseFormat(name); err != nil || got != testType {
+			t.Errorf("ParseFormat(%s) = %v, %v", name, got, err)
+		}
+	}
+
+	result, err := Default().Convert([]byte("TESTFMT\nHello"), testType)
+	if err != nil || result.Markdown != "# Hello" || result.Title != "Hello" || result.Metadata["format"] != "test" {
+		t.Errorf("unexpected conversion: %+v, error %v", result, err)
+	}
+	if _, err := Default().Convert(nil, types.DocumentType("Nonexistent")); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+
+	// Built-in converters are in the registry too
+	if c, ok := Default().Lookup(types.TypeMarkdown); !ok || c.Name() != "Markdown" {
+		t.Error("expected the Markdown converter to be registered")
+	}
+
+	duplicates := []struct {
+		name    string
+		c       Converter
+		aliases []string
+	}{
+		{"format", testConverter{}, nil},
+		{"alias", otherConverter{ext: ".other"}, []string{"md"}},
+		{"extension", otherConverter{ext: ".md"}, nil},
+	}
+	for _, tt := range duplicates {
+		t.Run("Duplicate "+tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected Register to panic for a duplicate %s", tt.name)
+				}
+			}()
+			Register(tt.c, tt.aliases...)
+		})
+	}
+	if _, err := Default().ParseFormat("Other Format"); err == nil {
+		t.Error("expected a rejected converter not to be registered")
+	}
+}
+
+// otherConverter is a converter with a given extension, for testing
+// conflicting registrations
+type otherConverter struct {
+	testConverter
+	ext string
+}
+
+func (otherConverter) Name() string { return "Other Format" }
+
+func (c otherConverter) Extensions() []string { return []string{c.ext} }
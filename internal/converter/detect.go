@@ -0,0 +1,294 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/glob"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// Extension maps a file extension to the format of files that have it
+type Extension struct {
+	Extension string
+	Type      types.DocumentType // TypeUnknown if the format is sniffed
+}
+
+// sniffedExtensions are extensions shared by several formats, whose files
+// are sniffed unless a converter or mapping claims the extension
+var sniffedExtensions = []string{".xml", ".txt"}
+
+// FormatMapping maps files whose names match a pattern to a format
+type FormatMapping struct {
+	Pattern string
+	Type    types.DocumentType
+}
+
+// ParseFormat returns the document type with the given format name
+func (r *Registry) ParseFormat(name string) (types.DocumentType, error) {
+	docType, ok := r.names[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		names := make([]string, 0, len(r.names))
+		for n := range r.names {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return types.TypeUnknown, fmt.Errorf("unknown format '%s': expected one of %s", name, strings.Join(names, ", "))
+	}
+	return docType, nil
+}
+
+// WithFormatMap returns a copy of the registry that gives files matching
+// patterns the mapped formats (pattern -> format name), in place of any
+// mappings of r. A pattern beginning with a dot and without wildcards is
+// an extension; a pattern without a slash matches the file name, and one
+// with a slash the end of the file's path. Patterns are matched without
+// regard to case; those with a slash are tried first, then other patterns,
+// then extensions, each longest first.
+func (r *Registry) WithFormatMap(mapping map[string]string) (*Registry, error) {
+	var mappings []FormatMapping
+	for pattern, name := range mapping {
+		docType, err := r.ParseFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("format mapping '%s': %w", pattern, err)
+		}
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if !isExtension(pattern) {
+			if err := glob.Validate(pattern); err != nil {
+				return nil, fmt.Errorf("format mapping: %w", err)
+			}
+		}
+		mappings = append(mappings, FormatMapping{Pattern: pattern, Type: docType})
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		a, b := mappings[i].Pattern, mappings[j].Pattern
+		if ra, rb := mappingRank(a), mappingRank(b); ra != rb {
+			return ra < rb
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	c := r.clone()
+	c.mappings = mappings
+	return c, nil
+}
+
+// FormatMappings returns the format mappings, in the order they are tried
+func (r *Registry) FormatMappings() []FormatMapping {
+	return r.mappings
+}
+
+// Extensions returns the extensions of the registered converters, in the
+// order the converters were registered, followed by those whose files are
+// sniffed
+func (r *Registry) Extensions() []Extension {
+	var exts []Extension
+	claimed := make(map[string]bool)
+	for _, c := range r.converters {
+		for _, ext := range c.Extensions() {
+			exts = append(exts, Extension{ext, types.DocumentType(c.Name())})
+			claimed[ext] = true
+		}
+	}
+	for _, ext := range sniffedExtensions {
+		if !claimed[ext] {
+			exts = append(exts, Extension{ext, types.TypeUnknown})
+		}
+	}
+	return exts
+}
+
+// mappingRank orders path patterns before name patterns before extensions
+func mappingRank(pattern string) int {
+	switch {
+	case strings.Contains(pattern, "/"):
+		return 0
+	case !isExtension(pattern):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// isExtension returns true if a mapping pattern is a plain extension
+func isExtension(pattern string) bool {
+	return strings.HasPrefix(pattern, ".") && !glob.HasMeta(pattern) && !strings.Contains(pattern, "/")
+}
+
+// matches returns true if a file matches the mapping's pattern
+func (m FormatMapping) matches(filename string) bool {
+	name := strings.ToLower(filepath.ToSlash(filename))
+	switch mappingRank(m.Pattern) {
+	case 0:
+		return glob.Match("**/"+strings.TrimPrefix(m.Pattern, "/"), name)
+	case 1:
+		return glob.Match(m.Pattern, path.Base(name))
+	default:
+		return strings.HasSuffix(name, m.Pattern)
+	}
+}
+
+// DetectDocumentType detects the document type from the file name, using
+// the format mappings and then the file extension. Files whose format can
+// only be told from their content (see NeedsSniffing) are TypeUnknown.
+func (r *Registry) DetectDocumentType(filename string) types.DocumentType {
+	for _, m := range r.mappings {
+		if m.matches(filename) {
+			return m.Type
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range r.Extensions() {
+		if e.Extension == ext {
+			return e.Type
+		}
+	}
+	return types.TypeUnknown
+}
+
+// DetectContentType detects the document type from the file name and, if
+// the name is not enough, from the content. Sniffed files that no
+// converter recognizes are given to the first converter implementing
+// Fallback that accepts them.
+func (r *Registry) DetectContentType(filename string, content []byte) types.DocumentType {
+	if docType := r.DetectDocumentType(filename); docType != types.TypeUnknown {
+		return docType
+	}
+	if !r.NeedsSniffing(filename) {
+		return types.TypeUnknown
+	}
+	if docType := r.Sniff(content); docType != types.TypeUnknown {
+		return docType
+	}
+	for _, c := range r.converters {
+		if f, ok := c.(Fallback); ok && f.Fallback(filename, content) {
+			return types.DocumentType(c.Name())
+		}
+	}
+	return types.TypeUnknown
+}
+
+// NeedsSniffing returns true if a file's format is told from its content:
+// files without an extension, and .xml and .txt files that no mapping
+// covers
+func (r *Registry) NeedsSniffing(filename string) bool {
+	if r.DetectDocumentType(filename) != types.TypeUnknown {
+		return false
+	}
+	base := filepath.Base(filename)
+	ext := strings.ToLower(filepath.Ext(base))
+	if ext == "" {
+		return !strings.HasPrefix(base, ".")
+	}
+	for _, e := range r.Extensions() {
+		if e.Extension == ext {
+			return e.Type == types.TypeUnknown
+		}
+	}
+	return false
+}
+
+// utf8BOM may begin a UTF-8 document
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Sniff detects the format of a document from its content, asking each
+// registered converter in turn whether it recognizes the document. It
+// returns TypeUnknown if none does.
+func (r *Registry) Sniff(content []byte) types.DocumentType {
+	for _, c := range r.converters {
+		if c.Sniff(content) {
+			return types.DocumentType(c.Name())
+		}
+	}
+	return types.TypeUnknown
+}
+
+// markupRoot returns the doctype declaration and the name of the root
+// element of an HTML, XML or SGML document, in lower case and without a
+// namespace prefix (as in <db:book>). The root element is taken from the
+// doctype if there is one. Both are empty if the content is not markup.
+func markupRoot(content []byte) (doctype, root string) {
+	text := bytes.TrimPrefix(content, utf8BOM)
+
+	// Skip the XML declaration, processing instructions and comments
+	// before the doctype or root element
+	for {
+		text = bytes.TrimLeft(text, " \t\r\n")
+		var end []byte
+		switch {
+		case bytes.HasPrefix(text, []byte("<?")):
+			end = []byte("?>")
+		case bytes.HasPrefix(text, []byte("<!--")):
+			end = []byte("-->")
+		}
+		if end == nil {
+			break
+		}
+		i := bytes.Index(text, end)
+		if i < 0 {
+			return "", ""
+		}
+		text = text[i+len(end):]
+	}
+
+	if len(text) < 2 || text[0] != '<' {
+		return "", ""
+	}
+
+	if len(text) >= 9 && strings.EqualFold(string(text[:9]), "<!doctype") {
+		end := bytes.IndexByte(text, '>')
+		if end < 0 {
+			return "", ""
+		}
+		doctype = strings.ToLower(string(text[9:end]))
+		if fields := strings.Fields(doctype); len(fields) > 0 {
+			root = fields[0]
+		}
+	} else {
+		name := text[1:]
+		end := bytes.IndexAny(name, " \t\r\n/>")
+		if end < 0 {
+			return "", ""
+		}
+		root = strings.ToLower(string(name[:end]))
+	}
+
+	if i := strings.LastIndexByte(root, ':'); i >= 0 {
+		root = root[i+1:]
+	}
+	return doctype, root
+}
+
+// IsSupported returns true if the file type is supported, or may be once
+// its content is sniffed
+func (r *Registry) IsSupported(filename string) bool {
+	return r.DetectDocumentType(filename) != types.TypeUnknown || r.NeedsSniffing(filename)
+}
+
+// GetSupportedExtensions returns a list of supported file extensions
+func (r *Registry) GetSupportedExtensions() []string {
+	extensions := r.Extensions()
+	exts := make([]string, len(extensions))
+	for i, e := range extensions {
+		exts[i] = e.Extension
+	}
+	return exts
+}
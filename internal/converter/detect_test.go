@@ -0,0 +1,133 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected types.DocumentType
+	}{
+		{"YAML front matter", "---\ntitle: Guide\n---\n\nText", types.TypeMarkdown},
+		{"TOML front matter", "\xef\xbb\xbf+++\ntitle = \"Guide\"\n+++\nText", types.TypeMarkdown},
+		{"Unclosed front matter", "---\ntitle: Guide\n", types.TypeUnknown},
+		{"HTML doctype", "<!DOCTYPE html>\n<html><body></body></html>", types.TypeHTML},
+		{"HTML root", "  <html lang=\"en\"><head></head></html>", types.TypeHTML},
+		{"XHTML", "<?xml version=\"1.0\"?>\n<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML 1.0 Strict//EN\">\n<html xmlns=\"http://www.w3.org/1999/xhtml\"></html>", types.TypeHTML},
+		{"DocBook doctype", "<!DOCTYPE refentry PUBLIC \"-//OASIS//DTD DocBook V4.2//EN\">\n<refentry></refentry>", types.TypeSGML},
+		{"DocBook root", "<?xml version=\"1.0\"?>\n<!-- Generated -->\n<chapter id=\"intro\"><title>Intro</title></chapter>", types.TypeSGML},
+		{"DocBook 5 namespace", "<db:book xmlns:db=\"http://docbook.org/ns/docbook\"></db:book>", types.TypeSGML},
+		{"Other XML", "<?xml version=\"1.0\"?>\n<project><modelVersion>4.0.0</modelVersion></project>", types.TypeUnknown},
+		{"Sitemap", "<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\"></urlset>", types.TypeUnknown},
+		{"Plain text", "Just some notes\n", types.TypeUnknown},
+		{"Empty", "", types.TypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default().Sniff([]byte(tt.content)); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	docbook := []byte("<!DOCTYPE book PUBLIC \"-//OASIS//DTD DocBook XML V4.5//EN\"><book></book>")
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		expected types.DocumentType
+	}{
+		{"Extension wins", "page.html", docbook, types.TypeHTML},
+		{"XML sniffed as DocBook", "manual.xml", docbook, types.TypeSGML},
+		{"XML sniffed as unknown", "pom.xml", []byte("<project></project>"), types.TypeUnknown},
+		{"Text with front matter", "notes.txt", []byte("---\ntitle: Notes\n---\n"), types.TypeMarkdown},
+		{"No extension", "docs/INSTALL", []byte("<!doctype html><html></html>"), types.TypeHTML},
+		{"Text file", "notes.txt", []byte("Some notes.\n"), types.TypePlainText},
+		{"Binary text file", "data.TXT", []byte("\x00\x01\x02"), types.TypeUnknown},
+		{"README without extension", "docs/README", []byte("Widgets\n"), types.TypePlainText},
+		{"Other file without extension", "docs/Makefile", []byte("all:\n"), types.TypeUnknown},
+		{"Man page without extension", "widgetctl", []byte(".TH WIDGETCTL 1\n"), types.TypeManPage},
+		{"Unknown extension not sniffed", "image.png", []byte("<html></html>"), types.TypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default().DetectContentType(tt.filename, tt.content); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithFormatMap(t *testing.T) {
+	formats, err := Default().WithFormatMap(map[string]string{
+		".txt":            "markdown",
+		"*.inc":           "html",
+		"CHANGES":         "rst",
+		"docs/api/*.xml":  "html",
+		"**/legacy/*.xml": "docbook",
+		".md":             "rst",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		filename string
+		expected types.DocumentType
+	}{
+		{"notes.TXT", types.TypeMarkdown},
+		{"header.inc", types.TypeHTML},
+		{"/repo/CHANGES", types.TypeReStructuredText},
+		{"/repo/docs/api/users.xml", types.TypeHTML},
+		{"docs/api/users.xml", types.TypeHTML},
+		{"docs/api/v1/users.xml", types.TypeUnknown},
+		{"src/legacy/old.xml", types.TypeSGML},
+		{"README.md", types.TypeReStructuredText},
+		{"page.html", types.TypeHTML},
+	}
+	for _, tt := range tests {
+		if got := formats.DetectDocumentType(tt.filename); got != tt.expected {
+			t.Errorf("DetectDocumentType(%q) = %v, expected %v", tt.filename, got, tt.expected)
+		}
+	}
+	if formats.NeedsSniffing("notes.txt") || !formats.NeedsSniffing("other.xml") {
+		t.Error("expected mapped extensions not to be sniffed, and others to be")
+	}
+
+	// The registry the mappings were added to is unchanged
+	if len(Default().FormatMappings()) != 0 || Default().DetectDocumentType("README.md") != types.TypeMarkdown {
+		t.Error("expected the default registry to have no mappings")
+	}
+
+	// Path patterns are tried first
+	mappings := formats.FormatMappings()
+	if len(mappings) != 6 || mappings[0].Pattern != "**/legacy/*.xml" || mappings[5].Pattern != ".md" {
+		t.Errorf("unexpected mapping order: %v", mappings)
+	}
+
+	for _, bad := range []map[string]string{
+		{".txt": "word"},
+		{"docs/[a-": "html"},
+	} {
+		if _, err := formats.WithFormatMap(bad); err == nil {
+			t.Errorf("expected error for %v", bad)
+		}
+	}
+}
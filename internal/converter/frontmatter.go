@@ -0,0 +1,98 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SplitFrontMatter separates YAML (---) or TOML (+++) front matter from the
+// start of a document. It returns the parsed front matter (nil if there is
+// none) and the remaining body. If the front matter cannot be parsed, the
+// original content is returned along with the error.
+func SplitFrontMatter(content []byte) (map[string]interface{}, []byte, error) {
+	text := bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var delimiter string
+	switch {
+	case hasDelimiterLine(text, "---"):
+		delimiter = "---"
+	case hasDelimiterLine(text, "+++"):
+		delimiter = "+++"
+	default:
+		return nil, content, nil
+	}
+
+	// Find the closing delimiter line (YAML also allows "...")
+	rest := text[lineEnd(text, 0):]
+	offset := 0
+	for offset < len(rest) {
+		end := lineEnd(rest, offset)
+		line := string(bytes.TrimRight(rest[offset:end], "\r\n"))
+		if line == delimiter || (delimiter == "---" && line == "...") {
+			block := rest[:offset]
+			body := bytes.TrimLeft(rest[end:], "\r\n")
+
+			meta, err := parseFrontMatter(block, delimiter)
+			if err != nil {
+				return nil, content, err
+			}
+			return meta, body, nil
+		}
+		offset = end
+	}
+
+	// No closing delimiter, so this is not front matter
+	return nil, content, nil
+}
+
+// parseFrontMatter parses a YAML or TOML front matter block
+func parseFrontMatter(block []byte, delimiter string) (map[string]interface{}, error) {
+	meta := make(map[string]interface{})
+
+	if delimiter == "+++" {
+		if err := toml.Unmarshal(block, &meta); err != nil {
+			return nil, fmt.Errorf("invalid TOML front matter: %w", err)
+		}
+		return meta, nil
+	}
+
+	if err := yaml.Unmarshal(block, &meta); err != nil {
+		return nil, fmt.Errorf("invalid YAML front matter: %w", err)
+	}
+	return meta, nil
+}
+
+// hasDelimiterLine returns true if the first line of text is exactly the
+// given delimiter
+func hasDelimiterLine(text []byte, delimiter string) bool {
+	line := bytes.TrimRight(text[:lineEnd(text, 0)], "\r\n")
+	return string(line) == delimiter
+}
+
+// lineEnd returns the index just past the end of the line starting at offset
+func lineEnd(text []byte, offset int) int {
+	if i := bytes.IndexByte(text[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(text)
+}
+
+// frontMatterTitle returns the title from front matter, if present
+func frontMatterTitle(meta map[string]interface{}) string {
+	if title, ok := meta["title"].(string); ok {
+		return title
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantMeta  bool
+		wantTitle string
+		wantBody  string
+		wantErr   bool
+	}{
+		{
+			"YAML front matter",
+			"---\ntitle: YAML Title\ntags: [a, b]\nweight: 10\n---\n\n# Heading\n",
+			true,
+			"YAML Title",
+			"# Heading\n",
+			false,
+		},
+		{
+			"YAML front matter with dots terminator",
+			"---\ntitle: Dots\n...\nBody\n",
+			true,
+			"Dots",
+			"Body\n",
+			false,
+		},
+		{
+			"TOML front matter",
+			"+++\ntitle = \"TOML Title\"\nweight = 3\n+++\nBody\n",
+			true,
+			"TOML Title",
+			"Body\n",
+			false,
+		},
+		{
+			"CRLF line endings",
+			"---\r\ntitle: CRLF\r\n---\r\nBody\r\n",
+			true,
+			"CRLF",
+			"Body\r\n",
+			false,
+		},
+		{
+			"No front matter",
+			"# Heading\n\nContent\n",
+			false,
+			"",
+			"# Heading\n\nContent\n",
+			false,
+		},
+		{
+			"Unterminated front matter",
+			"---\ntitle: Open\n\nContent\n",
+			false,
+			"",
+			"---\ntitle: Open\n\nContent\n",
+			false,
+		},
+		{
+			"Invalid YAML",
+			"---\ntitle: [unclosed\n---\nBody\n",
+			false,
+			"",
+			"---\ntitle: [unclosed\n---\nBody\n",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body, err := SplitFrontMatter([]byte(tt.content))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantMeta != (meta != nil) {
+				t.Fatalf("expected metadata %v, got %v", tt.wantMeta, meta)
+			}
+
+			if title := frontMatterTitle(meta); title != tt.wantTitle {
+				t.Errorf("expected title '%s', got '%s'", tt.wantTitle, title)
+			}
+
+			if string(body) != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func TestFrontMatterValues(t *testing.T) {
+	meta, _, err := SplitFrontMatter([]byte("---\ntags:\n  - admin\n  - gui\nweight: 10\nproduct: pgAdmin\n---\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags, ok := meta["tags"].([]interface{})
+	if !ok || len(tags) != 2 || tags[0] != "admin" {
+		t.Errorf("expected tags list, got %#v", meta["tags"])
+	}
+
+	if meta["weight"] != 10 {
+		t.Errorf("expected weight 10, got %#v", meta["weight"])
+	}
+
+	if meta["product"] != "pgAdmin" {
+		t.Errorf("expected product 'pgAdmin', got %#v", meta["product"])
+	}
+}
+
+func TestMarkdownFrontMatterTitle(t *testing.T) {
+	// The front matter title is used when there is no # heading
+	result, err := markdownConverter{}.Convert([]byte("---\ntitle: Only In Front Matter\n---\n\nSome text.\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "Only In Front Matter" {
+		t.Errorf("expected title 'Only In Front Matter', got '%s'", result.Title)
+	}
+
+	// Unparseable front matter is reported, not fatal
+	result, err = markdownConverter{}.Convert([]byte("---\ntitle: [unclosed\n---\n\n# Heading\n"))
+	if err != nil || result.Title != "Heading" || result.Metadata != nil || len(result.Warnings) != 1 {
+		t.Errorf("expected a warning and the heading title, got %+v, error %v", result, err)
+	}
+}
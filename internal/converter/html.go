@@ -0,0 +1,120 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(htmlConverter{})
+}
+
+// htmlConverter converts HTML and XHTML documents
+type htmlConverter struct{}
+
+func (htmlConverter) Name() string { return string(types.TypeHTML) }
+
+func (htmlConverter) Extensions() []string { return []string{".html", ".htm", ".xhtml"} }
+
+// Sniff recognizes documents whose doctype or root element is html
+func (htmlConverter) Sniff(content []byte) bool {
+	_, root := markupRoot(content)
+	return root == "html"
+}
+
+func (htmlConverter) Convert(content []byte) (*Result, error) {
+	markdown, title, err := convertHTML(content)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Markdown: markdown, Title: title}, nil
+}
+
+// convertHTML converts HTML to Markdown and extracts the title
+func convertHTML(content []byte) (string, string, error) {
+	converter := md.NewConverter("", true, nil)
+
+	// Add custom rule to shift heading levels down by one
+	// (since we use the <title> as H1, all other headings should be shifted)
+	converter.AddRules(md.Rule{
+		Filter: []string{"h1", "h2", "h3", "h4", "h5", "h6"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			// Shift each heading level down by one
+			level := 2 // h1 becomes h2 (##)
+			switch selec.Nodes[0].Data {
+			case "h1":
+				level = 2
+			case "h2":
+				level = 3
+			case "h3":
+				level = 4
+			case "h4":
+				level = 5
+			case "h5":
+				level = 6
+			case "h6":
+				level = 6 // h6 stays at max level (######)
+			}
+
+			result := strings.Repeat("#", level) + " " + content
+			return &result
+		},
+	})
+
+	markdown, err := converter.ConvertBytes(content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to convert HTML: %w", err)
+	}
+
+	// Extract title from HTML
+	title := extractHTMLTitle(content)
+
+	// Prepend title as H1 heading if we have one
+	markdownStr := string(markdown)
+	if title != "" {
+		// The html-to-markdown library includes the title as plain text at the start
+		// We need to replace it with a proper markdown heading
+		markdownStr = strings.TrimSpace(markdownStr)
+
+		// Check if the markdown starts with the title (without HTML entities decoded)
+		// The library decodes entities in the output but we extract title from raw HTML
+		if strings.HasPrefix(markdownStr, title) {
+			// Remove the plain title and replace with heading
+			markdownStr = strings.TrimPrefix(markdownStr, title)
+			markdownStr = strings.TrimSpace(markdownStr)
+		}
+
+		// Add title as H1 heading
+		markdownStr = "# " + title + "\n\n" + markdownStr
+	}
+
+	return markdownStr, title, nil
+}
+
+// extractHTMLTitle extracts the title from HTML <title> tag
+func extractHTMLTitle(content []byte) string {
+	titleRe := regexp.MustCompile(`(?i)<title[^>]*>([^<]+)</title>`)
+	matches := titleRe.FindSubmatch(content)
+	if len(matches) > 1 {
+		// Decode HTML entities (e.g., &#8212; -> —)
+		title := html.UnescapeString(string(matches[1]))
+		return strings.TrimSpace(title)
+	}
+	return ""
+}
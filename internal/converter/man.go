@@ -0,0 +1,550 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(manConverter{}, "man", "roff", "troff", "groff")
+}
+
+// manConverter converts man pages written with the roff man macros
+type manConverter struct{}
+
+func (manConverter) Name() string { return string(types.TypeManPage) }
+
+func (manConverter) Extensions() []string {
+	return []string{".1", ".2", ".3", ".4", ".5", ".6", ".7", ".8", ".9", ".man"}
+}
+
+// Sniff recognizes documents whose first request, after any comments, is
+// a .TH title line
+func (manConverter) Sniff(content []byte) bool {
+	for _, line := range strings.Split(string(bytes.TrimPrefix(content, utf8BOM)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "." || isManComment(line) {
+			continue
+		}
+		return line == ".TH" || strings.HasPrefix(line, ".TH ")
+	}
+	return false
+}
+
+func (manConverter) Convert(content []byte) (*Result, error) {
+	// Files such as libfoo.so.1 share the extensions of man pages
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil, fmt.Errorf("binary content is not a man page")
+	}
+	return convertMan(content), nil
+}
+
+// manDocument is the state of a man page conversion
+type manDocument struct {
+	blocks   []string
+	metadata map[string]interface{}
+	title    string
+
+	lines       []string // lines of the current paragraph or list item
+	item        bool     // whether the lines are the body of a list item
+	tag         string   // the list item's tag, "" for a bullet
+	wantTag     bool     // whether the next line is a .TP tag
+	wantHeading string   // the heading prefix the next line takes, if any
+	nextFont    byte     // the font of the next line, after .B or .I alone
+	lineBreak   bool     // whether a .br came before the next line
+}
+
+// convertMan converts a man page to Markdown. The .TH line gives the title
+// and metadata, .SH and .SS give headings, .TP and .IP give list items,
+// .nf/.fi and .EX/.EE blocks become code blocks and the font macros and
+// escapes become bold and italic text. Other requests are ignored.
+func convertMan(content []byte) *Result {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(content, utf8BOM)), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	d := &manDocument{metadata: make(map[string]interface{})}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if isManComment(line) {
+			continue
+		}
+		if line == "" || (line[0] != '.' && line[0] != '\'') {
+			d.text(line)
+			continue
+		}
+
+		name, args := parseManRequest(line)
+		switch name {
+		case "":
+		case "TH":
+			d.flush()
+			d.setTitle(args)
+		case "SH", "SS":
+			d.flush()
+			prefix := "## "
+			if name == "SS" {
+				prefix = "### "
+			}
+			if len(args) == 0 {
+				d.wantHeading = prefix
+			} else {
+				d.blocks = append(d.blocks, prefix+escapeTextLine(manPlain(strings.Join(args, " "))))
+			}
+		case "PP", "P", "LP", "HP", "sp":
+			d.flush()
+		case "br":
+			d.lineBreak = len(d.lines) > 0
+		case "TP":
+			d.flush()
+			d.wantTag = true
+		case "IP":
+			d.flush()
+			if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+				d.item = true
+				d.tag = manInline(args[0], 'R')
+				switch manPlain(args[0]) {
+				case "•", "*", "-", "o", "·":
+					d.tag = ""
+				}
+			}
+		case "B", "I", "SB", "SM":
+			font := byte('B')
+			switch name {
+			case "I":
+				font = 'I'
+			case "SM":
+				font = 'R'
+			}
+			if len(args) == 0 {
+				d.nextFont = font
+			} else {
+				d.add(manInline(strings.Join(args, " "), font))
+			}
+		case "BR", "BI", "IB", "IR", "RB", "RI":
+			var b strings.Builder
+			for j, arg := range args {
+				b.WriteString(manInline(arg, name[j%2]))
+			}
+			d.add(b.String())
+		case "nf", "EX":
+			d.flush()
+			i = d.noFill(lines, i+1, name)
+		case "de", "de1", "am", "ig":
+			// Skip macro definitions and ignored blocks
+			i++
+			for i < len(lines) && strings.TrimSpace(lines[i]) != ".." {
+				i++
+			}
+		}
+	}
+	d.flush()
+
+	result := &Result{Title: d.title}
+	if len(d.blocks) > 0 {
+		result.Markdown = strings.Join(d.blocks, "\n\n") + "\n"
+	}
+	if len(d.metadata) > 0 {
+		result.Metadata = d.metadata
+	}
+	return result
+}
+
+// setTitle reads a .TH line: the page's name, section, date, source and
+// manual
+func (d *manDocument) setTitle(args []string) {
+	keys := []string{"name", "section", "date", "source", "manual"}
+	for i, arg := range args {
+		if i < len(keys) {
+			if value := manPlain(arg); value != "" {
+				d.metadata[keys[i]] = value
+			}
+		}
+	}
+	name, _ := d.metadata["name"].(string)
+	if name == "" {
+		return
+	}
+	d.title = name
+	if section, ok := d.metadata["section"].(string); ok {
+		d.title += "(" + section + ")"
+	}
+	d.blocks = append(d.blocks, "# "+escapeTextLine(d.title))
+}
+
+// text handles a line of text in fill mode
+func (d *manDocument) text(line string) {
+	if strings.TrimSpace(line) == "" {
+		// A blank line breaks the paragraph, but not a pending tag or
+		// heading
+		if !d.wantTag && d.wantHeading == "" {
+			d.flush()
+		}
+		return
+	}
+	if d.wantHeading != "" {
+		d.blocks = append(d.blocks, d.wantHeading+escapeTextLine(manPlain(line)))
+		d.wantHeading = ""
+		return
+	}
+	font := byte('R')
+	if d.nextFont != 0 {
+		font, d.nextFont = d.nextFont, 0
+	}
+	d.add(manInline(line, font))
+}
+
+// add adds converted text to the current paragraph, or makes it the tag of
+// a .TP list item
+func (d *manDocument) add(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if d.wantTag {
+		d.wantTag = false
+		d.item = true
+		d.tag = strings.TrimSpace(text)
+		return
+	}
+	if d.lineBreak {
+		d.lines[len(d.lines)-1] += `\`
+		d.lineBreak = false
+	}
+	d.lines = append(d.lines, escapeTextLine(text))
+}
+
+// flush ends the current paragraph or list item
+func (d *manDocument) flush() {
+	body := strings.Join(d.lines, "\n  ")
+	switch {
+	case d.item && d.tag == "":
+		if body != "" {
+			d.blocks = append(d.blocks, "- "+body)
+		}
+	case d.item:
+		tag := d.tag
+		if !strings.HasPrefix(tag, "*") {
+			tag = "**" + tag + "**"
+		}
+		if body != "" {
+			tag += ": " + body
+		}
+		d.blocks = append(d.blocks, "- "+tag)
+	case len(d.lines) > 0:
+		d.blocks = append(d.blocks, strings.Join(d.lines, "\n"))
+	}
+	d.lines = nil
+	d.item = false
+	d.tag = ""
+	d.wantTag = false
+	d.nextFont = 0
+	d.lineBreak = false
+}
+
+// noFill converts the lines of a .nf (or .EX) block, starting at start, to
+// a code block, returning the index of the line that ends it
+func (d *manDocument) noFill(lines []string, start int, request string) int {
+	end := "fi"
+	if request == "EX" {
+		end = "EE"
+	}
+
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isManComment(line) {
+			continue
+		}
+		if line == "" || (line[0] != '.' && line[0] != '\'') {
+			code = append(code, manPlain(line))
+			continue
+		}
+		name, args := parseManRequest(line)
+		if name == end {
+			break
+		}
+		switch name {
+		case "sp":
+			code = append(code, "")
+		case "B", "I", "SB", "SM":
+			code = append(code, manPlain(strings.Join(args, " ")))
+		case "BR", "BI", "IB", "IR", "RB", "RI":
+			var b strings.Builder
+			for _, arg := range args {
+				b.WriteString(manPlain(arg))
+			}
+			code = append(code, b.String())
+		}
+	}
+
+	// Drop blank lines around the block
+	for len(code) > 0 && strings.TrimSpace(code[0]) == "" {
+		code = code[1:]
+	}
+	for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+		code = code[:len(code)-1]
+	}
+	if len(code) > 0 {
+		d.blocks = append(d.blocks, fenceCode(strings.Join(code, "\n"), ""))
+	}
+	return i
+}
+
+// isManComment returns true if a line is a roff comment
+func isManComment(line string) bool {
+	return strings.HasPrefix(line, `.\"`) || strings.HasPrefix(line, `'\"`) ||
+		strings.HasPrefix(line, `\"`) || strings.HasPrefix(line, `.\#`)
+}
+
+// parseManRequest splits a request line into the request's name and its
+// arguments. Arguments are separated by spaces, unless they are quoted; ""
+// in a quoted argument is a quote.
+func parseManRequest(line string) (string, []string) {
+	line = strings.TrimLeft(line[1:], " \t")
+	if i := strings.Index(line, `\"`); i >= 0 {
+		line = line[:i]
+	}
+	name, rest, _ := strings.Cut(line, " ")
+	name = strings.TrimSpace(name)
+
+	var args []string
+	for i := 0; i < len(rest); {
+		for i < len(rest) && (rest[i] == ' ' || rest[i] == '\t') {
+			i++
+		}
+		if i >= len(rest) {
+			break
+		}
+		var arg strings.Builder
+		if rest[i] == '"' {
+			for i++; i < len(rest); i++ {
+				if rest[i] == '"' {
+					if i+1 < len(rest) && rest[i+1] == '"' {
+						arg.WriteByte('"')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				arg.WriteByte(rest[i])
+			}
+		} else {
+			for ; i < len(rest) && rest[i] != ' ' && rest[i] != '\t'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					arg.WriteByte(rest[i])
+					i++
+				}
+				arg.WriteByte(rest[i])
+			}
+		}
+		args = append(args, arg.String())
+	}
+	return name, args
+}
+
+// manSpecialChars are the roff special characters (\(xx or \[xx]) that
+// are converted; others are removed
+var manSpecialChars = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "-", "bu": "•", "aq": "'",
+	"dq": `"`, "lq": `"`, "rq": `"`, "oq": "'", "cq": "'", "co": "©",
+	"rg": "®", "tm": "™", "mu": "×", "de": "°", "+-": "±", "<=": "≤",
+	">=": "≥", "!=": "≠", "->": "→", "<-": "←", "ti": "~", "ha": "^",
+	"rs": `\`, "sl": "/", "ba": "|", "or": "|", "ga": "`", "at": "@",
+	"sh": "#", "Do": "$", "lB": "[", "rB": "]", "lC": "{", "rC": "}",
+	"Fo": "«", "Fc": "»", "fo": "‹", "fc": "›",
+}
+
+// manStrings are the predefined roff strings (\*x) that are converted;
+// others are removed
+var manStrings = map[string]string{
+	"lq": `"`, "rq": `"`, "R": "®", "Tm": "™",
+}
+
+// manSegment is a run of text in one font: 'R' (roman), 'B' or 'I'
+type manSegment struct {
+	font byte
+	text strings.Builder
+}
+
+// manPlain returns text with its roff escapes interpreted and its font
+// changes dropped, for headings and code blocks
+func manPlain(text string) string {
+	return renderMan(parseManText(text, 'R', false), false)
+}
+
+// manInline converts text, starting in a font, to Markdown: font changes
+// become bold and italic text, and characters that are Markdown markup are
+// escaped
+func manInline(text string, font byte) string {
+	return renderMan(parseManText(text, font, true), true)
+}
+
+// parseManText interprets the escapes in a line of roff text, splitting
+// it into runs of text in one font. If markdown is set, characters that are
+// Markdown markup are escaped.
+func parseManText(text string, font byte, markdown bool) []*manSegment {
+	segments := []*manSegment{{font: font}}
+	setFont := func(f byte) {
+		if current := segments[len(segments)-1]; current.font != f {
+			if current.text.Len() == 0 {
+				current.font = f
+			} else {
+				segments = append(segments, &manSegment{font: f})
+			}
+		}
+	}
+	write := func(s string) { segments[len(segments)-1].text.WriteString(s) }
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' {
+			if markdown && strings.IndexByte("*`<", c) >= 0 {
+				write(`\`)
+			}
+			write(string(c))
+			continue
+		}
+		if i+1 >= len(text) {
+			break
+		}
+		i++
+		switch e := text[i]; e {
+		case '"', '#':
+			// A comment runs to the end of the line
+			return segments
+		case 'f':
+			name, n := manEscapeName(text[i+1:])
+			i += n
+			setFont(manFont(name))
+		case '(', '[':
+			name, n := manEscapeName(text[i:])
+			i += n - 1
+			write(manSpecialChars[name])
+		case '*':
+			name, n := manEscapeName(text[i+1:])
+			i += n
+			write(manStrings[name])
+		case 'n', 'g', 'm', 'F', 'Y', 'V':
+			_, n := manEscapeName(text[i+1:])
+			i += n
+		case 's':
+			// Size changes, such as \s-1, \s+2, \s0 or \s(12
+			j := i + 1
+			if j < len(text) && (text[j] == '+' || text[j] == '-') {
+				j++
+			}
+			if j < len(text) && (text[j] == '(' || text[j] == '[') {
+				_, n := manEscapeName(text[j:])
+				j += n
+			} else if j < len(text) && text[j] >= '0' && text[j] <= '9' {
+				j++
+			}
+			i = j - 1
+		case 'h', 'v', 'w', 'o', 'l', 'L', 'D', 'b', 'x', 'X', 'Z', 'N', 'k', 'A', 'B', 'C', 'R', 'S':
+			// Escapes with a delimited argument, such as \h'1n'
+			if i+1 < len(text) {
+				delim := text[i+1]
+				if end := strings.IndexByte(text[i+2:], delim); end >= 0 {
+					i += end + 2
+				} else {
+					i = len(text)
+				}
+			}
+		case '-':
+			write("-")
+		case 'e', '\\':
+			if markdown {
+				write(`\\`)
+			} else {
+				write(`\`)
+			}
+		case ' ', '~', '0':
+			write(" ")
+		case '\'':
+			write("'")
+		case '`':
+			if markdown {
+				write("\\`")
+			} else {
+				write("`")
+			}
+		case '.':
+			write(".")
+		case '&', '|', '^', ')', ',', '/', 'c', '%', ':', 'p', 'd', 'u', 'r', 't', 'z', '{', '}':
+		default:
+			write(string(e))
+		}
+	}
+	return segments
+}
+
+// manEscapeName reads the name of an escape: a single character, two
+// characters after (, or any in [...]. It returns the name and the length
+// of the text it takes up.
+func manEscapeName(text string) (string, int) {
+	switch {
+	case text == "":
+		return "", 0
+	case text[0] == '(':
+		if len(text) < 3 {
+			return "", len(text)
+		}
+		return text[1:3], 3
+	case text[0] == '[':
+		end := strings.IndexByte(text, ']')
+		if end < 0 {
+			return "", len(text)
+		}
+		return text[1:end], end + 1
+	default:
+		return text[:1], 1
+	}
+}
+
+// manFont returns the font, 'R', 'B' or 'I', for a roff font name
+func manFont(name string) byte {
+	switch name {
+	case "B", "3", "BI", "4", "CB":
+		return 'B'
+	case "I", "2", "CI":
+		return 'I'
+	default:
+		return 'R'
+	}
+}
+
+// renderMan joins runs of text, making the bold and italic runs Markdown
+// emphasis if markdown is set. Spaces at the ends of a run are moved
+// outside its emphasis.
+func renderMan(segments []*manSegment, markdown bool) string {
+	var b strings.Builder
+	for _, s := range segments {
+		text := s.text.String()
+		core := strings.TrimSpace(text)
+		if !markdown || s.font == 'R' || core == "" {
+			b.WriteString(text)
+			continue
+		}
+		mark := "**"
+		if s.font == 'I' {
+			mark = "*"
+		}
+		lead := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		trail := text[len(strings.TrimRight(text, " \t")):]
+		b.WriteString(lead + mark + core + mark + trail)
+	}
+	return b.String()
+}
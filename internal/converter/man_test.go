@@ -0,0 +1,153 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestConvertManSample(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/sample.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Default().Convert(content, types.TypeManPage)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "WIDGETCTL(1)" {
+		t.Errorf("unexpected title: %q", result.Title)
+	}
+	for key, expected := range map[string]string{
+		"name":    "WIDGETCTL",
+		"section": "1",
+		"date":    "2026-01-15",
+		"source":  "widgets 2.1",
+		"manual":  "Widgets Manual",
+	} {
+		if result.Metadata[key] != expected {
+			t.Errorf("expected metadata %s = %q, got %v", key, expected, result.Metadata[key])
+		}
+	}
+
+	for _, expected := range []string{
+		"# WIDGETCTL(1)\n\n## NAME\n\nwidgetctl - control the widget service",
+		"**widgetctl**\n[**-v**]\n[**-c** *file*]\n*command*",
+		"*/etc/widgets.conf*,\nor from the file given with\n**-c**.\n\nEach command waits",
+		"- **-c**, **--config**=*file*: Read the settings from\n  *file*.",
+		"- **-v**: Print more detail.",
+		"- **--timeout**: Wait up to the given number of seconds — 30 by default.",
+		"### Commands\n\n- **start**\n  starts the service.",
+		"```\n$ widgetctl stop\n$ widgetctl -v start\nok\n```",
+		"*/etc/widgets.conf*\\\n*/var/lib/widgets*",
+		"**widgets**(7),\n**systemctl**(1)",
+	} {
+		if !strings.Contains(result.Markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, result.Markdown)
+		}
+	}
+
+	for _, unexpected := range []string{"groff", ".RS", `\f`, `\-`} {
+		if strings.Contains(result.Markdown, unexpected) {
+			t.Errorf("expected markdown not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestConvertMan(t *testing.T) {
+	tests := []struct {
+		name     string
+		man      string
+		expected string
+	}{
+		{
+			name:     "Heading on the next line",
+			man:      ".SH\nDESCRIPTION\nText.\n",
+			expected: "## DESCRIPTION\n\nText.\n",
+		},
+		{
+			name:     "Quoted arguments",
+			man:      ".SH \"SEE ALSO\"\n.B \"two words\" and \"\"\"quoted\"\"\"\n",
+			expected: "## SEE ALSO\n\n**two words and \"quoted\"**\n",
+		},
+		{
+			name:     "Font alone applies to the next line",
+			man:      ".I\nitalic text\nplain text\n",
+			expected: "*italic text*\nplain text\n",
+		},
+		{
+			name:     "Spaces moved outside emphasis",
+			man:      "Use \\fBwidgets \\fRand \\fIgadgets\\fP.\n",
+			expected: "Use **widgets** and *gadgets*.\n",
+		},
+		{
+			name:     "Markdown characters escaped",
+			man:      "Match *.conf with `ls` or <files>.\n",
+			expected: "Match \\*.conf with \\`ls\\` or \\<files>.\n",
+		},
+		{
+			name:     "Escapes",
+			man:      "a\\e b\\(co \\[rg] \\*(lqq\\*(rq \\s-1SMALL\\s0 \\&.x\\c \\h'1n'y \\\" comment\n",
+			expected: "a\\\\ b© ® \"q\" SMALL .x y \n",
+		},
+		{
+			name:     "Indented paragraph without a tag",
+			man:      ".IP\nIndented.\n.IP 1. 4\nFirst.\n",
+			expected: "Indented.\n\n- **1.**: First.\n",
+		},
+		{
+			name:     "Example block",
+			man:      ".EX\n\\fBecho\\fP *\n.EE\n",
+			expected: "```\necho *\n```\n",
+		},
+		{
+			name:     "Macro definitions skipped",
+			man:      ".de XX\n.B hidden\n..\nShown.\n",
+			expected: "Shown.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertMan([]byte(tt.man))
+			if result.Markdown != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result.Markdown)
+			}
+		})
+	}
+
+	if _, err := (manConverter{}).Convert([]byte("\x7fELF\x00\x01")); err == nil {
+		t.Error("expected an error for binary content")
+	}
+}
+
+func TestSniffMan(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{".TH WIDGETS 7\n", true},
+		{"'\\\" t\n.\\\" Comment\n\n.TH WIDGETS 7 2026-01-15\n", true},
+		{".SH NAME\n", false},
+		{"Some text\n.TH WIDGETS 7\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := (manConverter{}).Sniff([]byte(tt.content)); got != tt.expected {
+			t.Errorf("Sniff(%q) = %v, expected %v", tt.content, got, tt.expected)
+		}
+	}
+}
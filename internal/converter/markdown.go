@@ -0,0 +1,100 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(markdownConverter{}, "md")
+}
+
+// markdownConverter reads Markdown documents, which need no conversion
+// beyond separating their front matter
+type markdownConverter struct{}
+
+func (markdownConverter) Name() string { return string(types.TypeMarkdown) }
+
+func (markdownConverter) Extensions() []string {
+	return []string{".md", ".markdown", ".mdx", ".mkd"}
+}
+
+// Sniff recognizes documents that start with YAML or TOML front matter
+func (markdownConverter) Sniff(content []byte) bool {
+	text := bytes.TrimLeft(bytes.TrimPrefix(content, utf8BOM), " \t\r\n")
+	if !hasDelimiterLine(text, "---") && !hasDelimiterLine(text, "+++") {
+		return false
+	}
+	meta, _, err := SplitFrontMatter(text)
+	return err == nil && meta != nil
+}
+
+// Convert returns the Markdown unchanged, with its front matter as the
+// metadata. The title is taken from the first # heading, falling back to
+// the front matter title. Unparseable front matter is left for the heading
+// scan to skip over.
+func (markdownConverter) Convert(content []byte) (*Result, error) {
+	result := &Result{Markdown: string(content)}
+
+	meta, body, err := SplitFrontMatter(content)
+	if err != nil {
+		result.Title = extractMarkdownTitle(result.Markdown)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("ignoring front matter: %v", err))
+		return result, nil
+	}
+
+	result.Metadata = meta
+	result.Title = extractMarkdownTitle(string(body))
+	if result.Title == "" {
+		result.Title = strings.TrimSpace(frontMatterTitle(meta))
+	}
+	return result, nil
+}
+
+// extractMarkdownTitle extracts the title from the first # heading
+func extractMarkdownTitle(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	inMetadata := false
+	metadataDelimiterCount := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Skip YAML front matter
+		if line == "---" {
+			metadataDelimiterCount++
+			if metadataDelimiterCount == 1 {
+				inMetadata = true
+				continue
+			} else if metadataDelimiterCount == 2 {
+				inMetadata = false
+				continue
+			}
+		}
+
+		if inMetadata {
+			continue
+		}
+
+		// Look for first # heading
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "# ") {
+			return strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+		}
+	}
+
+	return ""
+}
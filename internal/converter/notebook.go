@@ -0,0 +1,253 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(notebookConverter{outputLimit: DefaultNotebookOutputLimit}, "notebook", "jupyter", "ipynb")
+}
+
+// DefaultNotebookOutputLimit is the size, in bytes, at which notebook
+// outputs are truncated unless configured otherwise
+const DefaultNotebookOutputLimit = 2000
+
+// WithNotebookOutputs returns a copy of the registry whose notebook
+// converter includes the text outputs of code cells in the converted
+// Markdown, or not, truncating each output at limit bytes (0 for no limit)
+func (r *Registry) WithNotebookOutputs(include bool, limit int) *Registry {
+	reg := r.clone()
+	reg.replace(notebookConverter{outputs: include, outputLimit: limit})
+	return reg
+}
+
+// notebookConverter converts Jupyter notebooks
+type notebookConverter struct {
+	outputs     bool // Include the text outputs of code cells
+	outputLimit int  // Size at which each output is truncated (0 for no limit)
+}
+
+func (notebookConverter) Name() string { return string(types.TypeNotebook) }
+
+func (notebookConverter) Extensions() []string { return []string{".ipynb"} }
+
+// Sniff recognizes JSON objects with the nbformat and cells keys of a
+// notebook
+func (notebookConverter) Sniff(content []byte) bool {
+	text := bytes.TrimLeft(bytes.TrimPrefix(content, utf8BOM), " \t\r\n")
+	return bytes.HasPrefix(text, []byte("{")) &&
+		bytes.Contains(text, []byte(`"nbformat"`)) && bytes.Contains(text, []byte(`"cells"`))
+}
+
+func (c notebookConverter) Convert(content []byte) (*Result, error) {
+	return convertNotebook(content, c.outputs, c.outputLimit)
+}
+
+// notebook is the part of a notebook (nbformat 4) that is converted
+type notebook struct {
+	NBFormat int            `json:"nbformat"`
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		Title      string           `json:"title"`
+		Authors    []notebookAuthor `json:"authors"`
+		KernelSpec struct {
+			DisplayName string `json:"display_name"`
+			Language    string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// notebookAuthor is an entry in a notebook's authors metadata
+type notebookAuthor struct {
+	Name string `json:"name"`
+}
+
+// notebookCell is a cell of a notebook
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+// notebookOutput is an output of a code cell
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// notebookText is multi-line text in a notebook, stored either as a string
+// or as a list of lines
+type notebookText string
+
+// UnmarshalJSON reads text given as a string or a list of strings. Other
+// values, such as the JSON of an application/json output, are ignored.
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+	}
+	return nil
+}
+
+// ansiEscapeRe matches the terminal escape codes in tracebacks and
+// program output
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// convertNotebook converts a notebook to Markdown: Markdown cells are kept
+// as they are and code cells become code blocks in the kernel's language,
+// followed by their text outputs if outputs is set. Raw cells and binary
+// outputs, such as images, are left out.
+func convertNotebook(content []byte, outputs bool, limit int) (*Result, error) {
+	var nb notebook
+	if err := json.Unmarshal(content, &nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+	if nb.NBFormat < 4 {
+		return nil, fmt.Errorf("unsupported notebook format %d: only nbformat 4 is supported", nb.NBFormat)
+	}
+
+	language := nb.Metadata.KernelSpec.Language
+	if language == "" {
+		language = nb.Metadata.LanguageInfo.Name
+	}
+
+	var parts []string
+	title := ""
+	for _, cell := range nb.Cells {
+		source := strings.TrimRight(string(cell.Source), "\n")
+		switch cell.CellType {
+		case "markdown":
+			if source == "" {
+				continue
+			}
+			if title == "" {
+				title = extractMarkdownTitle(source)
+			}
+			parts = append(parts, source)
+		case "code":
+			if strings.TrimSpace(source) == "" {
+				continue
+			}
+			parts = append(parts, fenceCode(source, language))
+			if outputs {
+				for _, output := range cell.Outputs {
+					if text := output.text(); text != "" {
+						parts = append(parts, fenceCode(truncateOutput(text, limit), "text"))
+					}
+				}
+			}
+		}
+	}
+
+	if title == "" {
+		title = strings.TrimSpace(nb.Metadata.Title)
+	}
+
+	metadata := make(map[string]interface{})
+	if nb.Metadata.Title != "" {
+		metadata["title"] = nb.Metadata.Title
+	}
+	var authors []interface{}
+	for _, a := range nb.Metadata.Authors {
+		if a.Name != "" {
+			authors = append(authors, a.Name)
+		}
+	}
+	if len(authors) > 0 {
+		metadata["authors"] = authors
+	}
+	if kernel := nb.Metadata.KernelSpec.DisplayName; kernel != "" {
+		metadata["kernel"] = kernel
+	}
+	if language != "" {
+		metadata["language"] = language
+	}
+
+	result := &Result{
+		Markdown: strings.Join(parts, "\n\n") + "\n",
+		Title:    title,
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+	return result, nil
+}
+
+// text returns the text of an output: a stream, the plain text of a result
+// or display, or an error's name and value. Outputs without text, such as
+// images, give "".
+func (o notebookOutput) text() string {
+	var text string
+	switch o.OutputType {
+	case "stream":
+		text = string(o.Text)
+	case "execute_result", "display_data":
+		text = string(o.Data["text/plain"])
+	case "error":
+		text = o.EName
+		if o.EValue != "" {
+			text += ": " + o.EValue
+		}
+	}
+	return strings.TrimRight(ansiEscapeRe.ReplaceAllString(text, ""), "\n")
+}
+
+// truncateOutput cuts text longer than limit bytes (if limit is not 0) at
+// the last line break before the limit, or at the limit if there is none
+func truncateOutput(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if i := strings.LastIndexByte(text[:cut], '\n'); i > 0 {
+		cut = i
+	}
+	return text[:cut] + "\n... (output truncated)"
+}
+
+// fenceCode formats code as a fenced code block, with a fence longer than
+// any run of backticks in the code
+func fenceCode(code, language string) string {
+	longest, run := 0, 0
+	for _, c := range code {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + language + "\n" + code + "\n" + fence
+}
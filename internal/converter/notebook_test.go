@@ -0,0 +1,191 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestConvertNotebookSample(t *testing.T) {
+	content, err := os.ReadFile("../../testdata/sample.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := convertNotebook(content, true, DefaultNotebookOutputLimit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "Querying pgEdge with Python" {
+		t.Errorf("unexpected title: %q", result.Title)
+	}
+	for key, expected := range map[string]string{
+		"title":    "pgEdge Python Tutorial",
+		"kernel":   "Python 3 (ipykernel)",
+		"language": "python",
+	} {
+		if result.Metadata[key] != expected {
+			t.Errorf("expected metadata %s = %q, got %v", key, expected, result.Metadata[key])
+		}
+	}
+	if authors, ok := result.Metadata["authors"].([]interface{}); !ok || len(authors) != 1 || authors[0] != "Jane Doe" {
+		t.Errorf("unexpected authors: %v", result.Metadata["authors"])
+	}
+
+	for _, expected := range []string{
+		"# Querying pgEdge with Python\n\nThis tutorial shows how to connect to a **pgEdge** cluster and run queries.",
+		"```python\nimport psycopg\nconn = psycopg.connect(\"host=n1 dbname=app\")\nprint(\"connected to n1\")\n```\n\n```text\nconnected to n1\n```",
+		"## Running a Query",
+		"```text\n[(1, 'widget'), (2, 'gadget')]\n```",
+		"```text\n<Figure size 640x480 with 1 Axes>\n```",
+		"```text\nUndefinedTable: relation \"missing\" does not exist\n```",
+	} {
+		if !strings.Contains(result.Markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, result.Markdown)
+		}
+	}
+
+	for _, unexpected := range []string{"iVBORw0KGgo", "raw cell content", "\x1b"} {
+		if strings.Contains(result.Markdown, unexpected) {
+			t.Errorf("expected markdown not to contain %q", unexpected)
+		}
+	}
+
+	// Outputs are left out unless asked for
+	result, err = Default().Convert(content, types.TypeNotebook)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(result.Markdown, "```text") {
+		t.Errorf("expected no outputs, got:\n%s", result.Markdown)
+	}
+
+	// A registry can be configured to include them, truncated
+	formats := Default().WithNotebookOutputs(true, 10)
+	if result, err = formats.Convert(content, types.TypeNotebook); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Markdown, "```text\nconnected ") {
+		t.Errorf("expected truncated outputs, got:\n%s", result.Markdown)
+	}
+	if strings.Contains(result.Markdown, "connected to n1\n```") {
+		t.Errorf("expected outputs to be truncated at 10 bytes, got:\n%s", result.Markdown)
+	}
+	if result, _ = Default().Convert(content, types.TypeNotebook); strings.Contains(result.Markdown, "```text") {
+		t.Error("expected the default registry to be unchanged")
+	}
+}
+
+func TestConvertNotebook(t *testing.T) {
+	tests := []struct {
+		name     string
+		notebook string
+		expected string
+		title    string
+		wantErr  bool
+	}{
+		{
+			name:     "Title from metadata",
+			notebook: `{"nbformat": 4, "metadata": {"title": "Tutorial"}, "cells": [{"cell_type": "markdown", "source": "Some text."}]}`,
+			expected: "Some text.\n",
+			title:    "Tutorial",
+		},
+		{
+			name:     "Language from language_info",
+			notebook: `{"nbformat": 4, "metadata": {"language_info": {"name": "R"}}, "cells": [{"cell_type": "code", "source": ["x <- 1\n"], "outputs": []}]}`,
+			expected: "```R\nx <- 1\n```\n",
+		},
+		{
+			name:     "Code containing a fence",
+			notebook: `{"nbformat": 4, "metadata": {}, "cells": [{"cell_type": "code", "source": "s = '''\n` + "```" + `\n'''", "outputs": []}]}`,
+			expected: "````\ns = '''\n```\n'''\n````\n",
+		},
+		{
+			name:     "Empty cells",
+			notebook: `{"nbformat": 4, "metadata": {}, "cells": [{"cell_type": "markdown", "source": []}, {"cell_type": "code", "source": "\n", "outputs": []}, {"cell_type": "markdown", "source": "# Doc"}]}`,
+			expected: "# Doc\n",
+			title:    "Doc",
+		},
+		{
+			name:     "Old format",
+			notebook: `{"nbformat": 3, "worksheets": []}`,
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid JSON",
+			notebook: `{"nbformat": 4, "cells": [`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := convertNotebook([]byte(tt.notebook), false, 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Markdown != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result.Markdown)
+			}
+			if result.Title != tt.title {
+				t.Errorf("expected title %q, got %q", tt.title, result.Title)
+			}
+		})
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		limit    int
+		expected string
+	}{
+		{"Under the limit", "short", 10, "short"},
+		{"No limit", "a longer line of output", 0, "a longer line of output"},
+		{"At a line break", "line one\nline two\nline three", 15, "line one\n... (output truncated)"},
+		{"Within a line", "abcdefghij", 4, "abcd\n... (output truncated)"},
+		{"Within a character", "ééé", 3, "é\n... (output truncated)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateOutput(tt.text, tt.limit); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSniffNotebook(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{`{"cells": [], "metadata": {}, "nbformat": 4}`, true},
+		{`{"name": "package", "version": "1.0"}`, false},
+		{`<html>"nbformat" "cells"</html>`, false},
+	}
+	for _, tt := range tests {
+		if got := (notebookConverter{}).Sniff([]byte(tt.content)); got != tt.expected {
+			t.Errorf("Sniff(%q) = %v, expected %v", tt.content, got, tt.expected)
+		}
+	}
+}
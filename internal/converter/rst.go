@@ -0,0 +1,274 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(rstConverter{}, "rst")
+}
+
+// rstConverter converts reStructuredText documents
+type rstConverter struct{}
+
+func (rstConverter) Name() string { return string(types.TypeReStructuredText) }
+
+func (rstConverter) Extensions() []string { return []string{".rst"} }
+
+// Sniff recognizes nothing: reStructuredText has no reliable marker
+func (rstConverter) Sniff(content []byte) bool { return false }
+
+func (rstConverter) Convert(content []byte) (*Result, error) {
+	markdown, title, err := convertRST(content)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Markdown: markdown, Title: title}, nil
+}
+
+// convertRST converts reStructuredText to Markdown
+func convertRST(content []byte) (string, string, error) {
+	// Basic RST to Markdown conversion
+	// This is a simplified implementation
+	text := string(content)
+	title := extractRSTTitle(text)
+
+	// Convert RST headings to Markdown
+	markdown := convertRSTHeadings(text)
+
+	// Convert RST images to Markdown
+	markdown = convertRSTImages(markdown)
+
+	return markdown, title, nil
+}
+
+// extractRSTTitle extracts the title from reStructuredText
+func extractRSTTitle(content string) string {
+	lines := strings.Split(content, "\n")
+
+	for i := 0; i < len(lines)-1; i++ {
+		current := strings.TrimSpace(lines[i])
+		next := strings.TrimSpace(lines[i+1])
+
+		// Skip RST directives, anchors, and labels (.. name: or .. _name:)
+		if strings.HasPrefix(current, "..") && strings.HasSuffix(current, ":") {
+			continue
+		}
+
+		// Check for overline+underline pattern (heading with line above and below)
+		if i+2 < len(lines) && isUnderline(current) {
+			text := strings.TrimSpace(lines[i+1])
+			underline := strings.TrimSpace(lines[i+2])
+
+			// Make sure the text line is not a directive either
+			if text != "" && current == underline && isUnderline(underline) &&
+				!(strings.HasPrefix(text, "..") && strings.HasSuffix(text, ":")) {
+				// This is a heading with overline and underline - likely the title
+				return cleanHeadingText(text)
+			}
+		}
+
+		// Check for underline-only pattern (=, -, ~, etc.)
+		if current != "" && next != "" {
+			char := next[0]
+			if (char == '=' || char == '-' || char == '~' || char == '#' || char == '*') &&
+				strings.Count(next, string(char)) == len(next) &&
+				len(next) >= len(current) {
+				return cleanHeadingText(current)
+			}
+		}
+	}
+
+	return ""
+}
+
+// convertRSTHeadings converts RST-style headings to Markdown
+func convertRSTHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	var result []string
+
+	// Track heading patterns in order of appearance
+	headingPatterns := make(map[string]int)
+	nextLevel := 1
+
+	i := 0
+	for i < len(lines) {
+		// Skip RST directives, anchors, and labels (.. name: or .. _name:)
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "..") && strings.HasSuffix(trimmed, ":") {
+			i++
+			continue
+		}
+
+		current := lines[i]
+		currentTrim := strings.TrimSpace(current)
+
+		// Check for heading with overline and underline
+		if i+2 < len(lines) && isUnderline(currentTrim) {
+			text := strings.TrimSpace(lines[i+1])
+			underline := strings.TrimSpace(lines[i+2])
+
+			if text != "" && currentTrim == underline && isUnderline(underline) {
+				// This is a heading with overline and underline
+				pattern := string(currentTrim[0]) + "o" // 'o' for overline
+				level := getOrAssignLevel(pattern, headingPatterns, &nextLevel)
+				cleanText := cleanHeadingText(text)
+				result = append(result, strings.Repeat("#", level)+" "+cleanText)
+				i += 3
+				continue
+			}
+		}
+
+		// Check for heading with just underline
+		if i+1 < len(lines) && currentTrim != "" {
+			next := strings.TrimSpace(lines[i+1])
+			if isUnderline(next) && len(next) >= len(currentTrim) {
+				// This is a heading with just underline
+				pattern := string(next[0]) + "u" // 'u' for underline only
+				level := getOrAssignLevel(pattern, headingPatterns, &nextLevel)
+				cleanText := cleanHeadingText(currentTrim)
+				result = append(result, strings.Repeat("#", level)+" "+cleanText)
+				i += 2
+				continue
+			}
+		}
+
+		result = append(result, current)
+		i++
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// isUnderline checks if a line is a valid RST underline (all same punctuation)
+func isUnderline(line string) bool {
+	if line == "" {
+		return false
+	}
+
+	// Check if all characters are the same punctuation
+	char := line[0]
+	if !isPunctuation(char) {
+		return false
+	}
+
+	for _, c := range line {
+		if byte(c) != char {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPunctuation checks if a character is a valid RST heading punctuation
+func isPunctuation(c byte) bool {
+	// Common RST heading characters
+	punctuation := "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+	return strings.ContainsRune(punctuation, rune(c))
+}
+
+// getOrAssignLevel gets or assigns a heading level for a pattern
+func getOrAssignLevel(pattern string, patterns map[string]int, nextLevel *int) int {
+	if level, exists := patterns[pattern]; exists {
+		return level
+	}
+
+	level := *nextLevel
+	patterns[pattern] = level
+	*nextLevel++
+
+	// Cap at level 6 (max Markdown heading level)
+	if *nextLevel > 6 {
+		*nextLevel = 6
+	}
+
+	return level
+}
+
+// cleanHeadingText removes RST directives and extra formatting from heading text
+func cleanHeadingText(text string) string {
+	// Remove inline directives like :index:, :ref:, etc.
+	// Pattern: `text`:directive:
+	re := regexp.MustCompile("`([^`]+)`:[a-zA-Z]+:")
+	text = re.ReplaceAllString(text, "$1")
+
+	// Remove just the directive part if no backticks
+	// Pattern: :directive:
+	re2 := regexp.MustCompile(":[a-zA-Z]+:")
+	text = re2.ReplaceAllString(text, "")
+
+	return strings.TrimSpace(text)
+}
+
+// convertRSTImages converts RST image directives to Markdown format
+func convertRSTImages(content string) string {
+	lines := strings.Split(content, "\n")
+	var result []string
+
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Check for image or figure directive
+		if strings.HasPrefix(trimmed, ".. image::") || strings.HasPrefix(trimmed, ".. figure::") {
+			// Extract image path
+			parts := strings.SplitN(trimmed, "::", 2)
+			if len(parts) == 2 {
+				imagePath := strings.TrimSpace(parts[1])
+				altText := ""
+
+				// Look ahead for :alt: option
+				j := i + 1
+				for j < len(lines) {
+					nextLine := strings.TrimSpace(lines[j])
+
+					// Stop if we hit a non-indented line or empty line after options
+					if nextLine == "" {
+						break
+					}
+					if !strings.HasPrefix(lines[j], "   ") && !strings.HasPrefix(lines[j], "\t") {
+						break
+					}
+
+					// Extract alt text
+					if strings.HasPrefix(nextLine, ":alt:") {
+						altParts := strings.SplitN(nextLine, ":alt:", 2)
+						if len(altParts) == 2 {
+							altText = strings.TrimSpace(altParts[1])
+						}
+					}
+					j++
+				}
+
+				// Convert to Markdown format
+				markdownImage := fmt.Sprintf("![%s](%s)", altText, imagePath)
+				result = append(result, markdownImage)
+				result = append(result, "")
+
+				// Skip the directive and its options
+				i = j
+				continue
+			}
+		}
+
+		result = append(result, line)
+		i++
+	}
+
+	return strings.Join(result, "\n")
+}
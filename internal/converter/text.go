@@ -0,0 +1,148 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func init() {
+	Register(textConverter{}, "text", "txt", "plaintext")
+}
+
+// maxTextTitle is the length, in characters, beyond which the first line
+// of a plain text document is taken to be prose rather than a title
+const maxTextTitle = 100
+
+// textFileNames are the names (in upper case) of files without an
+// extension that are plain text unless their content says otherwise
+var textFileNames = map[string]bool{
+	"README": true, "INSTALL": true, "LICENSE": true, "LICENCE": true,
+	"COPYING": true, "AUTHORS": true, "CHANGES": true, "CHANGELOG": true,
+	"HISTORY": true, "NEWS": true, "NOTICE": true, "CONTRIBUTING": true,
+	"TODO": true,
+}
+
+// textConverter converts plain text documents
+type textConverter struct{}
+
+func (textConverter) Name() string { return string(types.TypePlainText) }
+
+func (textConverter) Extensions() []string { return []string{".text"} }
+
+// Sniff recognizes nothing, as any text would do: plain text is the
+// fallback for sniffed files (see Fallback)
+func (textConverter) Sniff(content []byte) bool { return false }
+
+// Fallback accepts .txt files, and files without an extension named like
+// README or LICENSE, that no other converter recognizes, as long as they
+// hold UTF-8 text
+func (textConverter) Fallback(filename string, content []byte) bool {
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	if !strings.EqualFold(ext, ".txt") && (ext != "" || !textFileNames[strings.ToUpper(base)]) {
+		return false
+	}
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) < 0
+}
+
+func (textConverter) Convert(content []byte) (*Result, error) {
+	return convertText(content), nil
+}
+
+// convertText converts plain text to Markdown, keeping its paragraphs
+// (separated by blank lines) and lines as they are. The first line is the
+// title if it is a paragraph of its own, or is underlined; lines
+// underlined with = or - become headings.
+func convertText(content []byte) *Result {
+	text := string(bytes.TrimPrefix(content, utf8BOM))
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\f", "\n").Replace(text)
+
+	var paragraphs [][]string
+	var current []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if current != nil {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if current != nil {
+		paragraphs = append(paragraphs, current)
+	}
+
+	title := ""
+	parts := make([]string, 0, len(paragraphs))
+	for i, lines := range paragraphs {
+		if len(lines) == 2 && isTextUnderline(lines[1]) && !isTextUnderline(lines[0]) {
+			heading := strings.TrimSpace(lines[0])
+			level := "## "
+			if lines[1][0] == '=' {
+				level = "# "
+			}
+			if i == 0 {
+				title = heading
+			}
+			parts = append(parts, level+escapeTextLine(heading))
+			continue
+		}
+		if i == 0 && len(lines) == 1 && utf8.RuneCountInString(strings.TrimSpace(lines[0])) <= maxTextTitle {
+			title = strings.TrimSpace(lines[0])
+			parts = append(parts, "# "+escapeTextLine(title))
+			continue
+		}
+
+		escaped := make([]string, len(lines))
+		for j, line := range lines {
+			escaped[j] = escapeTextLine(line)
+			// A line of = or - after text would make the paragraph a
+			// Markdown heading
+			if j > 0 && isTextUnderline(line) {
+				escaped[j] = `\` + line
+			}
+		}
+		parts = append(parts, strings.Join(escaped, "\n"))
+	}
+
+	if len(parts) == 0 {
+		return &Result{}
+	}
+	return &Result{Markdown: strings.Join(parts, "\n\n") + "\n", Title: title}
+}
+
+// isTextUnderline returns true if a line is a run of three or more = or -
+// characters, underlining the line before it
+func isTextUnderline(line string) bool {
+	line = strings.TrimSpace(line)
+	if len(line) < 3 || (line[0] != '=' && line[0] != '-') {
+		return false
+	}
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// escapeTextLine escapes a # at the start of a line, which would otherwise
+// make it a Markdown heading (lines indented by four spaces are already
+// code)
+func escapeTextLine(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if indent := len(line) - len(trimmed); indent < 4 && strings.HasPrefix(trimmed, "#") {
+		return line[:indent] + `\` + trimmed
+	}
+	return line
+}
@@ -0,0 +1,91 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		title    string
+	}{
+		{
+			name:     "Title line and paragraphs",
+			text:     "pgEdge Widgets\r\n\r\nWidgets make\r\neverything better.\r\n\r\n\r\n\r\nInstall them   \r\nfirst.\r\n",
+			expected: "# pgEdge Widgets\n\nWidgets make\neverything better.\n\nInstall them\nfirst.\n",
+			title:    "pgEdge Widgets",
+		},
+		{
+			name:     "Underlined headings",
+			text:     "Widgets\n=======\n\nInstalling\n----------\n\nRun the installer.\n",
+			expected: "# Widgets\n\n## Installing\n\nRun the installer.\n",
+			title:    "Widgets",
+		},
+		{
+			name:     "No title line",
+			text:     "These notes cover\nthe widget release.\n",
+			expected: "These notes cover\nthe widget release.\n",
+		},
+		{
+			name:     "First line too long to be a title",
+			text:     strings.Repeat("word ", 30) + "\n",
+			expected: strings.TrimSpace(strings.Repeat("word ", 30)) + "\n",
+		},
+		{
+			name:     "Markdown headings and underlines escaped",
+			text:     "Notes\n\n# not a heading\ntext\n---\n\n    # indented code\n",
+			expected: "# Notes\n\n\\# not a heading\ntext\n\\---\n\n    # indented code\n",
+			title:    "Notes",
+		},
+		{
+			name:     "Empty",
+			text:     "\n \n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertText([]byte(tt.text))
+			if result.Markdown != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result.Markdown)
+			}
+			if result.Title != tt.title {
+				t.Errorf("expected title %q, got %q", tt.title, result.Title)
+			}
+		})
+	}
+}
+
+func TestTextFallback(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		expected bool
+	}{
+		{"notes.txt", "Notes", true},
+		{"NOTES.TXT", "Notes", true},
+		{"README", "Read me", true},
+		{"docs/license", "Terms", true},
+		{"README.first", "Read me", false},
+		{"Makefile", "all:", false},
+		{"notes.txt", "\xff\xfe", false},
+	}
+	for _, tt := range tests {
+		if got := (textConverter{}).Fallback(tt.filename, []byte(tt.content)); got != tt.expected {
+			t.Errorf("Fallback(%q, %q) = %v, expected %v", tt.filename, tt.content, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// customColumn is a custom metadata column with its compiled value template
+type customColumn struct {
+	name    string
+	colType string
+	tmpl    *template.Template
+}
+
+// templateData is the data available to custom column value templates
+type templateData struct {
+	FileName     string
+	Title        string
+	DocumentType string
+	FileCreated  *time.Time
+	FileModified *time.Time
+	RunID        string
+	Source       string
+	GitURL       string
+	GitRef       string
+	GitCommit    string
+}
+
+// templateFuncs are the functions available to custom column value templates
+var templateFuncs = template.FuncMap{
+	"dir":  filepath.Dir,
+	"base": filepath.Base,
+	"ext":  filepath.Ext,
+	"stem": func(path string) string {
+		base := filepath.Base(path)
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+	"env":        os.Getenv,
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+	"date": func(layout string, t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(layout)
+	},
+}
+
+// compileCustomColumns compiles the global custom column value templates,
+// in column name order
+func compileCustomColumns(cfg *types.Config) ([]customColumn, error) {
+	return compileColumnSet(cfg.CustomColumns, cfg.CustomColumnTypes)
+}
+
+// compileSourceColumns compiles the custom columns for documents from a
+// configured source: the global columns, with the source's values and
+// types taking precedence
+func compileSourceColumns(cfg *types.Config, src *types.SourceConfig) ([]customColumn, error) {
+	values := make(map[string]string, len(cfg.CustomColumns)+len(src.CustomColumns))
+	colTypes := make(map[string]string, len(cfg.CustomColumnTypes)+len(src.CustomColumnTypes))
+	for name, value := range cfg.CustomColumns {
+		values[name] = value
+		colTypes[name] = cfg.CustomColumnTypes[name]
+	}
+	for name, value := range src.CustomColumns {
+		values[name] = value
+		colTypes[name] = src.CustomColumnTypes[name]
+	}
+
+	columns, err := compileColumnSet(values, colTypes)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", src.Name, err)
+	}
+	return columns, nil
+}
+
+// compileColumnSet compiles a set of custom column value templates, in
+// column name order
+func compileColumnSet(values, colTypes map[string]string) ([]customColumn, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]customColumn, 0, len(names))
+	for _, name := range names {
+		colType := strings.ToLower(strings.TrimSpace(colTypes[name]))
+		if colType != "" && !types.IsSupportedColumnType(colType) {
+			return nil, fmt.Errorf("custom column %s: unsupported type %q", name, colType)
+		}
+
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("custom column %s: invalid template: %w", name, err)
+		}
+
+		columns = append(columns, customColumn{name: name, colType: colType, tmpl: tmpl})
+	}
+
+	return columns, nil
+}
+
+// customColumns returns the compiled custom columns for a document,
+// compiling them on first use. Documents from a source with its own custom
+// column values use that source's columns.
+func (c *Client) customColumns(doc *types.Document) ([]customColumn, error) {
+	if c.custom == nil {
+		columns, err := compileCustomColumns(c.config)
+		if err != nil {
+			return nil, err
+		}
+		c.custom = columns
+	}
+
+	src := c.config.SourceByName(doc.Source)
+	if src == nil || len(src.CustomColumns) == 0 {
+		return c.custom, nil
+	}
+
+	if columns, ok := c.sourceCustom[src.Name]; ok {
+		return columns, nil
+	}
+	columns, err := compileSourceColumns(c.config, src)
+	if err != nil {
+		return nil, err
+	}
+	if c.sourceCustom == nil {
+		c.sourceCustom = make(map[string][]customColumn)
+	}
+	c.sourceCustom[src.Name] = columns
+	return columns, nil
+}
+
+// customColumnNames returns the names of all custom columns, global or set
+// by any source, in order
+func (c *Client) customColumnNames() []string {
+	seen := make(map[string]bool)
+	for name := range c.config.CustomColumns {
+		seen[name] = true
+	}
+	for _, src := range c.config.Sources {
+		for name := range src.CustomColumns {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateData returns the template data for a document
+func (c *Client) templateData(doc *types.Document) templateData {
+	data := templateData{
+		FileName:     doc.FileName,
+		Title:        doc.Title,
+		DocumentType: doc.DocumentType.String(),
+		FileCreated:  doc.FileCreated,
+		FileModified: doc.FileModified,
+		Source:       doc.Source,
+	}
+	if c.run != nil {
+		data.RunID = c.run.ID
+		data.GitURL = c.run.GitURL
+		data.GitRef = c.run.GitRef
+		data.GitCommit = c.run.GitCommit
+	}
+
+	// Documents loaded from Git use the repository, ref and commit they
+	// were checked out from, rather than those of the whole run
+	if doc.GitURL != "" {
+		data.GitURL = doc.GitURL
+		data.GitRef = doc.GitRef
+		data.GitCommit = doc.GitCommit
+	}
+	return data
+}
+
+// value evaluates the column template for a document and returns the value
+// to bind. Values are bound as text and cast by the query; empty values of
+// non-text types are bound as NULL, and array values are given either as a
+// JSON array or a comma-separated list.
+func (col *customColumn) value(data *templateData) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := col.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("custom column %s: %w", col.name, err)
+	}
+	value := buf.String()
+
+	switch {
+	case col.colType == "" || col.colType == "text" || col.colType == "varchar":
+		return value, nil
+	case strings.TrimSpace(value) == "":
+		return nil, nil
+	case strings.HasSuffix(col.colType, "[]"):
+		literal, err := parseArrayValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("custom column %s: %w", col.name, err)
+		}
+		return literal, nil
+	default:
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// parseArrayValue parses a JSON array or comma-separated list into a
+// Postgres array literal, which is cast to the column type by the query.
+// JSON numbers are kept as written, null elements become NULL, and nested
+// arrays and objects are stored as JSON.
+func parseArrayValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	var elements []*string
+	if strings.HasPrefix(value, "[") {
+		var raw []interface{}
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return "", fmt.Errorf("invalid JSON array %q: %w", value, err)
+		}
+		if dec.More() {
+			return "", fmt.Errorf("invalid JSON array %q: unexpected data after the array", value)
+		}
+		for _, v := range raw {
+			element, err := jsonArrayElement(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid JSON array %q: %w", value, err)
+			}
+			elements = append(elements, element)
+		}
+	} else {
+		for _, v := range strings.Split(value, ",") {
+			element := strings.TrimSpace(v)
+			elements = append(elements, &element)
+		}
+	}
+
+	return arrayLiteral(elements), nil
+}
+
+// jsonArrayElement returns the text of an element of a decoded JSON array,
+// or nil for null
+func jsonArrayElement(v interface{}) (*string, error) {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case bool:
+		s = fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s = string(data)
+	}
+	return &s, nil
+}
+
+// arrayLiteral formats elements as a Postgres array literal, with nil
+// elements as NULL
+func arrayLiteral(elements []*string) string {
+	quoted := make([]string, len(elements))
+	for i, e := range elements {
+		if e == nil {
+			quoted[i] = "NULL"
+			continue
+		}
+		s := strings.ReplaceAll(*e, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		quoted[i] = `"` + s + `"`
+	}
+
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// frontMatterColumn is a column receiving the value of a front matter key
+type frontMatterColumn struct {
+	key    string
+	column string
+}
+
+// frontMatterColumns returns the front matter column mappings, in column
+// name order
+func (c *Client) frontMatterColumns() []frontMatterColumn {
+	columns := make([]frontMatterColumn, 0, len(c.config.FrontMatterColumns))
+	for key, column := range c.config.FrontMatterColumns {
+		columns = append(columns, frontMatterColumn{key: key, column: column})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].column < columns[j].column
+	})
+	return columns
+}
+
+// frontMatterValue converts a front matter value for binding. Values are
+// bound as text and converted to the column type by the server: lists
+// become array literals (with null elements as NULL, and lists and maps
+// within them as JSON) and maps become JSON.
+func frontMatterValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v, nil
+	case []interface{}:
+		elements := make([]*string, len(v))
+		for i, e := range v {
+			switch e.(type) {
+			case nil:
+				continue
+			case []interface{}, map[string]interface{}:
+				data, err := json.Marshal(e)
+				if err != nil {
+					return nil, err
+				}
+				s := string(data)
+				elements[i] = &s
+			default:
+				s := fmt.Sprint(e)
+				elements[i] = &s
+			}
+		}
+		return arrayLiteral(elements), nil
+	case map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// metadataValue returns the front matter of a document as JSON, or nil if
+// the document has none
+func metadataValue(doc *types.Document) (interface{}, error) {
+	if doc.Metadata == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	return string(data), nil
+}
+
+// nullableString returns nil for an empty string, so that unknown values
+// are stored as NULL
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// placeholder returns the query placeholder for the column, cast to the
+// column type where one is configured
+func (col *customColumn) placeholder(argIndex int) string {
+	if col.colType == "" {
+		return fmt.Sprintf("$%d", argIndex)
+	}
+	return fmt.Sprintf("$%d::%s", argIndex, col.colType)
+}
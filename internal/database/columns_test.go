@@ -0,0 +1,327 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestCustomColumnTemplates(t *testing.T) {
+	os.Setenv("DOCLOADER_TEST_BUILD", "1234")
+	defer os.Unsetenv("DOCLOADER_TEST_BUILD")
+
+	modTime := time.Date(2026, 3, 13, 10, 0, 0, 0, time.UTC)
+	doc := &types.Document{
+		Title:        "Getting Started",
+		FileName:     "docs/guide/start.md",
+		FileModified: &modTime,
+		DocumentType: types.TypeMarkdown,
+	}
+
+	run := types.NewRunInfo()
+	run.GitRef = "v17.0"
+
+	client := &Client{
+		config: &types.Config{},
+		run:    run,
+	}
+	data := client.templateData(doc)
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"Plain value", "pgAdmin 4", "pgAdmin 4"},
+		{"Directory", "{{.FileName | dir}}", "docs/guide"},
+		{"Stem", "{{.FileName | stem}}", "start"},
+		{"Title", "{{.Title | lower}}", "getting started"},
+		{"Git ref", "{{.GitRef}}", "v17.0"},
+		{"Environment", `{{env "DOCLOADER_TEST_BUILD"}}`, "1234"},
+		{"Default", `{{env "DOCLOADER_TEST_UNSET" | default "none"}}`, "none"},
+		{"Date", `{{date "2006-01-02" .FileModified}}`, "2026-03-13"},
+		{"Document type", "{{.DocumentType}}", "Markdown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := compileCustomColumns(&types.Config{
+				CustomColumns: map[string]string{"col": tt.template},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			value, err := columns[0].value(&data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestTemplateDataGitSource(t *testing.T) {
+	run := types.NewRunInfo()
+	run.GitURL = "https://example.com/a.git,https://example.com/b.git"
+	run.GitRef = "main,main"
+	run.GitCommit = "aaa,bbb"
+	run.Checkouts = []types.GitCheckout{
+		{URL: "https://example.com/a.git", Ref: "main", Commit: "aaa"},
+		{URL: "https://example.com/b.git", Ref: "main", Commit: "bbb"},
+	}
+
+	client := &Client{
+		config: &types.Config{},
+		run:    run,
+	}
+
+	// A document from one of several repositories on the same ref uses
+	// its own repository and commit
+	data := client.templateData(&types.Document{
+		FileName:  "guide.md",
+		GitURL:    "https://example.com/a.git",
+		GitRef:    "main",
+		GitCommit: "aaa",
+	})
+	if data.GitURL != "https://example.com/a.git" || data.GitRef != "main" || data.GitCommit != "aaa" {
+		t.Errorf("expected the document's repository, ref and commit, got %s %s %s",
+			data.GitURL, data.GitRef, data.GitCommit)
+	}
+
+	// Other documents use those of the run
+	data = client.templateData(&types.Document{FileName: "local.md"})
+	if data.GitURL != run.GitURL || data.GitCommit != run.GitCommit {
+		t.Errorf("expected the run's repositories and commits, got %s %s", data.GitURL, data.GitCommit)
+	}
+}
+
+func TestCustomColumnTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		colType     string
+		value       string
+		placeholder string
+		expected    interface{}
+	}{
+		{"Untyped", "", "10", "$1", "10"},
+		{"Integer", "integer", " 10 ", "$1::integer", "10"},
+		{"Empty integer", "integer", "", "$1::integer", nil},
+		{"Boolean", "boolean", "true", "$1::boolean", "true"},
+		{"JSONB", "jsonb", `{"a": 1}`, "$1::jsonb", `{"a": 1}`},
+		{"Text array from list", "text[]", "a, b", "$1::text[]", `{"a","b"}`},
+		{"Integer array from JSON", "integer[]", "[1, 2]", "$1::integer[]", `{"1","2"}`},
+		{"Array with quotes", "text[]", `["say \"hi\""]`, "$1::text[]", `{"say \"hi\""}`},
+		{"Large numbers as written", "bigint[]", "[1000000, 12345678901234567890, 1.5]", "$1::bigint[]", `{"1000000","12345678901234567890","1.5"}`},
+		{"Null elements", "integer[]", "[1, null]", "$1::integer[]", `{"1",NULL}`},
+		{"Nested JSON", "jsonb[]", `[{"a": 1}, [1, 2], true]`, "$1::jsonb[]", `{"{\"a\":1}","[1,2]","true"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := compileCustomColumns(&types.Config{
+				CustomColumns:     map[string]string{"col": tt.value},
+				CustomColumnTypes: map[string]string{"col": tt.colType},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if p := columns[0].placeholder(1); p != tt.placeholder {
+				t.Errorf("expected placeholder %s, got %s", tt.placeholder, p)
+			}
+
+			value, err := columns[0].value(&templateData{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestCompileCustomColumnsErrors(t *testing.T) {
+	_, err := compileCustomColumns(&types.Config{
+		CustomColumns:     map[string]string{"col": "1"},
+		CustomColumnTypes: map[string]string{"col": "integer); DROP TABLE documents; --"},
+	})
+	if err == nil {
+		t.Error("expected error for unsupported type, got nil")
+	}
+
+	_, err = compileCustomColumns(&types.Config{
+		CustomColumns: map[string]string{"col": "{{.FileName"},
+	})
+	if err == nil {
+		t.Error("expected error for invalid template, got nil")
+	}
+}
+
+func TestBuildQueriesWithTypedColumns(t *testing.T) {
+	doc := &types.Document{
+		Title:    "Test Title",
+		FileName: "docs/test.md",
+	}
+
+	client := &Client{config: &types.Config{
+		DBTable:        "documents",
+		ColumnDocTitle: "title",
+		ColumnFileName: "filename",
+		CustomColumns: map[string]string{
+			"section": "{{.FileName | dir}}",
+			"weight":  "10",
+		},
+		CustomColumnTypes: map[string]string{"weight": "integer"},
+	}}
+
+	query, args, err := client.buildInsertQuery(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `INSERT INTO "documents" ("title", "filename", "section", "weight") VALUES ($1, $2, $3, $4::integer)`
+	if query != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, query)
+	}
+	if args[2] != "docs" || args[3] != "10" {
+		t.Errorf("unexpected custom column args: %v", args[2:])
+	}
+
+	query, _, err = client.buildUpdateQuery(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, `"weight" = $3::integer`) {
+		t.Errorf("expected typed SET clause, got: %s", query)
+	}
+}
+
+func TestSourceCustomColumns(t *testing.T) {
+	client := &Client{config: &types.Config{
+		DBTable:        "documents",
+		ColumnFileName: "filename",
+		CustomColumns: map[string]string{
+			"product": "Docs",
+			"weight":  "10",
+		},
+		CustomColumnTypes: map[string]string{"weight": "integer"},
+		Sources: []types.SourceConfig{
+			{
+				Name:              "reference",
+				Type:              types.SourceLocal,
+				Paths:             []string{"./generated"},
+				CustomColumns:     map[string]string{"product": "Reference {{.Source}}", "kind": "generated"},
+				CustomColumnTypes: map[string]string{},
+			},
+		},
+	}}
+
+	// Documents from other sources use the global columns
+	query, args, err := client.buildInsertQuery(&types.Document{FileName: "a.md", Source: "https://example.com/docs.git"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `INSERT INTO "documents" ("filename", "product", "weight") VALUES ($1, $2, $3::integer)`
+	if query != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, query)
+	}
+	if args[1] != "Docs" {
+		t.Errorf("expected global product, got %v", args[1])
+	}
+
+	// Source values override and add to the global columns
+	query, args, err = client.buildInsertQuery(&types.Document{FileName: "b.md", Source: "reference"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `INSERT INTO "documents" ("filename", "kind", "product", "weight") VALUES ($1, $2, $3, $4::integer)`
+	if query != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, query)
+	}
+	if args[1] != "generated" || args[2] != "Reference reference" || args[3] != "10" {
+		t.Errorf("unexpected source column args: %v", args[1:])
+	}
+
+	// The history table receives the columns of every source
+	names := client.customColumnNames()
+	if strings.Join(names, ",") != "kind,product,weight" {
+		t.Errorf("unexpected custom column names: %v", names)
+	}
+}
+
+func TestBuildQueriesWithFrontMatter(t *testing.T) {
+	doc := &types.Document{
+		FileName: "docs/test.md",
+		Metadata: map[string]interface{}{
+			"description": "A page",
+			"tags":        []interface{}{"admin", "gui"},
+			"weight":      10,
+		},
+	}
+
+	client := &Client{config: &types.Config{
+		DBTable:        "documents",
+		ColumnFileName: "filename",
+		ColumnMetadata: "front_matter",
+		FrontMatterColumns: map[string]string{
+			"description": "summary",
+			"tags":        "tags",
+			"weight":      "sort_order",
+			"missing":     "extra",
+		},
+	}}
+
+	query, args, err := client.buildInsertQuery(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `INSERT INTO "documents" ("filename", "front_matter", "extra", "sort_order", "summary", "tags") VALUES ($1, $2, $3, $4, $5, $6)`
+	if query != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, query)
+	}
+
+	if args[1] != `{"description":"A page","tags":["admin","gui"],"weight":10}` {
+		t.Errorf("unexpected metadata JSON: %v", args[1])
+	}
+	if args[2] != nil {
+		t.Errorf("expected NULL for missing key, got %v", args[2])
+	}
+	if args[3] != "10" || args[4] != "A page" || args[5] != `{"admin","gui"}` {
+		t.Errorf("unexpected front matter args: %v", args[3:])
+	}
+
+	// Lists and maps within a list are stored as JSON, and nulls as NULL
+	doc.Metadata["tags"] = []interface{}{map[string]interface{}{"a": 1}, []interface{}{1, 2}, nil, "gui"}
+	if _, args, err = client.buildInsertQuery(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[5] != `{"{\"a\":1}","[1,2]",NULL,"gui"}` {
+		t.Errorf("unexpected nested list value: %v", args[5])
+	}
+
+	// Documents without front matter store NULL metadata
+	_, args, err = client.buildUpdateQuery(&types.Document{FileName: "plain.md"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[0] != nil {
+		t.Errorf("expected NULL metadata, got %v", args[0])
+	}
+}
@@ -23,8 +23,14 @@ import (
 
 // Client represents a database client
 type Client struct {
-	pool   *pgxpool.Pool
-	config *types.Config
+	pool    *pgxpool.Pool
+	config  *types.Config
+	run     *types.RunInfo
+	changes []*types.FileChanges
+	custom  []customColumn
+
+	// Custom columns for sources with their own values, by source name
+	sourceCustom map[string][]customColumn
 }
 
 // New creates a new database client
@@ -32,6 +38,23 @@ func New(cfg *types.Config) (*Client, error) {
 	// Build connection string
 	connStr := buildConnectionString(cfg)
 
+	// Compile custom column templates before connecting
+	custom, err := compileCustomColumns(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sourceCustom := make(map[string][]customColumn)
+	for i := range cfg.Sources {
+		if len(cfg.Sources[i].CustomColumns) == 0 {
+			continue
+		}
+		columns, err := compileSourceColumns(cfg, &cfg.Sources[i])
+		if err != nil {
+			return nil, err
+		}
+		sourceCustom[cfg.Sources[i].Name] = columns
+	}
+
 	// Create connection pool
 	poolConfig, err := pgxpool.ParseConfig(connStr)
 	if err != nil {
@@ -50,8 +73,10 @@ func New(cfg *types.Config) (*Client, error) {
 	}
 
 	return &Client{
-		pool:   pool,
-		config: cfg,
+		pool:         pool,
+		config:       cfg,
+		custom:       custom,
+		sourceCustom: sourceCustom,
 	}, nil
 }
 
@@ -60,7 +85,14 @@ func (c *Client) Close() {
 	c.pool.Close()
 }
 
-// InsertDocuments inserts or updates documents in the database
+// SetRunInfo sets the run recorded in the run log table and stamped into
+// the run ID column
+func (c *Client) SetRunInfo(run *types.RunInfo) {
+	c.run = run
+}
+
+// InsertDocuments inserts or updates documents in the database in a single
+// transaction
 func (c *Client) InsertDocuments(ctx context.Context, documents []*types.Document, stats *types.Stats) error {
 	// Begin transaction
 	tx, err := c.pool.Begin(ctx)
@@ -71,6 +103,12 @@ func (c *Client) InsertDocuments(ctx context.Context, documents []*types.Documen
 		_ = tx.Rollback(ctx) //nolint:errcheck // Rollback on defer is safe to ignore
 	}()
 
+	// Rename and delete rows first, so that renamed documents that were
+	// also modified update the renamed row
+	if err := c.applyFileChanges(ctx, tx, stats); err != nil {
+		return err
+	}
+
 	for _, doc := range documents {
 		if c.config.UpdateMode {
 			// Try update first, then insert if not found
@@ -96,6 +134,15 @@ func (c *Client) InsertDocuments(ctx context.Context, documents []*types.Documen
 		}
 	}
 
+	// Record the loaded commit with the documents it produced. Files that
+	// failed to load leave it where it was, so that the next incremental
+	// load retries them.
+	if !stats.HasErrors() {
+		if err := c.recordState(ctx, tx); err != nil {
+			return err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -106,9 +153,12 @@ func (c *Client) InsertDocuments(ctx context.Context, documents []*types.Documen
 
 // insertDocument inserts a document into the database
 func (c *Client) insertDocument(ctx context.Context, tx pgx.Tx, doc *types.Document) error {
-	query, args := c.buildInsertQuery(doc)
+	query, args, err := c.buildInsertQuery(doc)
+	if err != nil {
+		return fmt.Errorf("failed to build insert for %s: %w", doc.FileName, err)
+	}
 
-	_, err := tx.Exec(ctx, query, args...)
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to insert document: %w", err)
 	}
@@ -123,12 +173,12 @@ func (c *Client) updateDocument(ctx context.Context, tx pgx.Tx, doc *types.Docum
 		return false, nil
 	}
 
-	checkQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1",
-		pgx.Identifier{c.config.DBTable}.Sanitize(),
-		pgx.Identifier{c.config.ColumnFileName}.Sanitize())
+	match, matchArgs := c.documentMatch("", doc.FileName, doc.GitRef, 1)
+	checkQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s",
+		pgx.Identifier{c.config.DBTable}.Sanitize(), match)
 
 	var count int
-	err := tx.QueryRow(ctx, checkQuery, doc.FileName).Scan(&count)
+	err := tx.QueryRow(ctx, checkQuery, matchArgs...).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check document existence: %w", err)
 	}
@@ -137,8 +187,18 @@ func (c *Client) updateDocument(ctx context.Context, tx pgx.Tx, doc *types.Docum
 		return false, nil
 	}
 
+	// Keep the previous version if history is enabled
+	if c.config.HistoryTable != "" {
+		if err := c.archiveDocument(ctx, tx, doc, false); err != nil {
+			return false, err
+		}
+	}
+
 	// Build update query
-	query, args := c.buildUpdateQuery(doc)
+	query, args, err := c.buildUpdateQuery(doc)
+	if err != nil {
+		return false, fmt.Errorf("failed to build update for %s: %w", doc.FileName, err)
+	}
 
 	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
@@ -149,7 +209,7 @@ func (c *Client) updateDocument(ctx context.Context, tx pgx.Tx, doc *types.Docum
 }
 
 // buildInsertQuery builds an INSERT query
-func (c *Client) buildInsertQuery(doc *types.Document) (string, []interface{}) {
+func (c *Client) buildInsertQuery(doc *types.Document) (string, []interface{}, error) {
 	var columns []string
 	var placeholders []string
 	var args []interface{}
@@ -198,6 +258,27 @@ func (c *Client) buildInsertQuery(doc *types.Document) (string, []interface{}) {
 		argIndex++
 	}
 
+	if c.config.ColumnAuthor != "" {
+		columns = append(columns, pgx.Identifier{c.config.ColumnAuthor}.Sanitize())
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+		args = append(args, nullableString(doc.Author))
+		argIndex++
+	}
+
+	if c.config.ColumnCommit != "" {
+		columns = append(columns, pgx.Identifier{c.config.ColumnCommit}.Sanitize())
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+		args = append(args, nullableString(doc.Commit))
+		argIndex++
+	}
+
+	if c.config.ColumnGitRef != "" {
+		columns = append(columns, pgx.Identifier{c.config.ColumnGitRef}.Sanitize())
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+		args = append(args, nullableString(doc.GitRef))
+		argIndex++
+	}
+
 	if c.config.ColumnRowCreated != "" {
 		columns = append(columns, pgx.Identifier{c.config.ColumnRowCreated}.Sanitize())
 		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
@@ -212,11 +293,50 @@ func (c *Client) buildInsertQuery(doc *types.Document) (string, []interface{}) {
 		argIndex++
 	}
 
-	// Add custom metadata columns
-	for colName, colValue := range c.config.CustomColumns {
-		columns = append(columns, pgx.Identifier{colName}.Sanitize())
+	if c.config.ColumnRunID != "" && c.run != nil {
+		columns = append(columns, pgx.Identifier{c.config.ColumnRunID}.Sanitize())
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+		args = append(args, c.run.ID)
+		argIndex++
+	}
+
+	// Add front matter columns
+	if c.config.ColumnMetadata != "" {
+		value, err := metadataValue(doc)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, pgx.Identifier{c.config.ColumnMetadata}.Sanitize())
+		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	for _, fm := range c.frontMatterColumns() {
+		value, err := frontMatterValue(doc.Metadata[fm.key])
+		if err != nil {
+			return "", nil, fmt.Errorf("front matter key %s: %w", fm.key, err)
+		}
+		columns = append(columns, pgx.Identifier{fm.column}.Sanitize())
 		placeholders = append(placeholders, fmt.Sprintf("$%d", argIndex))
-		args = append(args, colValue)
+		args = append(args, value)
+		argIndex++
+	}
+
+	// Add custom metadata columns
+	custom, err := c.customColumns(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	data := c.templateData(doc)
+	for i := range custom {
+		value, err := custom[i].value(&data)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, pgx.Identifier{custom[i].name}.Sanitize())
+		placeholders = append(placeholders, custom[i].placeholder(argIndex))
+		args = append(args, value)
 		argIndex++
 	}
 
@@ -225,11 +345,11 @@ func (c *Client) buildInsertQuery(doc *types.Document) (string, []interface{}) {
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "))
 
-	return query, args
+	return query, args, nil
 }
 
 // buildUpdateQuery builds an UPDATE query
-func (c *Client) buildUpdateQuery(doc *types.Document) (string, []interface{}) {
+func (c *Client) buildUpdateQuery(doc *types.Document) (string, []interface{}, error) {
 	var setClauses []string
 	var args []interface{}
 	argIndex := 1
@@ -263,6 +383,27 @@ func (c *Client) buildUpdateQuery(doc *types.Document) (string, []interface{}) {
 		argIndex++
 	}
 
+	if c.config.ColumnFileCreated != "" && doc.FileCreated != nil {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
+			pgx.Identifier{c.config.ColumnFileCreated}.Sanitize(), argIndex))
+		args = append(args, doc.FileCreated)
+		argIndex++
+	}
+
+	if c.config.ColumnAuthor != "" {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
+			pgx.Identifier{c.config.ColumnAuthor}.Sanitize(), argIndex))
+		args = append(args, nullableString(doc.Author))
+		argIndex++
+	}
+
+	if c.config.ColumnCommit != "" {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
+			pgx.Identifier{c.config.ColumnCommit}.Sanitize(), argIndex))
+		args = append(args, nullableString(doc.Commit))
+		argIndex++
+	}
+
 	if c.config.ColumnRowUpdated != "" {
 		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
 			pgx.Identifier{c.config.ColumnRowUpdated}.Sanitize(), argIndex))
@@ -270,22 +411,140 @@ func (c *Client) buildUpdateQuery(doc *types.Document) (string, []interface{}) {
 		argIndex++
 	}
 
-	// Add custom metadata columns
-	for colName, colValue := range c.config.CustomColumns {
+	if c.config.ColumnRunID != "" && c.run != nil {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
+			pgx.Identifier{c.config.ColumnRunID}.Sanitize(), argIndex))
+		args = append(args, c.run.ID)
+		argIndex++
+	}
+
+	// Add front matter columns
+	if c.config.ColumnMetadata != "" {
+		value, err := metadataValue(doc)
+		if err != nil {
+			return "", nil, err
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
+			pgx.Identifier{c.config.ColumnMetadata}.Sanitize(), argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	for _, fm := range c.frontMatterColumns() {
+		value, err := frontMatterValue(doc.Metadata[fm.key])
+		if err != nil {
+			return "", nil, fmt.Errorf("front matter key %s: %w", fm.key, err)
+		}
 		setClauses = append(setClauses, fmt.Sprintf("%s = $%d",
-			pgx.Identifier{colName}.Sanitize(), argIndex))
-		args = append(args, colValue)
+			pgx.Identifier{fm.column}.Sanitize(), argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	// Add custom metadata columns
+	custom, err := c.customColumns(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	data := c.templateData(doc)
+	for i := range custom {
+		value, err := custom[i].value(&data)
+		if err != nil {
+			return "", nil, err
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s",
+			pgx.Identifier{custom[i].name}.Sanitize(), custom[i].placeholder(argIndex)))
+		args = append(args, value)
 		argIndex++
 	}
 
 	// Add WHERE clause
-	args = append(args, doc.FileName)
+	match, matchArgs := c.documentMatch("", doc.FileName, doc.GitRef, argIndex)
+	args = append(args, matchArgs...)
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
 		pgx.Identifier{c.config.DBTable}.Sanitize(),
 		strings.Join(setClauses, ", "),
-		pgx.Identifier{c.config.ColumnFileName}.Sanitize(),
-		argIndex)
+		match)
+
+	return query, args, nil
+}
+
+// documentMatch returns the condition matching the row of a document, with
+// placeholders numbered from argIndex, and its arguments. Rows are matched
+// by file name and, if the Git ref column is mapped, by ref, so that
+// several versions of a document can be stored in the same table.
+func (c *Client) documentMatch(alias, fileName, ref string, argIndex int) (string, []interface{}) {
+	match := fmt.Sprintf("%s%s = $%d", alias,
+		pgx.Identifier{c.config.ColumnFileName}.Sanitize(), argIndex)
+	args := []interface{}{fileName}
+
+	if c.config.ColumnGitRef != "" {
+		match += fmt.Sprintf(" AND %s%s IS NOT DISTINCT FROM $%d", alias,
+			pgx.Identifier{c.config.ColumnGitRef}.Sanitize(), argIndex+1)
+		args = append(args, nullableString(ref))
+	}
+
+	return match, args
+}
+
+// WriteRunLog records the run in the run log table, if one is configured,
+// with the run's error if it failed. It is written outside the document
+// transaction, so that failed runs are recorded as well.
+func (c *Client) WriteRunLog(ctx context.Context, stats *types.Stats, runErr error) error {
+	if c.config.RunLogTable == "" || c.run == nil {
+		return nil
+	}
+
+	query, args := c.buildRunLogQuery(stats, runErr, time.Now())
+
+	if _, err := c.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to write run log: %w", err)
+	}
+
+	return nil
+}
+
+// buildRunLogQuery builds the INSERT query for the run log table. A run
+// that loaded its documents but failed on some files is recorded as
+// partial rather than successful.
+func (c *Client) buildRunLogQuery(stats *types.Stats, runErr error, finishedAt time.Time) (string, []interface{}) {
+	status := "success"
+	errMsgs := make([]string, 0, len(stats.Errors)+1)
+	for _, err := range stats.Errors {
+		errMsgs = append(errMsgs, err.Error())
+	}
+	if stats.HasErrors() {
+		status = "partial"
+	}
+	if runErr != nil {
+		status = "failed"
+		errMsgs = append(errMsgs, runErr.Error())
+	}
+
+	columns := []string{
+		"run_id", "started_at", "finished_at", "status",
+		"sources", "git_url", "git_ref", "git_commit",
+		"files_processed", "files_skipped", "rows_inserted", "rows_updated",
+		"errors", "tool_version", "tool_commit",
+	}
+	args := []interface{}{
+		c.run.ID, c.run.StartedAt, finishedAt, status,
+		c.run.Sources, c.run.GitURL, c.run.GitRef, c.run.GitCommit,
+		stats.FilesProcessed, stats.FilesSkipped, stats.FilesInserted, stats.FilesUpdated,
+		errMsgs, c.run.Version, c.run.Commit,
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		columns[i] = pgx.Identifier{columns[i]}.Sanitize()
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		pgx.Identifier{c.config.RunLogTable}.Sanitize(),
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "))
 
 	return query, args
 }
@@ -10,6 +10,8 @@
 package database
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -130,7 +132,10 @@ func TestBuildInsertQuery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := &Client{config: tt.config}
-			query, args := client.buildInsertQuery(doc)
+			query, args, err := client.buildInsertQuery(doc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
 			// Check that query contains INSERT
 			if len(query) == 0 {
@@ -194,7 +199,10 @@ func TestBuildUpdateQuery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := &Client{config: tt.config}
-			query, args := client.buildUpdateQuery(doc)
+			query, args, err := client.buildUpdateQuery(doc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
 			// Check that query contains UPDATE
 			if len(query) == 0 {
@@ -214,3 +222,132 @@ func TestBuildUpdateQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestRunIDColumn(t *testing.T) {
+	doc := &types.Document{
+		Title:    "Test Title",
+		FileName: "test.md",
+	}
+
+	cfg := &types.Config{
+		DBTable:        "documents",
+		ColumnDocTitle: "title",
+		ColumnFileName: "filename",
+		ColumnRunID:    "run_id",
+	}
+
+	// Without a run, the run ID column is not written
+	client := &Client{config: cfg}
+	query, _, _ := client.buildInsertQuery(doc)
+	if strings.Contains(query, `"run_id"`) {
+		t.Errorf("expected no run_id column without a run, got: %s", query)
+	}
+
+	run := types.NewRunInfo()
+	client.SetRunInfo(run)
+
+	query, args, _ := client.buildInsertQuery(doc)
+	if !strings.Contains(query, `"run_id"`) {
+		t.Errorf("expected run_id column in insert, got: %s", query)
+	}
+	if args[len(args)-1] != run.ID {
+		t.Errorf("expected last insert arg to be run ID, got %v", args[len(args)-1])
+	}
+
+	query, args, _ = client.buildUpdateQuery(doc)
+	if !strings.Contains(query, `"run_id" = $2`) {
+		t.Errorf("expected run_id in update SET clause, got: %s", query)
+	}
+	if args[1] != run.ID {
+		t.Errorf("expected run ID arg, got %v", args[1])
+	}
+}
+
+func TestAuthorCommitColumns(t *testing.T) {
+	cfg := &types.Config{
+		DBTable:        "documents",
+		ColumnFileName: "filename",
+		ColumnAuthor:   "author",
+		ColumnCommit:   "commit_sha",
+	}
+	client := &Client{config: cfg}
+
+	doc := &types.Document{
+		FileName: "test.md",
+		Author:   "Alice",
+		Commit:   "abc123",
+	}
+
+	query, args, _ := client.buildInsertQuery(doc)
+	if !strings.Contains(query, `"author", "commit_sha"`) {
+		t.Errorf("expected author and commit columns in insert, got: %s", query)
+	}
+	if args[1] != "Alice" || args[2] != "abc123" {
+		t.Errorf("expected author and commit args, got %v", args)
+	}
+
+	query, args, _ = client.buildUpdateQuery(doc)
+	if !strings.Contains(query, `"author" = $1, "commit_sha" = $2`) {
+		t.Errorf("expected author and commit in update SET clause, got: %s", query)
+	}
+	if args[0] != "Alice" || args[1] != "abc123" {
+		t.Errorf("expected author and commit args, got %v", args)
+	}
+
+	// Unknown values are stored as NULL
+	_, args, _ = client.buildInsertQuery(&types.Document{FileName: "local.md"})
+	if args[1] != nil || args[2] != nil {
+		t.Errorf("expected NULL author and commit, got %v", args)
+	}
+}
+
+func TestBuildRunLogQuery(t *testing.T) {
+	run := types.NewRunInfo()
+	run.Sources = []string{"docs"}
+	run.Version = "1.2.3"
+
+	client := &Client{
+		config: &types.Config{RunLogTable: "docloader_runs"},
+		run:    run,
+	}
+
+	stats := &types.Stats{FilesProcessed: 3, FilesSkipped: 1, FilesInserted: 2, FilesUpdated: 1}
+
+	finished := time.Now()
+	query, args := client.buildRunLogQuery(stats, nil, finished)
+
+	if !strings.HasPrefix(query, `INSERT INTO "docloader_runs" ("run_id", "started_at"`) {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(args) != 15 {
+		t.Fatalf("expected 15 args, got %d", len(args))
+	}
+	if args[0] != run.ID || args[2] != finished || args[3] != "success" {
+		t.Errorf("unexpected run args: %v", args[:4])
+	}
+	if args[8] != 3 || args[9] != 1 || args[10] != 2 || args[11] != 1 {
+		t.Errorf("unexpected count args: %v", args[8:12])
+	}
+	if errs := args[12].([]string); len(errs) != 0 {
+		t.Errorf("expected no error messages, got %v", errs)
+	}
+
+	// Files that failed to load make the run partial
+	stats.AddError(errors.New("file bad.md: broken"))
+	_, args = client.buildRunLogQuery(stats, nil, finished)
+	if args[3] != "partial" {
+		t.Errorf("expected partial status, got %v", args[3])
+	}
+	if errs := args[12].([]string); len(errs) != 1 {
+		t.Errorf("expected 1 error message, got %v", errs)
+	}
+
+	// A failed run records the failure as well
+	_, args = client.buildRunLogQuery(stats, errors.New("commit failed"), finished)
+	if args[3] != "failed" {
+		t.Errorf("expected failed status, got %v", args[3])
+	}
+	if errs := args[12].([]string); len(errs) != 2 || errs[1] != "commit failed" {
+		t.Errorf("expected run error to be recorded, got %v", errs)
+	}
+}
@@ -0,0 +1,304 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// HistoryVersion describes a previous version of a document held in the
+// history table
+type HistoryVersion struct {
+	Version   int
+	Title     string
+	ValidFrom *time.Time
+	ValidTo   time.Time
+}
+
+// historyColumns returns the mapped document columns that are copied into
+// the history table, in a stable order
+func (c *Client) historyColumns() []string {
+	var columns []string
+
+	for _, col := range []string{
+		c.config.ColumnDocTitle,
+		c.config.ColumnDocContent,
+		c.config.ColumnSourceContent,
+		c.config.ColumnFileName,
+		c.config.ColumnFileCreated,
+		c.config.ColumnFileModified,
+		c.config.ColumnAuthor,
+		c.config.ColumnCommit,
+		c.config.ColumnGitRef,
+		c.config.ColumnRunID,
+		c.config.ColumnMetadata,
+	} {
+		if col != "" {
+			columns = append(columns, col)
+		}
+	}
+
+	for _, fm := range c.frontMatterColumns() {
+		columns = append(columns, fm.column)
+	}
+
+	return append(columns, c.customColumnNames()...)
+}
+
+// historyValidFrom returns the SQL expression for the time the current
+// version of a row became valid: the row update or creation timestamp if
+// mapped, otherwise the end of the previous history entry matching match
+// (a documentMatch condition on the alias h.)
+func (c *Client) historyValidFrom(match string) string {
+	var candidates []string
+
+	if c.config.ColumnRowUpdated != "" {
+		candidates = append(candidates, "t."+pgx.Identifier{c.config.ColumnRowUpdated}.Sanitize())
+	}
+	if c.config.ColumnRowCreated != "" {
+		candidates = append(candidates, "t."+pgx.Identifier{c.config.ColumnRowCreated}.Sanitize())
+	}
+
+	candidates = append(candidates, fmt.Sprintf("(SELECT max(h.valid_to) FROM %s h WHERE %s)",
+		pgx.Identifier{c.config.HistoryTable}.Sanitize(),
+		match))
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return "COALESCE(" + strings.Join(candidates, ", ") + ")"
+}
+
+// buildHistoryQuery builds the query that copies the current version of a
+// document into the history table. Unless force is set, the copy is only
+// made when the new content differs from the stored content.
+func (c *Client) buildHistoryQuery(doc *types.Document, force bool) (string, []interface{}) {
+	columns := c.historyColumns()
+
+	insertCols := make([]string, 0, len(columns)+2)
+	selectCols := make([]string, 0, len(columns)+2)
+	for _, col := range columns {
+		insertCols = append(insertCols, pgx.Identifier{col}.Sanitize())
+		selectCols = append(selectCols, "t."+pgx.Identifier{col}.Sanitize())
+	}
+	// Both conditions use the same placeholders
+	where, args := c.documentMatch("t.", doc.FileName, doc.GitRef, 1)
+	historyMatch, _ := c.documentMatch("h.", doc.FileName, doc.GitRef, 1)
+
+	insertCols = append(insertCols, "valid_from", "valid_to")
+	selectCols = append(selectCols, c.historyValidFrom(historyMatch), "now()")
+
+	if !force {
+		var changed []string
+		if c.config.ColumnDocContent != "" {
+			args = append(args, doc.Content)
+			changed = append(changed, fmt.Sprintf("t.%s IS DISTINCT FROM $%d",
+				pgx.Identifier{c.config.ColumnDocContent}.Sanitize(), len(args)))
+		}
+		if c.config.ColumnSourceContent != "" {
+			args = append(args, doc.SourceContent)
+			changed = append(changed, fmt.Sprintf("t.%s IS DISTINCT FROM $%d",
+				pgx.Identifier{c.config.ColumnSourceContent}.Sanitize(), len(args)))
+		}
+		if len(changed) > 0 {
+			where += " AND (" + strings.Join(changed, " OR ") + ")"
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s t WHERE %s",
+		pgx.Identifier{c.config.HistoryTable}.Sanitize(),
+		strings.Join(insertCols, ", "),
+		strings.Join(selectCols, ", "),
+		pgx.Identifier{c.config.DBTable}.Sanitize(),
+		where)
+
+	return query, args
+}
+
+// archiveDocument copies the current version of a document into the
+// history table before it is overwritten
+func (c *Client) archiveDocument(ctx context.Context, tx pgx.Tx, doc *types.Document, force bool) error {
+	query, args := c.buildHistoryQuery(doc, force)
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to write document history: %w", err)
+	}
+
+	return nil
+}
+
+// ListHistory returns the previous versions of a document at the given Git
+// ref (if the ref column is mapped), most recent first
+func (c *Client) ListHistory(ctx context.Context, fileName, ref string) ([]HistoryVersion, error) {
+	titleExpr := "NULL::text"
+	if c.config.ColumnDocTitle != "" {
+		titleExpr = pgx.Identifier{c.config.ColumnDocTitle}.Sanitize()
+	}
+
+	where, args := c.documentMatch("", fileName, ref, 1)
+
+	query := fmt.Sprintf("SELECT %s, valid_from, valid_to FROM %s WHERE %s ORDER BY valid_to DESC",
+		titleExpr,
+		pgx.Identifier{c.config.HistoryTable}.Sanitize(),
+		where)
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document history: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []HistoryVersion
+	for rows.Next() {
+		var title *string
+		v := HistoryVersion{Version: len(versions) + 1}
+		if err := rows.Scan(&title, &v.ValidFrom, &v.ValidTo); err != nil {
+			return nil, fmt.Errorf("failed to read document history: %w", err)
+		}
+		if title != nil {
+			v.Title = *title
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read document history: %w", err)
+	}
+
+	return versions, nil
+}
+
+// buildRestoreQuery builds the query that overwrites a document at the
+// given Git ref with the given version (1 being the most recent) from the
+// history table
+func (c *Client) buildRestoreQuery(fileName, ref string, version int) (string, []interface{}) {
+	var setClauses []string
+	for _, col := range c.historyColumns() {
+		if col == c.config.ColumnFileName {
+			continue
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = h.%s",
+			pgx.Identifier{col}.Sanitize(), pgx.Identifier{col}.Sanitize()))
+	}
+	if c.config.ColumnRowUpdated != "" {
+		setClauses = append(setClauses, fmt.Sprintf("%s = now()",
+			pgx.Identifier{c.config.ColumnRowUpdated}.Sanitize()))
+	}
+
+	// The history and document rows are matched with the same placeholders
+	historyMatch, args := c.documentMatch("", fileName, ref, 1)
+	docMatch, _ := c.documentMatch("t.", fileName, ref, 1)
+	args = append(args, version-1)
+
+	query := fmt.Sprintf("UPDATE %s t SET %s FROM (SELECT * FROM %s WHERE %s ORDER BY valid_to DESC OFFSET $%d LIMIT 1) h WHERE %s",
+		pgx.Identifier{c.config.DBTable}.Sanitize(),
+		strings.Join(setClauses, ", "),
+		pgx.Identifier{c.config.HistoryTable}.Sanitize(),
+		historyMatch,
+		len(args),
+		docMatch)
+
+	return query, args
+}
+
+// buildRestoreInsertQuery builds the query that inserts the given version
+// (numbered from 1 as the offset allows) of a document at the given Git
+// ref from the history table, for a document whose row has been deleted
+func (c *Client) buildRestoreInsertQuery(fileName, ref string, version int) (string, []interface{}) {
+	var insertCols, selectCols []string
+	for _, col := range c.historyColumns() {
+		insertCols = append(insertCols, pgx.Identifier{col}.Sanitize())
+		selectCols = append(selectCols, "h."+pgx.Identifier{col}.Sanitize())
+	}
+	for _, col := range []string{c.config.ColumnRowCreated, c.config.ColumnRowUpdated} {
+		if col != "" {
+			insertCols = append(insertCols, pgx.Identifier{col}.Sanitize())
+			selectCols = append(selectCols, "now()")
+		}
+	}
+
+	where, args := c.documentMatch("", fileName, ref, 1)
+	args = append(args, version-1)
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM (SELECT * FROM %s WHERE %s ORDER BY valid_to DESC OFFSET $%d LIMIT 1) h",
+		pgx.Identifier{c.config.DBTable}.Sanitize(),
+		strings.Join(insertCols, ", "),
+		strings.Join(selectCols, ", "),
+		pgx.Identifier{c.config.HistoryTable}.Sanitize(),
+		where,
+		len(args))
+
+	return query, args
+}
+
+// restoreVersion returns the position in the history of the version to
+// restore once the current version has been archived: archiving a row
+// adds a new most recent entry, so the requested version is then one
+// further back
+func restoreVersion(version int, archived bool) int {
+	if archived {
+		return version + 1
+	}
+	return version
+}
+
+// RestoreHistory restores a previous version of a document at the given
+// Git ref (if the ref column is mapped). The version being replaced is
+// itself archived first, so a restore can be undone. A document whose row
+// has been deleted is inserted again.
+func (c *Client) RestoreHistory(ctx context.Context, fileName, ref string, version int) error {
+	if version < 1 {
+		return fmt.Errorf("invalid version %d: versions are numbered from 1", version)
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx) //nolint:errcheck // Rollback on defer is safe to ignore
+	}()
+
+	query, args := c.buildHistoryQuery(&types.Document{FileName: fileName, GitRef: ref}, true)
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to write document history: %w", err)
+	}
+
+	// Without a current row, nothing was archived and there is no row to
+	// update
+	archived := tag.RowsAffected() > 0
+	if archived {
+		query, args = c.buildRestoreQuery(fileName, ref, restoreVersion(version, archived))
+	} else {
+		query, args = c.buildRestoreInsertQuery(fileName, ref, restoreVersion(version, archived))
+	}
+
+	tag, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to restore document: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("version %d of %s not found", version, fileName)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func historyTestConfig() *types.Config {
+	return &types.Config{
+		DBTable:          "documents",
+		HistoryTable:     "documents_history",
+		ColumnDocTitle:   "title",
+		ColumnDocContent: "content",
+		ColumnFileName:   "filename",
+		ColumnRowUpdated: "updated_at",
+		CustomColumns: map[string]string{
+			"version": "v9.9",
+			"product": "pgAdmin 4",
+		},
+	}
+}
+
+func TestHistoryColumns(t *testing.T) {
+	client := &Client{config: historyTestConfig()}
+
+	columns := client.historyColumns()
+	expected := []string{"title", "content", "filename", "product", "version"}
+
+	if strings.Join(columns, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected columns %v, got %v", expected, columns)
+	}
+}
+
+func TestBuildHistoryQuery(t *testing.T) {
+	client := &Client{config: historyTestConfig()}
+	doc := &types.Document{
+		Title:    "Test Title",
+		Content:  "New content",
+		FileName: "test.md",
+	}
+
+	query, args := client.buildHistoryQuery(doc, false)
+
+	expectedPrefix := `INSERT INTO "documents_history" ("title", "content", "filename", "product", "version", valid_from, valid_to) SELECT t."title"`
+	if !strings.HasPrefix(query, expectedPrefix) {
+		t.Errorf("unexpected query prefix:\n%s", query)
+	}
+
+	expectedValidFrom := `COALESCE(t."updated_at", (SELECT max(h.valid_to) FROM "documents_history" h WHERE h."filename" = $1))`
+	if !strings.Contains(query, expectedValidFrom) {
+		t.Errorf("expected valid_from expression %s in query:\n%s", expectedValidFrom, query)
+	}
+
+	if !strings.HasSuffix(query, `WHERE t."filename" = $1 AND (t."content" IS DISTINCT FROM $2)`) {
+		t.Errorf("expected change check in WHERE clause:\n%s", query)
+	}
+
+	if len(args) != 2 || args[0] != "test.md" || args[1] != "New content" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// Forced archiving copies the row regardless of content
+	query, args = client.buildHistoryQuery(doc, true)
+	if strings.Contains(query, "IS DISTINCT FROM") {
+		t.Errorf("expected no change check when forced:\n%s", query)
+	}
+	if len(args) != 1 {
+		t.Errorf("expected 1 arg when forced, got %d", len(args))
+	}
+}
+
+func TestBuildRestoreQuery(t *testing.T) {
+	client := &Client{config: historyTestConfig()}
+
+	query, args := client.buildRestoreQuery("test.md", "", 2)
+
+	if !strings.HasPrefix(query, `UPDATE "documents" t SET "title" = h."title", "content" = h."content", "product" = h."product", "version" = h."version", "updated_at" = now()`) {
+		t.Errorf("unexpected SET clause:\n%s", query)
+	}
+
+	if strings.Contains(query, `"filename" = h."filename",`) {
+		t.Errorf("filename should not be overwritten:\n%s", query)
+	}
+
+	if len(args) != 2 || args[0] != "test.md" || args[1] != 1 {
+		t.Errorf("expected args [test.md 1], got %v", args)
+	}
+}
+
+func TestHistoryQueriesWithGitRef(t *testing.T) {
+	cfg := historyTestConfig()
+	cfg.ColumnGitRef = "git_ref"
+	client := &Client{config: cfg}
+
+	// Archiving matches the document and its previous versions by ref
+	query, args := client.buildHistoryQuery(&types.Document{FileName: "test.md", GitRef: "v16"}, true)
+	if !strings.Contains(query, `WHERE h."filename" = $1 AND h."git_ref" IS NOT DISTINCT FROM $2)`) {
+		t.Errorf("expected valid_from to match by ref:\n%s", query)
+	}
+	if !strings.HasSuffix(query, `WHERE t."filename" = $1 AND t."git_ref" IS NOT DISTINCT FROM $2`) {
+		t.Errorf("expected document to match by ref:\n%s", query)
+	}
+	if len(args) != 2 || args[1] != "v16" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	query, args = client.buildRestoreQuery("test.md", "v16", 2)
+	if !strings.HasSuffix(query, `FROM (SELECT * FROM "documents_history" WHERE "filename" = $1 AND "git_ref" IS NOT DISTINCT FROM $2 ORDER BY valid_to DESC OFFSET $3 LIMIT 1) h WHERE t."filename" = $1 AND t."git_ref" IS NOT DISTINCT FROM $2`) {
+		t.Errorf("expected restore to match by ref:\n%s", query)
+	}
+	if len(args) != 3 || args[0] != "test.md" || args[1] != "v16" || args[2] != 1 {
+		t.Errorf("expected args [test.md v16 1], got %v", args)
+	}
+
+	query, args = client.buildRestoreInsertQuery("test.md", "v16", 1)
+	if !strings.HasSuffix(query, `FROM (SELECT * FROM "documents_history" WHERE "filename" = $1 AND "git_ref" IS NOT DISTINCT FROM $2 ORDER BY valid_to DESC OFFSET $3 LIMIT 1) h`) {
+		t.Errorf("expected restore insert to match by ref:\n%s", query)
+	}
+	if len(args) != 3 || args[0] != "test.md" || args[1] != "v16" || args[2] != 0 {
+		t.Errorf("expected args [test.md v16 0], got %v", args)
+	}
+}
+
+func TestBuildRestoreInsertQuery(t *testing.T) {
+	client := &Client{config: historyTestConfig()}
+
+	query, args := client.buildRestoreInsertQuery("test.md", "", 1)
+
+	if !strings.HasPrefix(query, `INSERT INTO "documents" ("title", "content", "filename", "product", "version", "updated_at") SELECT h."title", h."content", h."filename", h."product", h."version", now() FROM (SELECT * FROM "documents_history" WHERE "filename" = $1`) {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+	if len(args) != 2 || args[0] != "test.md" || args[1] != 0 {
+		t.Errorf("expected args [test.md 0], got %v", args)
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	// Archiving the current row pushes the requested version one back
+	if got := restoreVersion(1, true); got != 2 {
+		t.Errorf("expected version 2 after archiving, got %d", got)
+	}
+
+	// A deleted row archives nothing, so the version is where it was
+	if got := restoreVersion(1, false); got != 1 {
+		t.Errorf("expected version 1 without archiving, got %d", got)
+	}
+}
@@ -0,0 +1,147 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// AddFileChanges adds rows to rename and delete, by file name, when
+// documents are next inserted
+func (c *Client) AddFileChanges(changes *types.FileChanges) {
+	c.changes = append(c.changes, changes)
+}
+
+// LastLoadedCommit returns the commit last loaded into the target table
+// from the given Git repository and ref, or an empty string if there is
+// none. The state table is used if configured, otherwise the most recent
+// successful run in the run log.
+func (c *Client) LastLoadedCommit(ctx context.Context, gitURL, gitRef string) (string, error) {
+	query, args := c.buildLastCommitQuery(gitURL, gitRef)
+
+	var commit string
+	err := c.pool.QueryRow(ctx, query, args...).Scan(&commit)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read last loaded commit: %w", err)
+	}
+
+	return commit, nil
+}
+
+// buildLastCommitQuery builds the query for the last loaded commit
+func (c *Client) buildLastCommitQuery(gitURL, gitRef string) (string, []interface{}) {
+	if c.config.StateTable != "" {
+		query := fmt.Sprintf("SELECT git_commit FROM %s WHERE target_table = $1 AND git_url = $2 AND git_ref = $3",
+			pgx.Identifier{c.config.StateTable}.Sanitize())
+		return query, []interface{}{c.config.DBTable, gitURL, gitRef}
+	}
+
+	query := fmt.Sprintf("SELECT git_commit FROM %s WHERE status = 'success' AND git_url = $1 AND git_ref = $2 "+
+		"AND git_commit <> '' ORDER BY finished_at DESC LIMIT 1",
+		pgx.Identifier{c.config.RunLogTable}.Sanitize())
+	return query, []interface{}{gitURL, gitRef}
+}
+
+// buildStateQuery builds the upsert recording the commit loaded for a ref
+// by the current run in the state table
+func (c *Client) buildStateQuery(checkout types.GitCheckout, loadedAt time.Time) (string, []interface{}) {
+	query := fmt.Sprintf("INSERT INTO %s (target_table, git_url, git_ref, git_commit, loaded_at, run_id) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) "+
+		"ON CONFLICT (target_table, git_url, git_ref) DO UPDATE SET "+
+		"git_commit = EXCLUDED.git_commit, loaded_at = EXCLUDED.loaded_at, run_id = EXCLUDED.run_id",
+		pgx.Identifier{c.config.StateTable}.Sanitize())
+
+	return query, []interface{}{
+		c.config.DBTable, checkout.URL, checkout.Ref, checkout.Commit, loadedAt, c.run.ID,
+	}
+}
+
+// recordState records the commit loaded for each ref by the current run in
+// the state table, if one is configured
+func (c *Client) recordState(ctx context.Context, tx pgx.Tx) error {
+	if c.config.StateTable == "" || c.run == nil {
+		return nil
+	}
+
+	loadedAt := time.Now()
+	for _, checkout := range c.run.Checkouts {
+		if checkout.Commit == "" {
+			continue
+		}
+		query, args := c.buildStateQuery(checkout, loadedAt)
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to record loaded commit for %s: %w", checkout.Ref, err)
+		}
+	}
+
+	return nil
+}
+
+// applyFileChanges renames and deletes rows for files renamed or deleted
+// since the last load. Deleted documents are kept in the history table if
+// one is configured.
+func (c *Client) applyFileChanges(ctx context.Context, tx pgx.Tx, stats *types.Stats) error {
+	for _, changes := range c.changes {
+		for oldName, newName := range changes.Renamed {
+			query, args := c.buildRenameQuery(oldName, newName, changes.Ref)
+			tag, err := tx.Exec(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("failed to rename document %s: %w", oldName, err)
+			}
+			stats.FilesRenamed += int(tag.RowsAffected())
+		}
+
+		for _, name := range changes.Deleted {
+			if c.config.HistoryTable != "" {
+				doc := &types.Document{FileName: name, GitRef: changes.Ref}
+				if err := c.archiveDocument(ctx, tx, doc, true); err != nil {
+					return err
+				}
+			}
+
+			query, args := c.buildDeleteQuery(name, changes.Ref)
+			tag, err := tx.Exec(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("failed to delete document %s: %w", name, err)
+			}
+			stats.FilesDeleted += int(tag.RowsAffected())
+		}
+	}
+
+	return nil
+}
+
+// buildRenameQuery builds the UPDATE query renaming a document row
+func (c *Client) buildRenameQuery(oldName, newName, ref string) (string, []interface{}) {
+	match, args := c.documentMatch("", oldName, ref, 2)
+	query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s",
+		pgx.Identifier{c.config.DBTable}.Sanitize(),
+		pgx.Identifier{c.config.ColumnFileName}.Sanitize(),
+		match)
+	return query, append([]interface{}{newName}, args...)
+}
+
+// buildDeleteQuery builds the DELETE query removing a document row
+func (c *Client) buildDeleteQuery(name, ref string) (string, []interface{}) {
+	match, args := c.documentMatch("", name, ref, 1)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s",
+		pgx.Identifier{c.config.DBTable}.Sanitize(), match)
+	return query, args
+}
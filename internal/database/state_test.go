@@ -0,0 +1,141 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestBuildLastCommitQuery(t *testing.T) {
+	cfg := &types.Config{
+		DBTable:     "documents",
+		RunLogTable: "docloader_runs",
+	}
+	client := &Client{config: cfg}
+
+	// Without a state table, the run log is used
+	query, args := client.buildLastCommitQuery("https://example.com/docs.git", "main")
+	if !strings.HasPrefix(query, `SELECT git_commit FROM "docloader_runs" WHERE status = 'success'`) {
+		t.Errorf("unexpected run log query: %s", query)
+	}
+	if !strings.HasSuffix(query, "ORDER BY finished_at DESC LIMIT 1") {
+		t.Errorf("expected most recent run, got: %s", query)
+	}
+	if len(args) != 2 || args[0] != "https://example.com/docs.git" || args[1] != "main" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// The state table takes precedence
+	cfg.StateTable = "docloader_state"
+	query, args = client.buildLastCommitQuery("https://example.com/docs.git", "main")
+	expected := `SELECT git_commit FROM "docloader_state" WHERE target_table = $1 AND git_url = $2 AND git_ref = $3`
+	if query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 3 || args[0] != "documents" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestBuildStateQuery(t *testing.T) {
+	client := &Client{config: &types.Config{
+		DBTable:    "documents",
+		StateTable: "docloader_state",
+	}}
+	run := types.NewRunInfo()
+	client.SetRunInfo(run)
+
+	loadedAt := time.Now()
+	checkout := types.GitCheckout{URL: "https://example.com/docs.git", Ref: "main", Commit: "abc123"}
+	query, args := client.buildStateQuery(checkout, loadedAt)
+
+	if !strings.HasPrefix(query, `INSERT INTO "docloader_state" (target_table, git_url, git_ref, git_commit, loaded_at, run_id)`) {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !strings.Contains(query, "ON CONFLICT (target_table, git_url, git_ref) DO UPDATE") {
+		t.Errorf("expected upsert, got: %s", query)
+	}
+
+	expected := []interface{}{"documents", checkout.URL, "main", "abc123", loadedAt, run.ID}
+	if len(args) != len(expected) {
+		t.Fatalf("expected %d args, got %d", len(expected), len(args))
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("arg %d: expected %v, got %v", i, expected[i], args[i])
+		}
+	}
+}
+
+func TestBuildRenameAndDeleteQueries(t *testing.T) {
+	client := &Client{config: &types.Config{
+		DBTable:        "documents",
+		ColumnFileName: "filename",
+	}}
+
+	query, args := client.buildRenameQuery("docs/old.md", "docs/new.md", "")
+	if query != `UPDATE "documents" SET "filename" = $1 WHERE "filename" = $2` {
+		t.Errorf("unexpected rename query: %s", query)
+	}
+	if args[0] != "docs/new.md" || args[1] != "docs/old.md" {
+		t.Errorf("unexpected rename args: %v", args)
+	}
+
+	query, args = client.buildDeleteQuery("docs/gone.md", "")
+	if query != `DELETE FROM "documents" WHERE "filename" = $1` {
+		t.Errorf("unexpected delete query: %s", query)
+	}
+	if args[0] != "docs/gone.md" {
+		t.Errorf("unexpected delete args: %v", args)
+	}
+}
+
+func TestGitRefColumn(t *testing.T) {
+	client := &Client{config: &types.Config{
+		DBTable:          "documents",
+		ColumnDocContent: "content",
+		ColumnFileName:   "filename",
+		ColumnGitRef:     "version",
+	}}
+	doc := &types.Document{Content: "Content", FileName: "docs/a.md", GitRef: "v16"}
+
+	query, args, _ := client.buildInsertQuery(doc)
+	if !strings.Contains(query, `("content", "filename", "version")`) || args[2] != "v16" {
+		t.Errorf("expected version column in insert, got: %s %v", query, args)
+	}
+
+	// Rows are matched by file name and ref
+	query, args, _ = client.buildUpdateQuery(doc)
+	expected := `UPDATE "documents" SET "content" = $1 WHERE "filename" = $2 AND "version" IS NOT DISTINCT FROM $3`
+	if query != expected {
+		t.Errorf("expected query:\n%s\ngot:\n%s", expected, query)
+	}
+	if len(args) != 3 || args[1] != "docs/a.md" || args[2] != "v16" {
+		t.Errorf("unexpected update args: %v", args)
+	}
+
+	query, args = client.buildRenameQuery("docs/a.md", "docs/b.md", "v16")
+	if query != `UPDATE "documents" SET "filename" = $1 WHERE "filename" = $2 AND "version" IS NOT DISTINCT FROM $3` {
+		t.Errorf("unexpected rename query: %s", query)
+	}
+	if args[0] != "docs/b.md" || args[1] != "docs/a.md" || args[2] != "v16" {
+		t.Errorf("unexpected rename args: %v", args)
+	}
+
+	// Documents without a ref match rows with a NULL ref
+	_, args = client.buildDeleteQuery("docs/a.md", "")
+	if args[1] != nil {
+		t.Errorf("expected NULL ref, got %v", args[1])
+	}
+}
@@ -0,0 +1,116 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// defaultTokenUsername is the username sent with a token when none is
+// configured; hosting services accept any non-empty username with a token
+const defaultTokenUsername = "x-access-token"
+
+// authEnv returns the environment for git commands that access the remote,
+// or nil if no credentials are configured. Tokens are passed to git as an
+// http.extraHeader setting in the environment (scoped to the repository's
+// host), and SSH keys through GIT_SSH_COMMAND, so that neither appears in
+// command lines or logs.
+func authEnv(cfg *types.Config) ([]string, error) {
+	if cfg.GitTokenEnv == "" && cfg.GitSSHKey == "" {
+		return nil, nil
+	}
+
+	env := os.Environ()
+	env = append(env, "GIT_TERMINAL_PROMPT=0")
+
+	if cfg.GitTokenEnv != "" {
+		username, token, u, err := tokenCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+
+		env = appendGitConfig(env,
+			fmt.Sprintf("http.%s://%s/.extraHeader", u.Scheme, u.Host),
+			"Authorization: Basic "+credentials)
+	}
+
+	if cfg.GitSSHKey != "" {
+		if _, err := os.Stat(cfg.GitSSHKey); err != nil {
+			return nil, fmt.Errorf("SSH key %s: %w", cfg.GitSSHKey, err)
+		}
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(cfg.GitSSHKey)+" -o IdentitiesOnly=yes")
+	}
+
+	return env, nil
+}
+
+// tokenCredentials returns the username and token to authenticate with,
+// read from the environment variable named by --git-token-env, and the
+// parsed repository URL they are sent to
+func tokenCredentials(cfg *types.Config) (string, string, *url.URL, error) {
+	token := os.Getenv(cfg.GitTokenEnv)
+	if token == "" {
+		return "", "", nil, fmt.Errorf("environment variable %s (from --git-token-env) is not set", cfg.GitTokenEnv)
+	}
+
+	u, err := url.Parse(cfg.GitURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", nil, fmt.Errorf("--git-token-env requires an HTTPS repository URL")
+	}
+
+	username := cfg.GitUsername
+	if username == "" {
+		username = defaultTokenUsername
+	}
+
+	return username, token, u, nil
+}
+
+// appendGitConfig adds a configuration setting to the environment of git
+// commands (GIT_CONFIG_COUNT/KEY/VALUE), after any already present
+func appendGitConfig(env []string, key, value string) []string {
+	count := 0
+	for i, entry := range env {
+		if v, ok := strings.CutPrefix(entry, "GIT_CONFIG_COUNT="); ok {
+			count, _ = strconv.Atoi(v) //nolint:errcheck // an invalid count is replaced
+			env = append(env[:i:i], env[i+1:]...)
+			break
+		}
+	}
+
+	return append(env,
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value))
+}
+
+// shellQuote quotes a value for use in a shell command line
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// RedactURL removes any credentials from a repository URL, so that it can
+// be printed or stored
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.User("***")
+	return strings.Replace(u.String(), "%2A%2A%2A@", "***@", 1)
+}
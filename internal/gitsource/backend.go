@@ -0,0 +1,173 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// Git backends (see Config.GitBackend)
+const (
+	BackendExec  = "exec"   // Run the git command (default)
+	BackendGoGit = "go-git" // Pure-Go implementation, for hosts without git
+)
+
+// backend performs the Git operations of a source on the clone (or a
+// worktree of it) at a path. GitSource decides what to clone, fetch and
+// check out; a backend only carries it out.
+type backend interface {
+	// Clone clones the repository at url to path, without checking it out
+	// if possible (see Checkout)
+	Clone(url, path string, opts cloneOptions) error
+
+	// OriginURL and SetOriginURL get and set the URL of the origin remote
+	OriginURL(path string) (string, error)
+	SetOriginURL(path, url string) error
+
+	// Fetch fetches a branch, tag or the default branch from origin and
+	// returns its commit
+	Fetch(path string, opts fetchOptions) (string, error)
+
+	// FetchCommit fetches a commit from origin, by the ref that points to
+	// it if known
+	FetchCommit(path, sha, ref string, depth int) error
+
+	// Resolve returns the commit a local revision (such as a ref) points to
+	Resolve(path, rev string) (string, error)
+
+	// HasCommit returns true if the commit is present in the clone
+	HasCommit(path, sha string) bool
+
+	// IsShallow returns true if the clone has truncated history
+	IsShallow(path string) bool
+
+	// RemoteRefs lists the branches and tags on origin and their commits,
+	// using the peeled commit of annotated tags
+	RemoteRefs(path string) (branches, tags map[string]string, err error)
+
+	// Checkout checks out a commit, discarding local modifications and
+	// untracked files
+	Checkout(path string, opts checkoutOptions) error
+
+	// PruneWorktrees forgets worktrees whose directories have been removed
+	PruneWorktrees(path string) error
+
+	// CheckoutWorktree checks out a commit (detached) in a worktree of the
+	// clone at path, adding the worktree if it does not exist
+	CheckoutWorktree(path, wtPath string, opts checkoutOptions) error
+
+	// HeadCommit returns the commit checked out
+	HeadCommit(path string) (string, error)
+
+	// FileHistory returns per-file metadata from the history of the commit
+	// checked out, for the files within dirs (slash-separated, or nil for
+	// the whole tree; see GitSource.FileMetadata)
+	FileHistory(path string, dirs []string) (map[string]*types.FileMetadata, error)
+
+	// Diff lists the files changed between a commit and the one checked out
+	Diff(path, since string) (*Changes, error)
+
+	// UpdateSubmodules recursively initializes and updates the submodules
+	// at the given paths
+	UpdateSubmodules(path string, paths []string, depth int) error
+
+	// FetchLFS downloads the Git LFS content of files under the given
+	// directories (all files if none), and in submodules if requested
+	FetchLFS(path string, include []string, submodules bool) error
+}
+
+// cloneOptions controls how a repository is cloned
+type cloneOptions struct {
+	Branch     string   // Branch to clone (default: the remote's default)
+	Tag        string   // Tag to clone, if no branch is given
+	Depth      int      // Number of commits to fetch (0 for full history)
+	SparseDirs []string // Directories to check out (all if none)
+}
+
+// fetchOptions controls what is fetched into an existing clone
+type fetchOptions struct {
+	Branch    string // Branch to fetch (default: the remote's default)
+	Tag       string // Tag to fetch, if no branch is given
+	Depth     int    // Depth to keep a shallow clone at (0 to leave as is)
+	Unshallow bool   // Fetch the full history of a shallow clone
+}
+
+// checkoutOptions controls how a commit is checked out
+type checkoutOptions struct {
+	Commit     string   // Commit to check out
+	Branch     string   // Local branch to point at the commit (detached if empty)
+	SparseDirs []string // Directories to check out (all if none)
+}
+
+// newBackend returns the backend selected in the configuration
+func newBackend(cfg *types.Config) (backend, error) {
+	switch cfg.GitBackend {
+	case "", BackendExec:
+		return newExecBackend(cfg)
+	case BackendGoGit:
+		return newGoGitBackend(cfg)
+	default:
+		return nil, fmt.Errorf("unknown Git backend '%s' (expected %s or %s)", cfg.GitBackend, BackendExec, BackendGoGit)
+	}
+}
+
+// fileHistory accumulates per-file metadata from the commits of a history,
+// newest first (see GitSource.FileMetadata)
+type fileHistory struct {
+	repoPath string
+	metadata map[string]*types.FileMetadata
+}
+
+// newFileHistory returns an empty history of the clone at repoPath
+func newFileHistory(repoPath string) *fileHistory {
+	return &fileHistory{
+		repoPath: repoPath,
+		metadata: make(map[string]*types.FileMetadata),
+	}
+}
+
+// add records that a commit changed a file (a slash-separated path within
+// the repository)
+func (h *fileHistory) add(file, commit, author string, commitTime time.Time) {
+	path := filepath.Join(h.repoPath, filepath.FromSlash(file))
+	t := commitTime
+	meta, ok := h.metadata[path]
+	if !ok {
+		// First (newest) commit seen for this file
+		modified := commitTime
+		meta = &types.FileMetadata{
+			Modified: &modified,
+			Author:   author,
+			Commit:   commit,
+		}
+		h.metadata[path] = meta
+	}
+	// Older commits keep pushing the creation time back
+	meta.Created = &t
+}
+
+// inDirs returns true if a file (a slash-separated path within the
+// repository) is within one of dirs, or dirs is nil
+func inDirs(file string, dirs []string) bool {
+	if dirs == nil {
+		return true
+	}
+	for _, dir := range dirs {
+		if file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,475 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// execBackend runs the git command
+type execBackend struct {
+	env []string // Environment for git commands
+}
+
+// newExecBackend returns a backend running git with the configured
+// credentials
+func newExecBackend(cfg *types.Config) (*execBackend, error) {
+	// Check git is available
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git command not found: please install git to use git sources, or use --git-backend %s", BackendGoGit)
+	}
+
+	env, err := authEnv(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// LFS content is only downloaded when asked for (see populate), not by
+	// the smudge filter on checkout
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append(env, "GIT_LFS_SKIP_SMUDGE=1")
+
+	return &execBackend{env: env}, nil
+}
+
+// command returns a git command with the backend's environment
+func (b *execBackend) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Env = b.env
+	return cmd
+}
+
+// run runs a git command, showing its output
+func (b *execBackend) run(args ...string) error {
+	cmd := b.command(args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Clone clones the repository. When sparse directories are given, a partial
+// clone without file contents is made and only those directories are
+// checked out (sparse checkout), so that blobs outside them are never
+// downloaded. If the local git does not support this, a full clone is made
+// instead.
+func (b *execBackend) Clone(url, path string, opts cloneOptions) error {
+	sparse := len(opts.SparseDirs) > 0
+
+	for {
+		args := []string{"clone"}
+		if opts.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(opts.Depth))
+		}
+		if sparse {
+			// Servers that don't support filters ignore them with a warning
+			args = append(args, "--filter=blob:none", "--sparse")
+		}
+
+		// Add branch/tag to clone command for efficiency
+		if opts.Branch != "" {
+			args = append(args, "--branch", opts.Branch)
+		} else if opts.Tag != "" {
+			args = append(args, "--branch", opts.Tag)
+		}
+
+		args = append(args, url, path)
+
+		err := b.run(args...)
+		if err == nil {
+			break
+		}
+		if !sparse {
+			return fmt.Errorf("git clone failed: %w", err)
+		}
+
+		fmt.Printf("Note: sparse clone failed (%v), retrying with a full clone\n", err)
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove partial clone: %w", err)
+		}
+		sparse = false
+	}
+
+	if sparse {
+		b.sparseCheckout(path, opts.SparseDirs)
+	}
+
+	return nil
+}
+
+// sparseCheckout restricts the files checked out at path to the given
+// directories (cone mode). If this fails, all files are checked out.
+func (b *execBackend) sparseCheckout(path string, dirs []string) {
+	args := append([]string{"-C", path, "sparse-checkout", "set", "--cone"}, dirs...)
+	if out, err := b.command(args...).CombinedOutput(); err != nil {
+		fmt.Printf("Note: sparse checkout failed (%v: %s), checking out all files\n",
+			err, strings.TrimSpace(string(out)))
+		_ = b.command("-C", path, "sparse-checkout", "disable").Run() //nolint:errcheck // best effort
+	}
+}
+
+// isSparse returns true if the clone has sparse checkout enabled
+func (b *execBackend) isSparse(path string) bool {
+	out, err := b.command("-C", path, "config", "--bool", "core.sparseCheckout").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// OriginURL returns the URL of the origin remote
+func (b *execBackend) OriginURL(path string) (string, error) {
+	out, err := b.command("-C", path, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetOriginURL sets the URL of the origin remote
+func (b *execBackend) SetOriginURL(path, url string) error {
+	return b.command("-C", path, "remote", "set-url", "origin", url).Run()
+}
+
+// Fetch fetches a branch, tag or the default branch from origin
+func (b *execBackend) Fetch(path string, opts fetchOptions) (string, error) {
+	args := []string{"-C", path, "fetch", "--prune"}
+	switch {
+	case opts.Unshallow:
+		args = append(args, "--unshallow")
+	case opts.Depth > 0:
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	args = append(args, "origin")
+
+	var rev string
+	switch {
+	case opts.Branch != "":
+		args = append(args, "+refs/heads/"+opts.Branch+":refs/remotes/origin/"+opts.Branch)
+		rev = "refs/remotes/origin/" + opts.Branch
+	case opts.Tag != "":
+		args = append(args, "+refs/tags/"+opts.Tag+":refs/tags/"+opts.Tag)
+		rev = "refs/tags/" + opts.Tag
+	default:
+		args = append(args, "HEAD")
+		rev = "FETCH_HEAD"
+	}
+
+	if err := b.run(args...); err != nil {
+		return "", fmt.Errorf("git fetch failed: %w", err)
+	}
+
+	return b.Resolve(path, rev)
+}
+
+// FetchCommit fetches a commit from origin by its SHA
+func (b *execBackend) FetchCommit(path, sha, _ string, depth int) error {
+	args := []string{"-C", path, "fetch", "--quiet"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	args = append(args, "origin", sha)
+	return b.command(args...).Run()
+}
+
+// Resolve returns the commit a local revision points to
+func (b *execBackend) Resolve(path, rev string) (string, error) {
+	out, err := b.command("-C", path, "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s not found in clone", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasCommit returns true if the commit object exists in the clone
+func (b *execBackend) HasCommit(path, sha string) bool {
+	return b.command("-C", path, "cat-file", "-e", sha+"^{commit}").Run() == nil
+}
+
+// IsShallow returns true if the clone has truncated history
+func (b *execBackend) IsShallow(path string) bool {
+	out, err := b.command("-C", path, "rev-parse", "--is-shallow-repository").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// RemoteRefs lists the branches and tags on origin
+func (b *execBackend) RemoteRefs(path string) (map[string]string, map[string]string, error) {
+	out, err := b.command("-C", path, "ls-remote", "--heads", "--tags", "origin").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("git ls-remote failed: %w", err)
+	}
+	branches, tags := parseRemoteRefs(out)
+	return branches, tags, nil
+}
+
+// parseRemoteRefs parses git ls-remote output into branch and tag names
+// mapped to commits, using the peeled commit of annotated tags
+func parseRemoteRefs(out []byte) (branches, tags map[string]string) {
+	branches = make(map[string]string)
+	tags = make(map[string]string)
+
+	for _, line := range strings.Split(string(out), "\n") {
+		sha, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok {
+			addRemoteRef(branches, tags, ref, sha)
+		}
+	}
+
+	return branches, tags
+}
+
+// addRemoteRef adds a remote ref to the branches or tags it belongs to. The
+// peeled commit of an annotated tag (ref^{}) replaces the tag object.
+func addRemoteRef(branches, tags map[string]string, ref, sha string) {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		branches[strings.TrimPrefix(ref, "refs/heads/")] = sha
+	case strings.HasSuffix(ref, "^{}"):
+		tags[strings.TrimSuffix(strings.TrimPrefix(ref, "refs/tags/"), "^{}")] = sha
+	case strings.HasPrefix(ref, "refs/tags/"):
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		if _, peeled := tags[name]; !peeled {
+			tags[name] = sha
+		}
+	}
+}
+
+// Checkout checks out a commit, first following any change to the sparse
+// directories of a sparse clone
+func (b *execBackend) Checkout(path string, opts checkoutOptions) error {
+	if b.isSparse(path) {
+		if len(opts.SparseDirs) > 0 {
+			b.sparseCheckout(path, opts.SparseDirs)
+		} else if err := b.command("-C", path, "sparse-checkout", "disable").Run(); err != nil {
+			return fmt.Errorf("git sparse-checkout disable failed: %w", err)
+		}
+	}
+
+	args := []string{"-C", path, "checkout", "--quiet", "--force"}
+	if opts.Branch != "" {
+		args = append(args, "-B", opts.Branch, opts.Commit)
+	} else {
+		args = append(args, "--detach", opts.Commit)
+	}
+
+	for _, args := range [][]string{args, {"-C", path, "clean", "--quiet", "-ffdx"}} {
+		if err := b.run(args...); err != nil {
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PruneWorktrees forgets worktrees whose directories have been removed
+func (b *execBackend) PruneWorktrees(path string) error {
+	if err := b.command("-C", path, "worktree", "prune").Run(); err != nil {
+		return fmt.Errorf("git worktree prune failed: %w", err)
+	}
+	return nil
+}
+
+// CheckoutWorktree checks out a commit in a worktree, adding the worktree
+// if it does not exist. In a sparse clone, the worktree is populated only
+// after its sparse checkout patterns are set.
+func (b *execBackend) CheckoutWorktree(path, wtPath string, opts checkoutOptions) error {
+	var commands [][]string
+	if _, err := os.Stat(filepath.Join(wtPath, ".git")); err == nil {
+		commands = append(commands,
+			[]string{"-C", wtPath, "checkout", "--quiet", "--force", "--detach", opts.Commit},
+			[]string{"-C", wtPath, "clean", "--quiet", "-ffdx"})
+	} else if b.isSparse(path) {
+		commands = append(commands,
+			[]string{"-C", path, "worktree", "add", "--quiet", "--force", "--no-checkout", "--detach", wtPath, opts.Commit},
+			append([]string{"-C", wtPath, "sparse-checkout", "set", "--cone"}, opts.SparseDirs...),
+			[]string{"-C", wtPath, "reset", "--quiet", "--hard"})
+	} else {
+		commands = append(commands, []string{"-C", path, "worktree", "add", "--quiet", "--force", "--detach", wtPath, opts.Commit})
+	}
+
+	for _, args := range commands {
+		if err := b.run(args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HeadCommit returns the SHA of the commit currently checked out
+func (b *execBackend) HeadCommit(path string) (string, error) {
+	out, err := b.command("-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// FileHistory reads the history of dirs in a single git log pass
+func (b *execBackend) FileHistory(path string, dirs []string) (map[string]*types.FileMetadata, error) {
+	args := []string{"-C", path, "-c", "core.quotePath=false",
+		"log", "--no-renames", "--name-only", "--format=%x1e%H%x1f%at%x1f%an", "--"}
+	out, err := b.command(append(args, dirs...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	return parseFileHistory(out, path)
+}
+
+// parseFileHistory parses git log output (newest commit first) into
+// per-file metadata keyed by path under repoPath
+func parseFileHistory(out []byte, repoPath string) (map[string]*types.FileMetadata, error) {
+	history := newFileHistory(repoPath)
+
+	var commit, author string
+	var commitTime time.Time
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Commit header: \x1e<sha>\x1f<unix time>\x1f<author>
+		if strings.HasPrefix(line, "\x1e") {
+			fields := strings.SplitN(strings.TrimPrefix(line, "\x1e"), "\x1f", 3)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("unexpected git log output: %q", line)
+			}
+			secs, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected commit time %q: %w", fields[1], err)
+			}
+			commit, commitTime, author = fields[0], time.Unix(secs, 0).UTC(), fields[2]
+			continue
+		}
+
+		history.add(line, commit, author, commitTime)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git log output: %w", err)
+	}
+
+	return history.metadata, nil
+}
+
+// Diff lists the files changed between a commit and HEAD, detecting renames
+func (b *execBackend) Diff(path, since string) (*Changes, error) {
+	cmd := b.command("-C", path, "-c", "core.quotePath=false",
+		"diff", "--name-status", "-z", "-M", since, "HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	return parseNameStatus(out, path)
+}
+
+// parseNameStatus parses NUL-separated git diff --name-status output into
+// changes keyed by path under repoPath
+func parseNameStatus(out []byte, repoPath string) (*Changes, error) {
+	changes := &Changes{Renamed: make(map[string]string)}
+	if len(out) == 0 {
+		return changes, nil
+	}
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+
+	path := func(p string) string {
+		return filepath.Join(repoPath, filepath.FromSlash(p))
+	}
+
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			return nil, fmt.Errorf("unexpected git diff output: empty status")
+		}
+
+		switch status[0] {
+		case 'R', 'C':
+			// Renames and copies are followed by the old and new paths
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff output: missing paths for %s", status)
+			}
+			oldPath, newPath := path(fields[i+1]), path(fields[i+2])
+			i += 2
+			if status[0] == 'R' {
+				changes.Renamed[oldPath] = newPath
+			}
+			changes.Modified = append(changes.Modified, newPath)
+		case 'D':
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff output: missing path for %s", status)
+			}
+			i++
+			changes.Deleted = append(changes.Deleted, path(fields[i]))
+		default:
+			// Added, modified and type changed files are reloaded
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff output: missing path for %s", status)
+			}
+			i++
+			changes.Modified = append(changes.Modified, path(fields[i]))
+		}
+	}
+
+	return changes, nil
+}
+
+// UpdateSubmodules recursively initializes and updates submodules
+func (b *execBackend) UpdateSubmodules(path string, paths []string, depth int) error {
+	args := []string{"-C", path, "submodule", "update", "--init", "--recursive", "--force"}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	args = append(args, "--")
+	args = append(args, paths...)
+
+	if err := b.run(args...); err != nil {
+		return fmt.Errorf("git submodule update failed: %w", err)
+	}
+	return nil
+}
+
+// FetchLFS downloads Git LFS content with git lfs pull
+func (b *execBackend) FetchLFS(path string, include []string, submodules bool) error {
+	if err := b.command("lfs", "version").Run(); err != nil {
+		return fmt.Errorf("git lfs is not available: install Git LFS or use --git-lfs %s", LFSSkip)
+	}
+
+	args := []string{"-C", path, "lfs", "pull"}
+	if len(include) > 0 {
+		args = append(args, "--include", strings.Join(include, ","))
+	}
+	if err := b.run(args...); err != nil {
+		return fmt.Errorf("git lfs pull failed: %w", err)
+	}
+
+	if submodules {
+		// Submodule doc paths are relative to the submodule, so fetch all
+		// of their LFS content
+		if err := b.run("-C", path, "submodule", "--quiet", "foreach", "--recursive", "git lfs pull"); err != nil {
+			return fmt.Errorf("git lfs pull in submodules failed: %w", err)
+		}
+	}
+
+	return nil
+}
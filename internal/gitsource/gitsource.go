@@ -10,34 +10,44 @@
 package gitsource
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/pgedge/pgedge-docloader/internal/glob"
 	"github.com/pgedge/pgedge-docloader/internal/types"
 )
 
 // GitSource represents a git repository source
 type GitSource struct {
-	config   *types.Config
-	repoPath string
-	cleanup  func() error
+	config    *types.Config
+	repoPath  string
+	ref       string       // Ref checked out in a worktree (see Checkouts)
+	worktrees []*GitSource // One per ref, when loading several refs
+	git       backend      // Performs Git operations on the clone
+	cleanup   func() error
+	unlock    func() error // Releases the lock on a persistent clone
 }
 
 // New creates a new GitSource from configuration
 func New(cfg *types.Config) (*GitSource, error) {
-	// Check git is available
-	if _, err := exec.LookPath("git"); err != nil {
-		return nil, fmt.Errorf("git command not found: please install git to use git sources")
+	git, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	gs := &GitSource{
 		config: cfg,
+		git:    git,
 	}
 
 	if err := gs.setup(); err != nil {
+		_ = gs.Cleanup() //nolint:errcheck // the setup error is reported
 		return nil, err
 	}
 
@@ -62,25 +72,40 @@ func (gs *GitSource) setup() error {
 				return os.RemoveAll(tmpDir)
 			}
 		}
-	} else {
-		// Using specified directory - ensure it exists
-		if err := os.MkdirAll(cloneDir, 0755); err != nil {
-			return fmt.Errorf("failed to create clone directory: %w", err)
-		}
 	}
 
 	// Extract repo name from URL for subdirectory
 	repoName := extractRepoName(gs.config.GitURL)
 	gs.repoPath = filepath.Join(cloneDir, repoName)
 
+	if gs.config.GitCloneDir != "" {
+		// Using specified directory - ensure it exists
+		if err := os.MkdirAll(cloneDir, 0755); err != nil {
+			return fmt.Errorf("failed to create clone directory: %w", err)
+		}
+
+		// Other runs using the clone wait until this one is finished
+		unlock, err := acquireLock(filepath.Join(cloneDir, repoName+".lock"))
+		if err != nil {
+			return err
+		}
+		gs.unlock = unlock
+	}
+
 	// Check if repo already exists
+	var commit string
 	if _, err := os.Stat(filepath.Join(gs.repoPath, ".git")); err == nil {
 		// Repo exists
+		if err := gs.verifyOrigin(); err != nil {
+			return err
+		}
+
 		if gs.config.GitSkipFetch {
 			fmt.Printf("Using existing clone: %s\n", gs.repoPath)
 		} else {
 			fmt.Printf("Repository exists, fetching updates: %s\n", gs.repoPath)
-			if err := gs.fetch(); err != nil {
+			var err error
+			if commit, err = gs.fetch(); err != nil {
 				return err
 			}
 		}
@@ -91,89 +116,315 @@ func (gs *GitSource) setup() error {
 		}
 	}
 
-	// Checkout specific branch/tag if specified
-	if err := gs.checkout(); err != nil {
+	// Check out each of several refs in its own worktree
+	if len(gs.config.GitRefs) > 0 {
+		return gs.setupWorktrees(cloneDir, repoName)
+	}
+
+	// Without a fetch, check out the branch, tag or default branch as it
+	// is in the clone
+	if commit == "" {
+		var err error
+		if commit, err = gs.git.Resolve(gs.repoPath, gs.localRev()); err != nil {
+			if gs.config.GitSkipFetch {
+				return fmt.Errorf("git checkout failed: %w (the ref may not have been fetched; run without --git-skip-fetch)", err)
+			}
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+	}
+	if err := gs.checkout(commit); err != nil {
+		return err
+	}
+
+	return gs.populate()
+}
+
+// setupWorktrees resolves the configured refs against the remote and checks
+// each out in a worktree of the clone, alongside it in the clone directory
+func (gs *GitSource) setupWorktrees(cloneDir, repoName string) error {
+	refs, err := gs.resolveRefs()
+	if err != nil {
+		return err
+	}
+
+	// Forget worktrees whose directories have been removed
+	if err := gs.git.PruneWorktrees(gs.repoPath); err != nil {
 		return err
 	}
 
+	for _, ref := range refs {
+		wtPath := filepath.Join(cloneDir, repoName+"@"+strings.ReplaceAll(ref.name, "/", "-"))
+		fmt.Printf("Checking out %s in worktree: %s\n", ref.name, wtPath)
+
+		if err := gs.ensureCommit(ref.commit, ref.ref); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", ref.name, err)
+		}
+
+		opts := checkoutOptions{Commit: ref.commit, SparseDirs: sparseDirs(gs.config.GitDocPath)}
+		if err := gs.git.CheckoutWorktree(gs.repoPath, wtPath, opts); err != nil {
+			return fmt.Errorf("git worktree checkout of %s failed: %w", ref.name, err)
+		}
+
+		worktree := &GitSource{
+			config:   gs.config,
+			repoPath: wtPath,
+			ref:      ref.name,
+			git:      gs.git,
+		}
+		if err := worktree.populate(); err != nil {
+			return fmt.Errorf("failed to populate %s: %w", ref.name, err)
+		}
+		gs.worktrees = append(gs.worktrees, worktree)
+	}
+
 	return nil
 }
 
-// clone clones the repository
-func (gs *GitSource) clone() error {
-	fmt.Printf("Cloning repository: %s\n", gs.config.GitURL)
+// remoteRef is a branch or tag on the remote and the commit it points to
+type remoteRef struct {
+	name   string
+	ref    string // Full ref name (empty for a commit SHA)
+	commit string
+}
 
-	args := []string{"clone", "--depth", "1"}
+// resolveRefs resolves the configured refs to commits on the remote.
+// Branches take precedence over tags of the same name, globs are matched
+// against tag names, and anything else is assumed to be a commit SHA.
+func (gs *GitSource) resolveRefs() ([]remoteRef, error) {
+	branches, tags, err := gs.git.RemoteRefs(gs.repoPath)
+	if err != nil {
+		return nil, err
+	}
 
-	// Add branch/tag to clone command for efficiency
-	if gs.config.GitBranch != "" {
-		args = append(args, "--branch", gs.config.GitBranch)
-	} else if gs.config.GitTag != "" {
-		args = append(args, "--branch", gs.config.GitTag)
+	tagNames := make([]string, 0, len(tags))
+	for name := range tags {
+		tagNames = append(tagNames, name)
+	}
+	sort.Strings(tagNames)
+
+	var refs []remoteRef
+	seen := make(map[string]bool)
+	add := func(name, ref, commit string) {
+		if !seen[name] {
+			seen[name] = true
+			refs = append(refs, remoteRef{name: name, ref: ref, commit: commit})
+		}
 	}
 
-	args = append(args, gs.config.GitURL, gs.repoPath)
+	for _, ref := range gs.config.GitRefs {
+		switch {
+		case strings.ContainsAny(ref, "*?["):
+			matched := false
+			for _, name := range tagNames {
+				if ok, err := path.Match(ref, name); err != nil {
+					return nil, fmt.Errorf("invalid ref pattern %q: %w", ref, err)
+				} else if ok {
+					add(name, "refs/tags/"+name, tags[name])
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("no tags match %q", ref)
+			}
+		case branches[ref] != "":
+			add(ref, "refs/heads/"+ref, branches[ref])
+		case tags[ref] != "":
+			add(ref, "refs/tags/"+ref, tags[ref])
+		default:
+			add(ref, "", ref)
+		}
+	}
 
-	cmd := exec.Command("git", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return refs, nil
+}
+
+// ensureCommit fetches a commit from the remote if it is not in the clone,
+// by the ref that points to it if known
+func (gs *GitSource) ensureCommit(sha, ref string) error {
+	if gs.git.HasCommit(gs.repoPath, sha) {
+		return nil
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+	depth := 0
+	if gs.git.IsShallow(gs.repoPath) {
+		depth = 1
+	}
+	if err := gs.git.FetchCommit(gs.repoPath, sha, ref, depth); err != nil || !gs.git.HasCommit(gs.repoPath, sha) {
+		return fmt.Errorf("%w: %s", ErrCommitNotFound, sha)
 	}
 
 	return nil
 }
 
-// fetch fetches updates from the remote
-func (gs *GitSource) fetch() error {
-	cmd := exec.Command("git", "-C", gs.repoPath, "fetch", "--all", "--prune", "--tags")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// Checkouts returns a source for each checked out ref: one per configured
+// ref when loading several refs, or the clone itself otherwise
+func (gs *GitSource) Checkouts() []*GitSource {
+	if len(gs.config.GitRefs) > 0 {
+		return gs.worktrees
+	}
+	return []*GitSource{gs}
+}
+
+// clone clones the repository. When doc paths are given, only they are
+// checked out (sparse checkout).
+func (gs *GitSource) clone() error {
+	fmt.Printf("Cloning repository: %s\n", RedactURL(gs.config.GitURL))
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git fetch failed: %w", err)
+	opts := cloneOptions{
+		Branch:     gs.config.GitBranch,
+		Tag:        gs.config.GitTag,
+		SparseDirs: sparseDirs(gs.config.GitDocPath),
+	}
+	if !gs.config.GitFullHistory {
+		opts.Depth = 1
 	}
 
-	return nil
+	return gs.git.Clone(gs.config.GitURL, gs.repoPath, opts)
 }
 
-// checkout checks out the specified branch or tag
-func (gs *GitSource) checkout() error {
-	var ref string
-	if gs.config.GitBranch != "" {
-		ref = gs.config.GitBranch
-	} else if gs.config.GitTag != "" {
-		ref = gs.config.GitTag
-	} else {
-		return nil // Use default branch from clone
+// sparseDirs returns the directories to check out for the given doc paths,
+// or nil if the whole tree is needed. Brace alternatives are expanded, glob
+// patterns are reduced to the directory before the first wildcard, and
+// paths that look like files (with an extension) to their parent directory.
+func sparseDirs(docPaths []string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+
+	var patterns []string
+	for _, docPath := range docPaths {
+		patterns = append(patterns, glob.Expand(filepath.ToSlash(docPath))...)
 	}
 
-	fmt.Printf("Checking out: %s\n", ref)
+	for _, pattern := range patterns {
+		parts := strings.Split(path.Clean(pattern), "/")
+
+		var dir []string
+		globbed := false
+		for _, part := range parts {
+			if glob.HasMeta(part) {
+				globbed = true
+				break
+			}
+			dir = append(dir, part)
+		}
+		if !globbed && len(dir) > 0 && path.Ext(dir[len(dir)-1]) != "" {
+			dir = dir[:len(dir)-1]
+		}
+
+		d := strings.TrimPrefix(strings.Join(dir, "/"), "/")
+		if d == "" || d == "." || strings.HasPrefix(d, "..") {
+			return nil
+		}
+		if !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
 
-	cmd := exec.Command("git", "-C", gs.repoPath, "checkout", ref)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return dirs
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git checkout failed: %w", err)
+// fetch fetches the configured branch, tag or default branch from the
+// remote into an existing clone, and returns its commit. A shallow clone
+// stays shallow (and can fetch a ref it has not seen before) unless the
+// full history is needed. When loading several refs, their commits are
+// fetched as they are checked out (see ensureCommit).
+func (gs *GitSource) fetch() (string, error) {
+	shallow := gs.git.IsShallow(gs.repoPath)
+
+	opts := fetchOptions{Branch: gs.config.GitBranch, Tag: gs.config.GitTag}
+	switch {
+	case gs.config.GitFullHistory && shallow:
+		opts.Unshallow = true
+	case shallow:
+		opts.Depth = 1
 	}
 
-	// Pull latest if on a branch (not a tag) and not skipping fetch
-	if gs.config.GitBranch != "" && !gs.config.GitSkipFetch {
-		pullCmd := exec.Command("git", "-C", gs.repoPath, "pull", "--ff-only")
-		pullCmd.Stdout = os.Stdout
-		pullCmd.Stderr = os.Stderr
-		// Pull may fail for various reasons (detached HEAD, conflicts, etc.)
-		// This is not fatal - we already have the checkout
-		if err := pullCmd.Run(); err != nil {
-			fmt.Printf("Note: git pull skipped (%v)\n", err)
+	if len(gs.config.GitRefs) > 0 {
+		if !opts.Unshallow {
+			return "", nil
+		}
+		opts.Branch, opts.Tag = "", ""
+	}
+
+	return gs.git.Fetch(gs.repoPath, opts)
+}
+
+// localRev returns the revision in the clone of the configured branch, tag
+// or default branch
+func (gs *GitSource) localRev() string {
+	switch {
+	case gs.config.GitBranch != "":
+		return "refs/remotes/origin/" + gs.config.GitBranch
+	case gs.config.GitTag != "":
+		return "refs/tags/" + gs.config.GitTag
+	default:
+		return "HEAD"
+	}
+}
+
+// checkout checks out a commit of the configured branch, tag or default
+// branch, discarding any local modifications and untracked files left in
+// the working tree
+func (gs *GitSource) checkout(commit string) error {
+	if ref := gs.Ref(); ref != "" {
+		fmt.Printf("Checking out: %s\n", ref)
+	}
+
+	return gs.git.Checkout(gs.repoPath, checkoutOptions{
+		Commit:     commit,
+		Branch:     gs.config.GitBranch,
+		SparseDirs: sparseDirs(gs.config.GitDocPath),
+	})
+}
+
+// verifyOrigin checks that an existing clone is of the configured
+// repository, and updates its remote URL if only the credentials differ
+func (gs *GitSource) verifyOrigin() error {
+	origin, err := gs.git.OriginURL(gs.repoPath)
+	if err != nil {
+		return fmt.Errorf("existing clone %s has no origin remote (%w): remove it or use another --git-clone-dir",
+			gs.repoPath, err)
+	}
+
+	if !sameRepoURL(origin, gs.config.GitURL) {
+		return fmt.Errorf("existing clone %s is of %s, not %s: remove it or use another --git-clone-dir",
+			gs.repoPath, RedactURL(origin), RedactURL(gs.config.GitURL))
+	}
+
+	if origin != gs.config.GitURL {
+		if err := gs.git.SetOriginURL(gs.repoPath, gs.config.GitURL); err != nil {
+			return fmt.Errorf("failed to update origin of %s: %w", gs.repoPath, err)
 		}
 	}
 
 	return nil
 }
 
+// sameRepoURL returns true if two repository URLs refer to the same
+// repository, ignoring credentials, a trailing slash or .git suffix, and
+// the case of the host
+func sameRepoURL(a, b string) bool {
+	return normalizeRepoURL(a) == normalizeRepoURL(b)
+}
+
+// normalizeRepoURL reduces a repository URL or path for comparison
+func normalizeRepoURL(rawURL string) string {
+	s := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		if u.Scheme == "file" {
+			s = u.Path
+		} else {
+			s = u.Scheme + "://" + strings.ToLower(u.Host) + u.Path
+		}
+	}
+	if !strings.Contains(s, "://") && !strings.Contains(s, ":") {
+		s = filepath.ToSlash(filepath.Clean(s))
+	}
+	s = strings.TrimSuffix(s, "/")
+	return strings.TrimSuffix(s, ".git")
+}
+
 // GetSourcePaths returns the paths to process files from
 func (gs *GitSource) GetSourcePaths() []string {
 	if len(gs.config.GitDocPath) > 0 {
@@ -186,13 +437,86 @@ func (gs *GitSource) GetSourcePaths() []string {
 	return []string{gs.repoPath}
 }
 
+// Ref returns the branch or tag that was checked out, or an empty string
+// if the repository default branch is used
+func (gs *GitSource) Ref() string {
+	if gs.ref != "" {
+		return gs.ref
+	}
+	if gs.config.GitBranch != "" {
+		return gs.config.GitBranch
+	}
+	return gs.config.GitTag
+}
+
+// HeadCommit returns the SHA of the commit currently checked out
+func (gs *GitSource) HeadCommit() (string, error) {
+	return gs.git.HeadCommit(gs.repoPath)
+}
+
+// FileMetadata returns per-file metadata from the repository history, keyed
+// by the path of each file within the clone. Modified, author and commit
+// are taken from the last commit to touch each file, and created from the
+// first. Only the history of the doc paths is read. A shallow clone would
+// credit every file to the commits it holds, so it gives no metadata.
+func (gs *GitSource) FileMetadata() (map[string]*types.FileMetadata, error) {
+	if gs.git.IsShallow(gs.repoPath) {
+		fmt.Println("Note: shallow clone, file timestamps, author and commit are not recorded (use --git-full-history)")
+		return map[string]*types.FileMetadata{}, nil
+	}
+
+	return gs.git.FileHistory(gs.repoPath, sparseDirs(gs.config.GitDocPath))
+}
+
+// ErrCommitNotFound is returned by Changes when the previously loaded
+// commit is not available in the repository (e.g. after a force push)
+var ErrCommitNotFound = errors.New("commit not found in repository")
+
+// Changes lists the files changed between two commits, by path within the
+// clone
+type Changes struct {
+	Modified []string          // Added, modified or copied files
+	Deleted  []string          // Deleted files
+	Renamed  map[string]string // Old path -> new path
+}
+
+// Changes returns the files changed between the given commit and HEAD. If
+// the commit is not present in a shallow clone it is fetched first. With
+// submodules, all files of a submodule whose commit changed are modified.
+func (gs *GitSource) Changes(since string) (*Changes, error) {
+	if err := gs.ensureCommit(since, ""); err != nil {
+		return nil, err
+	}
+
+	changes, err := gs.git.Diff(gs.repoPath, since)
+	if err != nil {
+		return nil, err
+	}
+
+	if gs.config.GitSubmodules {
+		if changes.Modified, err = expandSubmodules(changes.Modified); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
 // Cleanup removes the cloned repository if configured
 func (gs *GitSource) Cleanup() error {
+	var err error
 	if gs.cleanup != nil {
 		fmt.Println("Cleaning up cloned repository...")
-		return gs.cleanup()
+		err = gs.cleanup()
+		gs.cleanup = nil
 	}
-	return nil
+	if gs.unlock != nil {
+		if unlockErr := gs.unlock(); err == nil {
+			err = unlockErr
+		}
+		gs.unlock = nil
+	}
+	return err
 }
 
 // extractRepoName extracts repository name from URL
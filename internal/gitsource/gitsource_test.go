@@ -10,11 +10,14 @@
 package gitsource
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pgedge/pgedge-docloader/internal/types"
 )
@@ -94,228 +97,963 @@ func TestGitSourceCloneAndCleanup(t *testing.T) {
 		t.Skip("git not available")
 	}
 
-	// Create a local bare repository for testing
-	tmpDir := t.TempDir()
-	bareRepo := filepath.Join(tmpDir, "test-repo.git")
+	forEachBackend(t, func(t *testing.T, backend string) {
+		// Create a local bare repository for testing
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
 
-	// Initialize bare repo
-	if err := exec.Command("git", "init", "--bare", bareRepo).Run(); err != nil {
-		t.Fatalf("failed to create bare repo: %v", err)
+		// Initialize bare repo
+		if err := exec.Command("git", "init", "--bare", bareRepo).Run(); err != nil {
+			t.Fatalf("failed to create bare repo: %v", err)
+		}
+
+		// Create a working copy, add a file, and push
+		workDir := filepath.Join(tmpDir, "work")
+		if err := exec.Command("git", "clone", bareRepo, workDir).Run(); err != nil {
+			t.Fatalf("failed to clone: %v", err)
+		}
+
+		testFile := filepath.Join(workDir, "test.md")
+		if err := os.WriteFile(testFile, []byte("# Test\n\nContent"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+
+		if err := exec.Command("git", "-C", workDir, "add", ".").Run(); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+
+		exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
+		exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
+
+		if err := exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+
+		if err := exec.Command("git", "-C", workDir, "push").Run(); err != nil {
+			t.Fatalf("failed to push: %v", err)
+		}
+
+		// Test GitSource with temp directory (cleanup enabled)
+		cfg := &types.Config{
+			GitBackend:   backend,
+			GitURL:       bareRepo,
+			GitKeepClone: false,
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+
+		sourcePaths := gs.GetSourcePaths()
+		if len(sourcePaths) == 0 {
+			t.Fatal("expected at least one source path")
+		}
+		sourcePath := sourcePaths[0]
+		if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+			t.Error("source path should exist after clone")
+		}
+
+		// Verify test.md exists
+		clonedFile := filepath.Join(sourcePath, "test.md")
+		if _, err := os.Stat(clonedFile); os.IsNotExist(err) {
+			t.Error("test.md should exist in cloned repo")
+		}
+
+		// Cleanup
+		if err := gs.Cleanup(); err != nil {
+			t.Errorf("cleanup failed: %v", err)
+		}
+
+		// Verify cleanup occurred
+		if _, err := os.Stat(sourcePath); !os.IsNotExist(err) {
+			t.Error("source path should be removed after cleanup")
+		}
+	})
+}
+
+func TestGitSourceWithDocPath(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		exec.Command("git", "init", "--bare", bareRepo).Run()
+
+		workDir := filepath.Join(tmpDir, "work")
+		exec.Command("git", "clone", bareRepo, workDir).Run()
+
+		// Create docs subdirectory
+		docsDir := filepath.Join(workDir, "docs", "api")
+		os.MkdirAll(docsDir, 0755)
+		os.WriteFile(filepath.Join(docsDir, "api.md"), []byte("# API\n\nDocs"), 0644)
+
+		exec.Command("git", "-C", workDir, "add", ".").Run()
+		exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
+		exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
+		exec.Command("git", "-C", workDir, "commit", "-m", "add docs").Run()
+		exec.Command("git", "-C", workDir, "push").Run()
+
+		cfg := &types.Config{
+			GitBackend:   backend,
+			GitURL:       bareRepo,
+			GitDocPath:   []string{"docs/api"},
+			GitKeepClone: false,
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer gs.Cleanup()
+
+		sourcePaths := gs.GetSourcePaths()
+		if len(sourcePaths) == 0 {
+			t.Fatal("expected at least one source path")
+		}
+		sourcePath := sourcePaths[0]
+		if !filepath.IsAbs(sourcePath) {
+			t.Error("source path should be absolute")
+		}
+
+		expectedSuffix := filepath.Join("docs", "api")
+		if !strings.HasSuffix(sourcePath, expectedSuffix) {
+			t.Errorf("source path should end with %s, got %s", expectedSuffix, sourcePath)
+		}
+
+		// Verify api.md exists in the doc path
+		apiFile := filepath.Join(sourcePath, "api.md")
+		if _, err := os.Stat(apiFile); os.IsNotExist(err) {
+			t.Error("api.md should exist in doc path")
+		}
+	})
+}
+
+func TestGitSourceKeepClone(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		exec.Command("git", "init", "--bare", bareRepo).Run()
+
+		workDir := filepath.Join(tmpDir, "work")
+		exec.Command("git", "clone", bareRepo, workDir).Run()
+		os.WriteFile(filepath.Join(workDir, "test.md"), []byte("# Test"), 0644)
+		exec.Command("git", "-C", workDir, "add", ".").Run()
+		exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
+		exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
+		exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run()
+		exec.Command("git", "-C", workDir, "push").Run()
+
+		cloneDir := filepath.Join(tmpDir, "clones")
+		cfg := &types.Config{
+			GitBackend:   backend,
+			GitURL:       bareRepo,
+			GitCloneDir:  cloneDir,
+			GitKeepClone: true,
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+
+		sourcePaths := gs.GetSourcePaths()
+		if len(sourcePaths) == 0 {
+			t.Fatal("expected at least one source path")
+		}
+		sourcePath := sourcePaths[0]
+
+		// Cleanup should not remove the repo when GitKeepClone is true
+		gs.Cleanup()
+
+		// Verify repo still exists
+		if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+			t.Error("source path should still exist when GitKeepClone is true")
+		}
+	})
+}
+
+func TestGitSourceSkipFetch(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
 	}
 
-	// Create a working copy, add a file, and push
-	workDir := filepath.Join(tmpDir, "work")
-	if err := exec.Command("git", "clone", bareRepo, workDir).Run(); err != nil {
-		t.Fatalf("failed to clone: %v", err)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		exec.Command("git", "init", "--bare", bareRepo).Run()
+
+		workDir := filepath.Join(tmpDir, "work")
+		exec.Command("git", "clone", bareRepo, workDir).Run()
+		os.WriteFile(filepath.Join(workDir, "test.md"), []byte("# Test"), 0644)
+		exec.Command("git", "-C", workDir, "add", ".").Run()
+		exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
+		exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
+		exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run()
+		exec.Command("git", "-C", workDir, "push").Run()
+
+		cloneDir := filepath.Join(tmpDir, "clones")
+
+		// First clone
+		cfg := &types.Config{
+			GitBackend:   backend,
+			GitURL:       bareRepo,
+			GitCloneDir:  cloneDir,
+			GitKeepClone: true,
+		}
+		gs1, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create first GitSource: %v", err)
+		}
+		gs1.Cleanup()
+
+		// Second use with skip-fetch should reuse existing clone
+		cfg.GitSkipFetch = true
+		gs2, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create second GitSource: %v", err)
+		}
+		defer gs2.Cleanup()
+
+		// Should succeed without network call
+		sourcePaths := gs2.GetSourcePaths()
+		if len(sourcePaths) == 0 {
+			t.Fatal("expected at least one source path")
+		}
+		if _, err := os.Stat(sourcePaths[0]); os.IsNotExist(err) {
+			t.Error("source path should exist")
+		}
+	})
+}
+
+// forEachBackend runs a test with each Git backend
+func forEachBackend(t *testing.T, test func(t *testing.T, backend string)) {
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+// runGit runs a git command in dir, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
 	}
+	return strings.TrimSpace(string(out))
+}
 
-	testFile := filepath.Join(workDir, "test.md")
-	if err := os.WriteFile(testFile, []byte("# Test\n\nContent"), 0644); err != nil {
-		t.Fatalf("failed to create test file: %v", err)
+// commitFile writes a file in the working copy and commits it with the
+// given author and date
+func commitFile(t *testing.T, workDir, name, content, author, date string) {
+	t.Helper()
+	path := filepath.Join(workDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
 	}
+	runGit(t, workDir, "add", name)
+	runGit(t, workDir, "-c", "user.name="+author, "-c", "user.email=test@test.com",
+		"commit", "-m", "update "+name, "--author", author+" <test@test.com>", "--date", date)
+}
 
-	if err := exec.Command("git", "-C", workDir, "add", ".").Run(); err != nil {
-		t.Fatalf("failed to add: %v", err)
+func TestParseFileHistory(t *testing.T) {
+	out := []byte("\x1eccc\x1f300\x1fCarol\n\ndocs/a.md\n" +
+		"\x1ebbb\x1f200\x1fBob\n\ndocs/a.md\ndocs/b.md\n" +
+		"\x1eaaa\x1f100\x1fAlice\n\ndocs/a.md\n")
+
+	metadata, err := parseFileHistory(out, "/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
-	exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
+	a := metadata[filepath.Join("/repo", "docs", "a.md")]
+	if a == nil {
+		t.Fatal("expected metadata for docs/a.md")
+	}
+	if a.Commit != "ccc" || a.Author != "Carol" || a.Modified.Unix() != 300 || a.Created.Unix() != 100 {
+		t.Errorf("unexpected metadata for a.md: %+v", a)
+	}
 
-	if err := exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run(); err != nil {
-		t.Fatalf("failed to commit: %v", err)
+	b := metadata[filepath.Join("/repo", "docs", "b.md")]
+	if b == nil {
+		t.Fatal("expected metadata for docs/b.md")
+	}
+	if b.Commit != "bbb" || b.Author != "Bob" || b.Modified.Unix() != 200 || b.Created.Unix() != 200 {
+		t.Errorf("unexpected metadata for b.md: %+v", b)
 	}
 
-	if err := exec.Command("git", "-C", workDir, "push").Run(); err != nil {
-		t.Fatalf("failed to push: %v", err)
+	if _, err := parseFileHistory([]byte("\x1ebroken\n"), "/repo"); err == nil {
+		t.Error("expected error for malformed header, got nil")
 	}
+}
 
-	// Test GitSource with temp directory (cleanup enabled)
-	cfg := &types.Config{
-		GitURL:       bareRepo,
-		GitKeepClone: false,
+func TestGitSourceFileMetadata(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
 	}
 
-	gs, err := New(cfg)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		runGit(t, tmpDir, "init", "--bare", bareRepo)
+
+		workDir := filepath.Join(tmpDir, "work")
+		runGit(t, tmpDir, "clone", bareRepo, workDir)
+		commitFile(t, workDir, "README.md", "# Readme", "Dave", "2023-12-01T00:00:00Z")
+		commitFile(t, workDir, "docs/guide.md", "# Guide", "Alice", "2024-01-01T00:00:00Z")
+		commitFile(t, workDir, "docs/other.md", "# Other", "Bob", "2024-02-01T00:00:00Z")
+		commitFile(t, workDir, "docs/guide.md", "# Guide v2", "Carol", "2024-03-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD")
+		head := runGit(t, workDir, "rev-parse", "HEAD")
+
+		cfg := &types.Config{
+			GitBackend:     backend,
+			GitURL:         bareRepo,
+			GitDocPath:     []string{"docs"},
+			GitFullHistory: true,
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer gs.Cleanup()
+
+		metadata, err := gs.FileMetadata()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		guide := metadata[filepath.Join(gs.repoPath, "docs", "guide.md")]
+		if guide == nil {
+			t.Fatal("expected metadata for docs/guide.md")
+		}
+		if guide.Author != "Carol" || guide.Commit != head {
+			t.Errorf("expected last commit by Carol (%s), got %s (%s)", head, guide.Author, guide.Commit)
+		}
+		if got := guide.Modified.Format("2006-01-02"); got != "2024-03-01" {
+			t.Errorf("expected modified 2024-03-01, got %s", got)
+		}
+		if got := guide.Created.Format("2006-01-02"); got != "2024-01-01" {
+			t.Errorf("expected created 2024-01-01, got %s", got)
+		}
+
+		other := metadata[filepath.Join(gs.repoPath, "docs", "other.md")]
+		if other == nil || other.Author != "Bob" {
+			t.Errorf("expected metadata for docs/other.md by Bob, got %+v", other)
+		}
+
+		// Only the history of the doc paths is read
+		if readme := metadata[filepath.Join(gs.repoPath, "README.md")]; readme != nil {
+			t.Errorf("expected no metadata for README.md, got %+v", readme)
+		}
+
+		// A shallow clone would attribute all files to the commit fetched,
+		// so it gives no metadata
+		shallow, err := New(&types.Config{GitBackend: backend, GitURL: "file://" + bareRepo})
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer shallow.Cleanup()
+
+		if metadata, err = shallow.FileMetadata(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if metadata == nil || len(metadata) != 0 {
+			t.Errorf("expected empty metadata for a shallow clone, got %v", metadata)
+		}
+	})
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := []byte("M\x00docs/a.md\x00A\x00docs/new.md\x00D\x00docs/old.md\x00" +
+		"R095\x00docs/b.md\x00docs/c.md\x00C100\x00docs/a.md\x00docs/copy.md\x00")
+
+	changes, err := parseNameStatus(out, "/repo")
 	if err != nil {
-		t.Fatalf("failed to create GitSource: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	sourcePaths := gs.GetSourcePaths()
-	if len(sourcePaths) == 0 {
-		t.Fatal("expected at least one source path")
+	path := func(p string) string { return filepath.Join("/repo", "docs", p) }
+
+	expectedModified := []string{path("a.md"), path("new.md"), path("c.md"), path("copy.md")}
+	if len(changes.Modified) != len(expectedModified) {
+		t.Fatalf("expected %d modified files, got %v", len(expectedModified), changes.Modified)
+	}
+	for i, p := range expectedModified {
+		if changes.Modified[i] != p {
+			t.Errorf("modified[%d]: expected %s, got %s", i, p, changes.Modified[i])
+		}
 	}
-	sourcePath := sourcePaths[0]
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		t.Error("source path should exist after clone")
+
+	if len(changes.Deleted) != 1 || changes.Deleted[0] != path("old.md") {
+		t.Errorf("expected old.md deleted, got %v", changes.Deleted)
 	}
 
-	// Verify test.md exists
-	clonedFile := filepath.Join(sourcePath, "test.md")
-	if _, err := os.Stat(clonedFile); os.IsNotExist(err) {
-		t.Error("test.md should exist in cloned repo")
+	if len(changes.Renamed) != 1 || changes.Renamed[path("b.md")] != path("c.md") {
+		t.Errorf("expected b.md renamed to c.md, got %v", changes.Renamed)
 	}
 
-	// Cleanup
-	if err := gs.Cleanup(); err != nil {
-		t.Errorf("cleanup failed: %v", err)
+	empty, err := parseNameStatus(nil, "/repo")
+	if err != nil || len(empty.Modified) != 0 {
+		t.Errorf("expected no changes for empty output, got %+v, %v", empty, err)
 	}
 
-	// Verify cleanup occurred
-	if _, err := os.Stat(sourcePath); !os.IsNotExist(err) {
-		t.Error("source path should be removed after cleanup")
+	if _, err := parseNameStatus([]byte("R100\x00docs/b.md\x00"), "/repo"); err == nil {
+		t.Error("expected error for truncated rename, got nil")
 	}
 }
 
-func TestGitSourceWithDocPath(t *testing.T) {
+func TestGitSourceChanges(t *testing.T) {
 	// Skip if git is not available
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
 	}
 
-	tmpDir := t.TempDir()
-	bareRepo := filepath.Join(tmpDir, "test-repo.git")
-	exec.Command("git", "init", "--bare", bareRepo).Run()
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		runGit(t, tmpDir, "init", "--bare", bareRepo)
+
+		workDir := filepath.Join(tmpDir, "work")
+		runGit(t, tmpDir, "clone", bareRepo, workDir)
+		commitFile(t, workDir, "docs/keep.md", "# Keep", "Alice", "2024-01-01T00:00:00Z")
+		commitFile(t, workDir, "docs/remove.md", "# Remove", "Alice", "2024-01-01T00:00:00Z")
+		commitFile(t, workDir, "docs/move.md", "# Move\n\nThis file is renamed but not changed.\n", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD")
+		first := runGit(t, workDir, "rev-parse", "HEAD")
+
+		commitFile(t, workDir, "docs/keep.md", "# Keep v2", "Bob", "2024-02-01T00:00:00Z")
+		runGit(t, workDir, "rm", "-q", "docs/remove.md")
+		runGit(t, workDir, "mv", "docs/move.md", "docs/moved.md")
+		runGit(t, workDir, "commit", "-m", "remove and rename")
+		runGit(t, workDir, "push", "origin", "HEAD")
 
-	workDir := filepath.Join(tmpDir, "work")
-	exec.Command("git", "clone", bareRepo, workDir).Run()
+		// A shallow clone does not contain the first commit, so it is fetched
+		gs, err := New(&types.Config{GitURL: "file://" + bareRepo, GitBackend: backend})
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer gs.Cleanup()
+
+		changes, err := gs.Changes(first)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		path := func(p string) string { return filepath.Join(gs.repoPath, "docs", p) }
+
+		if len(changes.Deleted) != 1 || changes.Deleted[0] != path("remove.md") {
+			t.Errorf("expected remove.md deleted, got %v", changes.Deleted)
+		}
+		if changes.Renamed[path("move.md")] != path("moved.md") {
+			t.Errorf("expected move.md renamed to moved.md, got %v", changes.Renamed)
+		}
+
+		modified := make(map[string]bool)
+		for _, p := range changes.Modified {
+			modified[p] = true
+		}
+		if !modified[path("keep.md")] || !modified[path("moved.md")] || len(modified) != 2 {
+			t.Errorf("expected keep.md and moved.md modified, got %v", changes.Modified)
+		}
+
+		// An unknown commit cannot be diffed against
+		if _, err := gs.Changes("0123456789abcdef0123456789abcdef01234567"); !errors.Is(err, ErrCommitNotFound) {
+			t.Errorf("expected ErrCommitNotFound, got %v", err)
+		}
+	})
+}
 
-	// Create docs subdirectory
-	docsDir := filepath.Join(workDir, "docs", "api")
-	os.MkdirAll(docsDir, 0755)
-	os.WriteFile(filepath.Join(docsDir, "api.md"), []byte("# API\n\nDocs"), 0644)
+func TestParseRemoteRefs(t *testing.T) {
+	out := []byte("aaa\trefs/heads/main\n" +
+		"bbb\trefs/heads/release/16\n" +
+		"ccc\trefs/tags/v15\n" +
+		"ddd\trefs/tags/v16\n" +
+		"eee\trefs/tags/v16^{}\n")
 
-	exec.Command("git", "-C", workDir, "add", ".").Run()
-	exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
-	exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
-	exec.Command("git", "-C", workDir, "commit", "-m", "add docs").Run()
-	exec.Command("git", "-C", workDir, "push").Run()
+	branches, tags := parseRemoteRefs(out)
 
-	cfg := &types.Config{
-		GitURL:       bareRepo,
-		GitDocPath:   []string{"docs/api"},
-		GitKeepClone: false,
+	if branches["main"] != "aaa" || branches["release/16"] != "bbb" || len(branches) != 2 {
+		t.Errorf("unexpected branches: %v", branches)
 	}
+	if tags["v15"] != "ccc" || len(tags) != 2 {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	// Annotated tags resolve to the commit they point to
+	if tags["v16"] != "eee" {
+		t.Errorf("expected peeled commit for v16, got %s", tags["v16"])
+	}
+}
 
-	gs, err := New(cfg)
-	if err != nil {
-		t.Fatalf("failed to create GitSource: %v", err)
+func TestGitSourceRefs(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
 	}
-	defer gs.Cleanup()
 
-	sourcePaths := gs.GetSourcePaths()
-	if len(sourcePaths) == 0 {
-		t.Fatal("expected at least one source path")
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		runGit(t, tmpDir, "init", "--bare", bareRepo)
+
+		workDir := filepath.Join(tmpDir, "work")
+		runGit(t, tmpDir, "clone", bareRepo, workDir)
+		commitFile(t, workDir, "docs/index.md", "# Version 1", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, workDir, "tag", "v1")
+		commitFile(t, workDir, "docs/index.md", "# Version 2", "Alice", "2024-02-01T00:00:00Z")
+		runGit(t, workDir, "tag", "-a", "v2", "-m", "Version 2")
+		commitFile(t, workDir, "docs/index.md", "# Development", "Alice", "2024-03-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD", "--tags")
+		branch := runGit(t, workDir, "rev-parse", "--abbrev-ref", "HEAD")
+
+		cfg := &types.Config{
+			GitBackend:  backend,
+			GitURL:      "file://" + bareRepo,
+			GitRefs:     []string{"v*", branch},
+			GitDocPath:  []string{"docs"},
+			GitCloneDir: filepath.Join(tmpDir, "clones"),
+		}
+
+		for run := 1; run <= 2; run++ {
+			// The second run reuses the clone and its worktrees
+			gs, err := New(cfg)
+			if err != nil {
+				t.Fatalf("run %d: failed to create GitSource: %v", run, err)
+			}
+
+			checkouts := gs.Checkouts()
+			if len(checkouts) != 3 {
+				t.Fatalf("run %d: expected 3 checkouts, got %d", run, len(checkouts))
+			}
+
+			expected := map[string]string{
+				"v1":   "# Version 1",
+				"v2":   "# Version 2",
+				branch: "# Development",
+			}
+			for i, ref := range []string{"v1", "v2", branch} {
+				checkout := checkouts[i]
+				if checkout.Ref() != ref {
+					t.Errorf("run %d: checkout %d: expected ref %s, got %s", run, i, ref, checkout.Ref())
+				}
+
+				paths := checkout.GetSourcePaths()
+				content, err := os.ReadFile(filepath.Join(paths[0], "index.md"))
+				if err != nil {
+					t.Fatalf("run %d: failed to read %s: %v", run, ref, err)
+				}
+				if string(content) != expected[ref] {
+					t.Errorf("run %d: %s: expected %q, got %q", run, ref, expected[ref], content)
+				}
+			}
+			gs.Cleanup()
+		}
+	})
+}
+
+func TestSparseDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		docPaths []string
+		expected []string
+	}{
+		{"No doc paths", nil, nil},
+		{"Directories", []string{"docs", "guides/user/"}, []string{"docs", "guides/user"}},
+		{"File", []string{"docs/index.md"}, []string{"docs"}},
+		{"Glob", []string{"docs/**/*.md", "api/*.rst"}, []string{"docs", "api"}},
+		{"Duplicates", []string{"docs", "docs/*.md"}, []string{"docs"}},
+		{"Root file", []string{"docs", "README.md"}, nil},
+		{"Root glob", []string{"*.md"}, nil},
+		{"Braces", []string{"{docs,guides}/**/*.{md,rst}"}, []string{"docs", "guides"}},
 	}
-	sourcePath := sourcePaths[0]
-	if !filepath.IsAbs(sourcePath) {
-		t.Error("source path should be absolute")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs := sparseDirs(tt.docPaths)
+			if strings.Join(dirs, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, dirs)
+			}
+		})
 	}
+}
 
-	expectedSuffix := filepath.Join("docs", "api")
-	if !strings.HasSuffix(sourcePath, expectedSuffix) {
-		t.Errorf("source path should end with %s, got %s", expectedSuffix, sourcePath)
+func TestGitSourceSparseClone(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
 	}
 
-	// Verify api.md exists in the doc path
-	apiFile := filepath.Join(sourcePath, "api.md")
-	if _, err := os.Stat(apiFile); os.IsNotExist(err) {
-		t.Error("api.md should exist in doc path")
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		runGit(t, tmpDir, "init", "--bare", bareRepo)
+		runGit(t, bareRepo, "config", "uploadpack.allowFilter", "true")
+
+		workDir := filepath.Join(tmpDir, "work")
+		runGit(t, tmpDir, "clone", bareRepo, workDir)
+		commitFile(t, workDir, "docs/guide.md", "# Guide", "Alice", "2024-01-01T00:00:00Z")
+		commitFile(t, workDir, "src/main.c", "int main() { return 0; }", "Alice", "2024-01-01T00:00:00Z")
+		commitFile(t, workDir, "README.md", "# Readme", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD")
+
+		cfg := &types.Config{
+			GitBackend:  backend,
+			GitURL:      "file://" + bareRepo,
+			GitDocPath:  []string{"docs"},
+			GitCloneDir: filepath.Join(tmpDir, "clones"),
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "docs", "guide.md")); err != nil {
+			t.Errorf("expected docs/guide.md to be checked out: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "src")); !os.IsNotExist(err) {
+			t.Errorf("expected src to be excluded by sparse checkout, got %v", err)
+		}
+
+		// Contents outside the doc paths are never downloaded (go-git does
+		// not make partial clones)
+		if backend == BackendExec {
+			missing := runGit(t, gs.repoPath, "rev-list", "--objects", "--all", "--missing=print")
+			if !strings.Contains(missing, "?") {
+				t.Error("expected blobs outside the doc paths to be missing from the partial clone")
+			}
+		}
+
+		// Widening the doc paths on an existing clone checks out the new paths
+		gs.Cleanup()
+		cfg.GitDocPath = []string{"docs", "src"}
+		gs, err = New(cfg)
+		if err != nil {
+			t.Fatalf("failed to reuse GitSource: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "src", "main.c")); err != nil {
+			t.Errorf("expected src/main.c to be checked out after widening: %v", err)
+		}
+	})
+}
+
+func TestSubmoduleInScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		subPath  string
+		docPaths []string
+		expected bool
+	}{
+		{"No doc paths", "vendor/lib", nil, true},
+		{"Within doc path", "docs/shared", []string{"docs"}, true},
+		{"Contains doc path", "shared", []string{"shared/docs/*.md"}, true},
+		{"Same as doc path", "docs/shared", []string{"docs/shared"}, true},
+		{"Outside doc paths", "vendor/lib", []string{"docs"}, false},
+		{"Name prefix only", "docs-old", []string{"docs"}, false},
+		{"Root doc path", "vendor/lib", []string{"*.md"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := submoduleInScope(tt.subPath, tt.docPaths); got != tt.expected {
+				t.Errorf("submoduleInScope(%q, %v) = %v, expected %v", tt.subPath, tt.docPaths, got, tt.expected)
+			}
+		})
 	}
 }
 
-func TestGitSourceKeepClone(t *testing.T) {
+func TestGitSourceSubmodules(t *testing.T) {
 	// Skip if git is not available
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
 	}
 
-	tmpDir := t.TempDir()
-	bareRepo := filepath.Join(tmpDir, "test-repo.git")
-	exec.Command("git", "init", "--bare", bareRepo).Run()
+	forEachBackend(t, func(t *testing.T, backend string) {
+		// Submodules are cloned from local paths in this test
+		t.Setenv("GIT_CONFIG_COUNT", "1")
+		t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+		t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+		tmpDir := t.TempDir()
+		newRepo := func(name string) (string, string) {
+			bare := filepath.Join(tmpDir, name+".git")
+			work := filepath.Join(tmpDir, name)
+			runGit(t, tmpDir, "init", "--bare", bare)
+			runGit(t, tmpDir, "clone", bare, work)
+			return bare, work
+		}
+
+		sharedBare, sharedWork := newRepo("shared")
+		commitFile(t, sharedWork, "intro.md", "# Shared Intro", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, sharedWork, "push", "origin", "HEAD")
+
+		otherBare, otherWork := newRepo("other")
+		commitFile(t, otherWork, "other.md", "# Other", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, otherWork, "push", "origin", "HEAD")
+
+		mainBare, mainWork := newRepo("main")
+		commitFile(t, mainWork, "docs/index.md", "# Index", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, mainWork, "submodule", "add", "file://"+sharedBare, "docs/shared")
+		runGit(t, mainWork, "submodule", "add", "file://"+otherBare, "vendor/other")
+		runGit(t, mainWork, "commit", "-m", "add submodules")
+		runGit(t, mainWork, "push", "origin", "HEAD")
+
+		cfg := &types.Config{
+			GitBackend:    backend,
+			GitURL:        "file://" + mainBare,
+			GitDocPath:    []string{"docs"},
+			GitSubmodules: true,
+		}
+
+		gs, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer gs.Cleanup()
+
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "docs", "shared", "intro.md")); err != nil {
+			t.Errorf("expected the submodule within the doc path to be checked out: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "vendor", "other", "other.md")); !os.IsNotExist(err) {
+			t.Errorf("expected the submodule outside the doc paths not to be checked out, got %v", err)
+		}
+
+		// A new submodule commit reloads the submodule's files
+		base, err := gs.HeadCommit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitFile(t, sharedWork, "more.md", "# More", "Bob", "2024-02-01T00:00:00Z")
+		runGit(t, sharedWork, "push", "origin", "HEAD")
+		runGit(t, filepath.Join(mainWork, "docs", "shared"), "pull", "--quiet")
+		runGit(t, mainWork, "commit", "-am", "update shared")
+		runGit(t, mainWork, "push", "origin", "HEAD")
+
+		gs.Cleanup()
+		gs, err = New(cfg)
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		defer gs.Cleanup()
 
-	workDir := filepath.Join(tmpDir, "work")
-	exec.Command("git", "clone", bareRepo, workDir).Run()
-	os.WriteFile(filepath.Join(workDir, "test.md"), []byte("# Test"), 0644)
-	exec.Command("git", "-C", workDir, "add", ".").Run()
-	exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
-	exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
-	exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run()
-	exec.Command("git", "-C", workDir, "push").Run()
+		changes, err := gs.Changes(base)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := filepath.Join(gs.repoPath, "docs", "shared", "more.md")
+		found := false
+		for _, p := range changes.Modified {
+			if p == want {
+				found = true
+			}
+			if strings.HasSuffix(p, ".git") {
+				t.Errorf("expected the submodule's .git file to be excluded, got %s", p)
+			}
+		}
+		if !found {
+			t.Errorf("expected %s in modified files, got %v", want, changes.Modified)
+		}
+	})
+}
 
-	cloneDir := filepath.Join(tmpDir, "clones")
-	cfg := &types.Config{
-		GitURL:       bareRepo,
-		GitCloneDir:  cloneDir,
-		GitKeepClone: true,
+func TestSameRepoURL(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"https://github.com/org/docs.git", "https://github.com/org/docs", true},
+		{"https://token@github.com/org/docs.git", "https://GitHub.com/org/docs/", true},
+		{"https://github.com/org/docs.git", "https://github.com/other/docs.git", false},
+		{"git@github.com:org/docs.git", "git@github.com:org/docs", true},
+		{"git@github.com:org/docs.git", "https://github.com/org/docs.git", false},
+		{"file:///srv/git/docs.git", "/srv/git/docs.git", true},
+		{"/srv/git/docs.git", "/srv/git/./docs.git/", true},
+		{"/srv/git/docs.git", "/srv/other/docs.git", false},
 	}
 
-	gs, err := New(cfg)
-	if err != nil {
-		t.Fatalf("failed to create GitSource: %v", err)
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := sameRepoURL(tt.a, tt.b); got != tt.expected {
+				t.Errorf("sameRepoURL(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
 	}
+}
 
-	sourcePaths := gs.GetSourcePaths()
-	if len(sourcePaths) == 0 {
-		t.Fatal("expected at least one source path")
+func TestGitSourceReuse(t *testing.T) {
+	// Skip if git is not available
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
 	}
-	sourcePath := sourcePaths[0]
 
-	// Cleanup should not remove the repo when GitKeepClone is true
-	gs.Cleanup()
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		bareRepo := filepath.Join(tmpDir, "test-repo.git")
+		runGit(t, tmpDir, "init", "--bare", bareRepo)
 
-	// Verify repo still exists
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		t.Error("source path should still exist when GitKeepClone is true")
-	}
+		workDir := filepath.Join(tmpDir, "work")
+		runGit(t, tmpDir, "clone", bareRepo, workDir)
+		commitFile(t, workDir, "index.md", "# Version 1", "Alice", "2024-01-01T00:00:00Z")
+		runGit(t, workDir, "tag", "v1")
+		commitFile(t, workDir, "index.md", "# Version 2", "Alice", "2024-02-01T00:00:00Z")
+		runGit(t, workDir, "tag", "v2")
+		runGit(t, workDir, "push", "origin", "HEAD", "--tags")
+
+		cfg := &types.Config{
+			GitBackend:  backend,
+			GitURL:      "file://" + bareRepo,
+			GitTag:      "v1",
+			GitCloneDir: filepath.Join(tmpDir, "clones"),
+		}
+
+		load := func(expected string) *GitSource {
+			t.Helper()
+			gs, err := New(cfg)
+			if err != nil {
+				t.Fatalf("failed to create GitSource: %v", err)
+			}
+			t.Cleanup(func() { gs.Cleanup() })
+			content, err := os.ReadFile(filepath.Join(gs.repoPath, "index.md"))
+			if err != nil {
+				t.Fatalf("failed to read index.md: %v", err)
+			}
+			if string(content) != expected {
+				t.Errorf("expected %q, got %q", expected, content)
+			}
+			return gs
+		}
+
+		gs := load("# Version 1")
+
+		// Local modifications and untracked files are discarded on reuse
+		os.WriteFile(filepath.Join(gs.repoPath, "index.md"), []byte("# Modified"), 0644)
+		os.WriteFile(filepath.Join(gs.repoPath, "stray.md"), []byte("# Stray"), 0644)
+		gs.Cleanup()
+
+		gs = load("# Version 1")
+		if _, err := os.Stat(filepath.Join(gs.repoPath, "stray.md")); !os.IsNotExist(err) {
+			t.Errorf("expected untracked files to be removed, got %v", err)
+		}
+		gs.Cleanup()
+
+		// The shallow clone can check out a tag it has not fetched before
+		cfg.GitTag = "v2"
+		gs = load("# Version 2")
+		gs.Cleanup()
+
+		// The default branch is updated from the remote
+		commitFile(t, workDir, "index.md", "# Version 3", "Alice", "2024-03-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD")
+		cfg.GitTag = ""
+		gs = load("# Version 3")
+		gs.Cleanup()
+
+		// A branch is checked out at the remote's commit
+		branch := runGit(t, workDir, "rev-parse", "--abbrev-ref", "HEAD")
+		commitFile(t, workDir, "index.md", "# Version 4", "Alice", "2024-04-01T00:00:00Z")
+		runGit(t, workDir, "push", "origin", "HEAD")
+		cfg.GitBranch = branch
+		gs = load("# Version 4")
+		gs.Cleanup()
+	})
 }
 
-func TestGitSourceSkipFetch(t *testing.T) {
+func TestGitSourceReuseOtherRepository(t *testing.T) {
 	// Skip if git is not available
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
 	}
 
-	tmpDir := t.TempDir()
-	bareRepo := filepath.Join(tmpDir, "test-repo.git")
-	exec.Command("git", "init", "--bare", bareRepo).Run()
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tmpDir := t.TempDir()
+		cloneDir := filepath.Join(tmpDir, "clones")
 
-	workDir := filepath.Join(tmpDir, "work")
-	exec.Command("git", "clone", bareRepo, workDir).Run()
-	os.WriteFile(filepath.Join(workDir, "test.md"), []byte("# Test"), 0644)
-	exec.Command("git", "-C", workDir, "add", ".").Run()
-	exec.Command("git", "-C", workDir, "config", "user.email", "test@test.com").Run()
-	exec.Command("git", "-C", workDir, "config", "user.name", "Test").Run()
-	exec.Command("git", "-C", workDir, "commit", "-m", "initial").Run()
-	exec.Command("git", "-C", workDir, "push").Run()
+		// Two repositories with the same name
+		var urls []string
+		for _, owner := range []string{"alice", "bob"} {
+			bareRepo := filepath.Join(tmpDir, owner, "docs.git")
+			runGit(t, tmpDir, "init", "--bare", bareRepo)
+			workDir := filepath.Join(tmpDir, owner+"-work")
+			runGit(t, tmpDir, "clone", bareRepo, workDir)
+			commitFile(t, workDir, "index.md", "# "+owner, "Alice", "2024-01-01T00:00:00Z")
+			runGit(t, workDir, "push", "origin", "HEAD")
+			urls = append(urls, "file://"+bareRepo)
+		}
 
-	cloneDir := filepath.Join(tmpDir, "clones")
+		gs, err := New(&types.Config{GitURL: urls[0], GitCloneDir: cloneDir, GitBackend: backend})
+		if err != nil {
+			t.Fatalf("failed to create GitSource: %v", err)
+		}
+		gs.Cleanup()
+
+		_, err = New(&types.Config{GitURL: urls[1], GitCloneDir: cloneDir, GitBackend: backend})
+		if err == nil || !strings.Contains(err.Error(), "not "+urls[1]) {
+			t.Fatalf("expected an origin mismatch error, got %v", err)
+		}
+
+		// The lock is released after a failed setup
+		if _, err := os.Stat(filepath.Join(cloneDir, "docs.lock")); !os.IsNotExist(err) {
+			t.Errorf("expected the lock file to be removed, got %v", err)
+		}
+	})
+}
 
-	// First clone
-	cfg := &types.Config{
-		GitURL:       bareRepo,
-		GitCloneDir:  cloneDir,
-		GitKeepClone: true,
+func TestAcquireLock(t *testing.T) {
+	oldTimeout, oldInterval := lockTimeout, lockPollInterval
+	lockTimeout, lockPollInterval = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { lockTimeout, lockPollInterval = oldTimeout, oldInterval }()
+
+	lockPath := filepath.Join(t.TempDir(), "repo.lock")
+	hostname, _ := os.Hostname()
+
+	// Held by another running process: times out
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start a process: %v", err)
 	}
-	gs1, err := New(cfg)
-	if err != nil {
-		t.Fatalf("failed to create first GitSource: %v", err)
+	defer cmd.Process.Kill()
+	os.WriteFile(lockPath, []byte(fmt.Sprintf("%s %d\n", hostname, cmd.Process.Pid)), 0644)
+	if _, err := acquireLock(lockPath); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
 	}
-	gs1.Cleanup()
 
-	// Second use with skip-fetch should reuse existing clone
-	cfg.GitSkipFetch = true
-	gs2, err := New(cfg)
+	// Held by a process that no longer exists: taken over
+	cmd.Process.Kill()
+	cmd.Wait()
+	unlock, err := acquireLock(lockPath)
 	if err != nil {
-		t.Fatalf("failed to create second GitSource: %v", err)
+		t.Fatalf("expected the stale lock to be taken over, got %v", err)
+	}
+
+	// Held by this process: fails at once
+	if _, err := acquireLock(lockPath); err == nil || !strings.Contains(err.Error(), "this run") {
+		t.Errorf("expected an error for a lock held by this run, got %v", err)
 	}
-	defer gs2.Cleanup()
 
-	// Should succeed without network call
-	sourcePaths := gs2.GetSourcePaths()
-	if len(sourcePaths) == 0 {
-		t.Fatal("expected at least one source path")
+	if err := unlock(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if _, err := os.Stat(sourcePaths[0]); os.IsNotExist(err) {
-		t.Error("source path should exist")
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
 	}
 }
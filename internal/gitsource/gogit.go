@@ -0,0 +1,713 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// goGitBackend implements Git operations in Go (go-git), so that git
+// sources can be loaded on hosts without the git command. Partial clones
+// are not supported, so file contents outside the doc paths are downloaded
+// (but not checked out), and Git LFS content cannot be fetched.
+type goGitBackend struct {
+	auth     transport.AuthMethod // Credentials for the repository (nil if none)
+	authHost string               // Host the credentials are sent to
+}
+
+// newGoGitBackend returns a go-git backend with the configured credentials
+func newGoGitBackend(cfg *types.Config) (*goGitBackend, error) {
+	b := &goGitBackend{}
+
+	switch {
+	case cfg.GitTokenEnv != "":
+		username, token, u, err := tokenCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		b.auth = &githttp.BasicAuth{Username: username, Password: token}
+		b.authHost = u.Host
+	case cfg.GitSSHKey != "":
+		if _, err := os.Stat(cfg.GitSSHKey); err != nil {
+			return nil, fmt.Errorf("SSH key %s: %w", cfg.GitSSHKey, err)
+		}
+		endpoint, err := transport.NewEndpoint(cfg.GitURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository URL: %w", err)
+		}
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+		auth, err := gitssh.NewPublicKeysFromFile(user, cfg.GitSSHKey, "")
+		if err != nil {
+			return nil, fmt.Errorf("SSH key %s: %w", cfg.GitSSHKey, err)
+		}
+		b.auth = auth
+		b.authHost = endpoint.Host
+	}
+
+	return b, nil
+}
+
+// authFor returns the credentials to use for a URL, so that they are only
+// ever sent to the repository's host (submodules may be hosted elsewhere)
+func (b *goGitBackend) authFor(rawURL string) transport.AuthMethod {
+	if b.auth == nil {
+		return nil
+	}
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil || !strings.EqualFold(endpoint.Host, b.authHost) {
+		return nil
+	}
+	return b.auth
+}
+
+// open opens the repository at path, which may be a worktree
+func (b *goGitBackend) open(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", path, err)
+	}
+	return repo, nil
+}
+
+// Clone clones the repository without checking it out, fetching only the
+// branch or tag if one is given
+func (b *goGitBackend) Clone(url, path string, opts cloneOptions) error {
+	cloneOpts := &git.CloneOptions{
+		URL:        url,
+		Auth:       b.authFor(url),
+		Depth:      opts.Depth,
+		NoCheckout: true,
+		Progress:   os.Stdout,
+	}
+	switch {
+	case opts.Branch != "":
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+		cloneOpts.SingleBranch = true
+	case opts.Tag != "":
+		cloneOpts.ReferenceName = plumbing.NewTagReferenceName(opts.Tag)
+		cloneOpts.SingleBranch = true
+	}
+
+	if _, err := git.PlainClone(path, false, cloneOpts); err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+	return nil
+}
+
+// OriginURL returns the URL of the origin remote
+func (b *goGitBackend) OriginURL(path string) (string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", err
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+	return remote.Config().URLs[0], nil
+}
+
+// SetOriginURL sets the URL of the origin remote
+func (b *goGitBackend) SetOriginURL(path, url string) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	remote, ok := cfg.Remotes[git.DefaultRemoteName]
+	if !ok {
+		return git.ErrRemoteNotFound
+	}
+	remote.URLs = []string{url}
+	return repo.SetConfig(cfg)
+}
+
+// Fetch fetches a branch, tag or the default branch from origin
+func (b *goGitBackend) Fetch(path string, opts fetchOptions) (string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", err
+	}
+
+	branch := opts.Branch
+	if branch == "" && opts.Tag == "" {
+		// Fetch the branch the remote HEAD points to
+		if branch, err = b.defaultBranch(repo); err != nil {
+			return "", err
+		}
+	}
+
+	var refSpec, rev string
+	if branch != "" {
+		refSpec = "+refs/heads/" + branch + ":refs/remotes/origin/" + branch
+		rev = "refs/remotes/origin/" + branch
+	} else {
+		refSpec = "+refs/tags/" + opts.Tag + ":refs/tags/" + opts.Tag
+		rev = "refs/tags/" + opts.Tag
+	}
+
+	depth := opts.Depth
+	if opts.Unshallow {
+		depth = math.MaxInt32
+	}
+	if err := b.fetch(repo, depth, refSpec); err != nil {
+		return "", fmt.Errorf("git fetch failed: %w", err)
+	}
+
+	return b.Resolve(path, rev)
+}
+
+// fetch fetches refspecs from origin, succeeding if nothing changed
+func (b *goGitBackend) fetch(repo *git.Repository, depth int, refSpecs ...string) error {
+	specs := make([]gitconfig.RefSpec, len(refSpecs))
+	for i, spec := range refSpecs {
+		specs[i] = gitconfig.RefSpec(spec)
+	}
+
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+
+	err = remote.Fetch(&git.FetchOptions{
+		RefSpecs: specs,
+		Depth:    depth,
+		Auth:     b.authFor(remote.Config().URLs[0]),
+		Progress: os.Stdout,
+		Tags:     git.NoTags,
+		Force:    true,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// defaultBranch returns the branch the remote HEAD points to
+func (b *goGitBackend) defaultBranch(repo *git.Repository) (string, error) {
+	refs, err := b.listRemote(repo)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("failed to find the default branch of origin")
+}
+
+// listRemote lists the refs on origin, with the peeled commits of
+// annotated tags
+func (b *goGitBackend) listRemote(repo *git.Repository) ([]*plumbing.Reference, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := remote.List(&git.ListOptions{
+		Auth:          b.authFor(remote.Config().URLs[0]),
+		PeelingOption: git.AppendPeeled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote refs: %w", err)
+	}
+	return refs, nil
+}
+
+// FetchCommit fetches a commit from origin. Servers may not allow fetching
+// a commit by SHA over the protocol go-git uses, so the ref that points to
+// it is fetched if known, and otherwise the full history of the branches
+// if fetching the commit fails.
+func (b *goGitBackend) FetchCommit(path, sha, ref string, depth int) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return b.fetch(repo, depth, "+"+ref+":refs/remotes/origin/"+strings.TrimPrefix(ref, "refs/heads/"))
+	case strings.HasPrefix(ref, "refs/tags/"):
+		return b.fetch(repo, depth, "+"+ref+":"+ref)
+	}
+
+	if err := b.fetch(repo, depth, sha+":refs/docloader/fetched"); err == nil {
+		return nil
+	}
+	return b.fetch(repo, math.MaxInt32, "+refs/heads/*:refs/remotes/origin/*")
+}
+
+// Resolve returns the commit a local revision points to
+func (b *goGitBackend) Resolve(path, rev string) (string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("%s not found in clone", rev)
+	}
+	return hash.String(), nil
+}
+
+// HasCommit returns true if the commit object exists in the clone
+func (b *goGitBackend) HasCommit(path, sha string) bool {
+	repo, err := b.open(path)
+	if err != nil {
+		return false
+	}
+	_, err = repo.CommitObject(plumbing.NewHash(sha))
+	return err == nil
+}
+
+// IsShallow returns true if the clone has truncated history
+func (b *goGitBackend) IsShallow(path string) bool {
+	repo, err := b.open(path)
+	if err != nil {
+		return false
+	}
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// RemoteRefs lists the branches and tags on origin
+func (b *goGitBackend) RemoteRefs(path string) (map[string]string, map[string]string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	refs, err := b.listRemote(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	branches := make(map[string]string)
+	tags := make(map[string]string)
+	for _, ref := range refs {
+		if ref.Type() == plumbing.HashReference {
+			addRemoteRef(branches, tags, ref.Name().String(), ref.Hash().String())
+		}
+	}
+
+	return branches, tags, nil
+}
+
+// Checkout checks out a commit. Unlike git clean, untracked files that are
+// ignored are left in place.
+func (b *goGitBackend) Checkout(path string, opts checkoutOptions) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+
+	checkoutOpts := &git.CheckoutOptions{
+		Hash:                      plumbing.NewHash(opts.Commit),
+		Force:                     true,
+		SparseCheckoutDirectories: opts.SparseDirs,
+	}
+	if opts.Branch != "" {
+		branch := plumbing.NewBranchReferenceName(opts.Branch)
+		ref := plumbing.NewHashReference(branch, checkoutOpts.Hash)
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+		checkoutOpts.Hash = plumbing.ZeroHash
+		checkoutOpts.Branch = branch
+	}
+
+	return b.checkout(repo, plumbing.NewHash(opts.Commit), checkoutOpts)
+}
+
+// checkout checks out a commit in a worktree and removes untracked files
+func (b *goGitBackend) checkout(repo *git.Repository, commit plumbing.Hash, opts *git.CheckoutOptions) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+
+	// go-git only ever adds files to a sparse checkout, so start from a
+	// full one
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	for _, e := range idx.Entries {
+		e.SkipWorktree = false
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	if len(opts.SparseCheckoutDirectories) > 0 {
+		if opts.SparseCheckoutDirectories, err = sparsePatterns(repo, commit, opts.SparseCheckoutDirectories); err != nil {
+			return fmt.Errorf("git checkout failed: %w", err)
+		}
+	}
+
+	if err := wt.Checkout(opts); err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	if err := wt.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("git clean failed: %w", err)
+	}
+	return nil
+}
+
+// sparsePatterns returns the path prefixes to check out for the given
+// directories, matching git's cone mode: files at the root of the commit's
+// tree (such as .gitmodules) are always checked out
+func sparsePatterns(repo *git.Repository, commit plumbing.Hash, dirs []string) ([]string, error) {
+	var patterns []string
+	for _, dir := range dirs {
+		// go-git matches by prefix, so end directories with a slash
+		patterns = append(patterns, strings.TrimSuffix(dir, "/")+"/")
+	}
+
+	c, err := repo.CommitObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range tree.Entries {
+		if entry.Mode.IsFile() {
+			patterns = append(patterns, entry.Name)
+		}
+	}
+
+	return patterns, nil
+}
+
+// PruneWorktrees forgets worktrees whose directories have been removed
+func (b *goGitBackend) PruneWorktrees(path string) error {
+	entries, err := os.ReadDir(filepath.Join(path, ".git", "worktrees"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("git worktree prune failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(path, ".git", "worktrees", entry.Name())
+		gitdir, err := os.ReadFile(filepath.Join(dir, "gitdir"))
+		if err == nil {
+			if _, err = os.Stat(strings.TrimSpace(string(gitdir))); err == nil {
+				continue
+			}
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("git worktree prune failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CheckoutWorktree checks out a commit in a worktree, adding the worktree
+// if it does not exist. go-git cannot add worktrees, so they are created
+// in the same layout as git worktree add, and can also be used with git.
+func (b *goGitBackend) CheckoutWorktree(path, wtPath string, opts checkoutOptions) error {
+	if _, err := os.Stat(filepath.Join(wtPath, ".git")); err != nil {
+		if err := addWorktree(path, wtPath, opts.Commit); err != nil {
+			return err
+		}
+	}
+
+	repo, err := b.open(wtPath)
+	if err != nil {
+		return err
+	}
+
+	return b.checkout(repo, plumbing.NewHash(opts.Commit), &git.CheckoutOptions{
+		Hash:                      plumbing.NewHash(opts.Commit),
+		Force:                     true,
+		SparseCheckoutDirectories: opts.SparseDirs,
+	})
+}
+
+// addWorktree registers a worktree of the clone at path, with its HEAD
+// detached at a commit
+func addWorktree(path, wtPath, commit string) error {
+	absPath, err := filepath.Abs(wtPath)
+	if err != nil {
+		return err
+	}
+
+	gitDir := filepath.Join(path, ".git", "worktrees", filepath.Base(absPath))
+	absGitDir, err := filepath.Abs(gitDir)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{filepath.Join(gitDir, "gitdir"), filepath.Join(absPath, ".git")},
+		{filepath.Join(gitDir, "commondir"), "../.."},
+		{filepath.Join(gitDir, "HEAD"), commit},
+		{filepath.Join(absPath, ".git"), "gitdir: " + absGitDir},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.name), 0755); err != nil {
+			return fmt.Errorf("failed to add worktree %s: %w", wtPath, err)
+		}
+		if err := os.WriteFile(f.name, []byte(f.content+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to add worktree %s: %w", wtPath, err)
+		}
+	}
+
+	return nil
+}
+
+// HeadCommit returns the SHA of the commit currently checked out
+func (b *goGitBackend) HeadCommit(path string) (string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// FileHistory walks the history newest commit first, as git log does,
+// attributing the files within dirs that each commit changed from its
+// parent. Merge commits are skipped, and the oldest commit of a shallow
+// clone is taken to have added all of its files.
+func (b *goGitBackend) FileHistory(path string, dirs []string) (map[string]*types.FileMetadata, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	// go-git's log fails at the end of a shallow history, so the commits
+	// are collected here and ordered by commit time
+	var commits []*object.Commit
+	seen := make(map[plumbing.Hash]bool)
+	pending := []plumbing.Hash{head.Hash()}
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		c, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("git log failed: %w", err)
+		}
+		commits = append(commits, c)
+		pending = append(pending, c.ParentHashes...)
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Committer.When.After(commits[j].Committer.When)
+	})
+
+	history := newFileHistory(path)
+	for _, c := range commits {
+		if c.NumParents() > 1 {
+			continue
+		}
+		files, err := b.changedFiles(repo, c)
+		if err != nil {
+			return nil, fmt.Errorf("git log failed: %w", err)
+		}
+		for _, file := range files {
+			if inDirs(file, dirs) {
+				history.add(file, c.Hash.String(), c.Author.Name, c.Author.When.UTC())
+			}
+		}
+	}
+
+	return history.metadata, nil
+}
+
+// changedFiles returns the files a commit changed from its parent, or all
+// of its files if the parent is not available
+func (b *goGitBackend) changedFiles(repo *git.Repository, c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() == 1 {
+		if parent, err := repo.CommitObject(c.ParentHashes[0]); err == nil {
+			if parentTree, err = parent.Tree(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var files []string
+	if parentTree == nil {
+		err = tree.Files().ForEach(func(f *object.File) error {
+			files = append(files, f.Name)
+			return nil
+		})
+		return files, err
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.To.Name != "" {
+			files = append(files, change.To.Name)
+		} else {
+			files = append(files, change.From.Name)
+		}
+	}
+	return files, nil
+}
+
+// Diff lists the files changed between a commit and HEAD, detecting renames
+func (b *goGitBackend) Diff(path, since string) (*Changes, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make([]*object.Tree, 2)
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	for i, hash := range []plumbing.Hash{plumbing.NewHash(since), head.Hash()} {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCommitNotFound, hash)
+		}
+		if trees[i], err = commit.Tree(); err != nil {
+			return nil, fmt.Errorf("git diff failed: %w", err)
+		}
+	}
+
+	diff, err := object.DiffTreeWithOptions(context.Background(), trees[0], trees[1], object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	changes := &Changes{Renamed: make(map[string]string)}
+	join := func(p string) string {
+		return filepath.Join(path, filepath.FromSlash(p))
+	}
+	for _, change := range diff {
+		switch {
+		case change.To.Name == "":
+			changes.Deleted = append(changes.Deleted, join(change.From.Name))
+		case change.From.Name != "" && change.From.Name != change.To.Name:
+			changes.Renamed[join(change.From.Name)] = join(change.To.Name)
+			changes.Modified = append(changes.Modified, join(change.To.Name))
+		default:
+			changes.Modified = append(changes.Modified, join(change.To.Name))
+		}
+	}
+
+	return changes, nil
+}
+
+// UpdateSubmodules recursively initializes and updates submodules
+func (b *goGitBackend) UpdateSubmodules(path string, paths []string, depth int) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	submodules, err := wt.Submodules()
+	if err != nil {
+		return fmt.Errorf("failed to read submodules: %w", err)
+	}
+
+	for _, sub := range submodules {
+		if !slices.Contains(paths, sub.Config().Path) {
+			continue
+		}
+		err := sub.Update(&git.SubmoduleUpdateOptions{
+			Init:              true,
+			RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+			Depth:             depth,
+			Auth:              b.authFor(resolveSubmoduleURL(repo, sub.Config().URL)),
+		})
+		if err != nil {
+			return fmt.Errorf("git submodule update of %s failed: %w", sub.Config().Path, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveSubmoduleURL resolves a submodule URL relative to the origin URL
+func resolveSubmoduleURL(repo *git.Repository, subURL string) string {
+	if !strings.HasPrefix(subURL, "./") && !strings.HasPrefix(subURL, "../") {
+		return subURL
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return subURL
+	}
+	base, err := url.Parse(strings.TrimSuffix(remote.Config().URLs[0], "/") + "/")
+	if err != nil {
+		return subURL
+	}
+	rel, err := url.Parse(subURL)
+	if err != nil {
+		return subURL
+	}
+	return base.ResolveReference(rel).String()
+}
+
+// FetchLFS fails: go-git does not support Git LFS
+func (b *goGitBackend) FetchLFS(string, []string, bool) error {
+	return fmt.Errorf("Git LFS content cannot be fetched with the %s backend: use --git-backend %s or --git-lfs %s",
+		BackendGoGit, BackendExec, LFSSkip)
+}
@@ -0,0 +1,120 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// lockTimeout is how long to wait for another run to release a clone
+var lockTimeout = 10 * time.Minute
+
+// lockPollInterval is how often a held lock is checked while waiting
+var lockPollInterval = time.Second
+
+// acquireLock creates a lock file, waiting while another process holds it.
+// The file records the holder's host and process ID, so that a lock left
+// behind by a process that no longer exists can be taken over. It returns
+// a function that releases the lock.
+func acquireLock(path string) (func() error, error) {
+	hostname, _ := os.Hostname() //nolint:errcheck // an unknown host never matches
+	owner := fmt.Sprintf("%s %d\n", hostname, os.Getpid())
+
+	deadline := time.Now().Add(lockTimeout)
+	waiting := false
+
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(owner)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+			}
+			return func() error {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove lock file %s: %w", path, err)
+				}
+				return nil
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", path, err)
+		}
+
+		holder, stale := lockHolder(path, hostname)
+		if holder == fmt.Sprintf("process %d on %s", os.Getpid(), hostname) {
+			return nil, fmt.Errorf("%s is already in use by this run: give each repository a different name", path)
+		}
+		if stale {
+			fmt.Printf("Removing stale lock file: %s\n", path)
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove stale lock file %s: %w", path, err)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock file %s held by %s; "+
+				"remove it if no other run is using the clone", path, holder)
+		}
+		if !waiting {
+			fmt.Printf("Waiting for another run (%s) to release %s\n", holder, path)
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockHolder describes the holder of a lock file, and returns true if the
+// lock is stale: held by a process on this host that no longer exists
+func lockHolder(path, hostname string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Released (or being written) since the lock was attempted
+		return "unknown process", os.IsNotExist(err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return "unknown process", false
+	}
+	pid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "unknown process", false
+	}
+
+	holder := fmt.Sprintf("process %d on %s", pid, fields[0])
+	return holder, fields[0] == hostname && !processExists(pid)
+}
+
+// processExists returns true if a process with the given ID is running
+func processExists(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess fails on Windows if the process does not exist
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
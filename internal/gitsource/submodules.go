@@ -0,0 +1,159 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package gitsource
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+)
+
+// Git LFS modes (see Config.GitLFS)
+const (
+	LFSSkip  = "skip"  // Leave LFS pointer files in place; the processor skips them
+	LFSFetch = "fetch" // Download LFS content for the doc paths
+)
+
+// populate completes a checkout: it initializes the submodules within the
+// doc paths and downloads Git LFS content, if configured
+func (gs *GitSource) populate() error {
+	if gs.config.GitSubmodules {
+		if err := gs.updateSubmodules(); err != nil {
+			return err
+		}
+	}
+
+	if gs.config.GitLFS == LFSFetch {
+		if err := gs.fetchLFS(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSubmodules recursively initializes and updates the submodules that
+// contain, or are contained in, the doc paths
+func (gs *GitSource) updateSubmodules() error {
+	paths, err := gs.submodulePaths()
+	if err != nil {
+		return err
+	}
+
+	var inScope []string
+	for _, subPath := range paths {
+		if submoduleInScope(subPath, gs.config.GitDocPath) {
+			inScope = append(inScope, subPath)
+		}
+	}
+	if len(inScope) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Updating submodules: %s\n", strings.Join(inScope, ", "))
+
+	depth := 0
+	if !gs.config.GitFullHistory {
+		depth = 1
+	}
+
+	return gs.git.UpdateSubmodules(gs.repoPath, inScope, depth)
+}
+
+// submodulePaths returns the paths of the submodules listed in .gitmodules
+func (gs *GitSource) submodulePaths() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(gs.repoPath, ".gitmodules"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	modules := gitconfig.NewModules()
+	if err := modules.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	var paths []string
+	for _, sub := range modules.Submodules {
+		if sub.Path != "" {
+			paths = append(paths, sub.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// submoduleInScope returns true if files under the doc paths may come from
+// the submodule: the submodule is within a doc path, or a doc path is
+// within the submodule. All submodules are in scope without doc paths.
+func submoduleInScope(subPath string, docPaths []string) bool {
+	if len(docPaths) == 0 {
+		return true
+	}
+	dirs := sparseDirs(docPaths)
+	if dirs == nil {
+		// A doc path at the root of the repository
+		return true
+	}
+
+	subPath = path.Clean(subPath)
+	for _, dir := range dirs {
+		if subPath == dir || strings.HasPrefix(subPath, dir+"/") || strings.HasPrefix(dir, subPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchLFS downloads the Git LFS content of files under the doc paths,
+// including those in submodules
+func (gs *GitSource) fetchLFS() error {
+	fmt.Println("Fetching Git LFS content")
+
+	return gs.git.FetchLFS(gs.repoPath, sparseDirs(gs.config.GitDocPath), gs.config.GitSubmodules)
+}
+
+// expandSubmodules replaces each modified path that is a submodule
+// directory with the files it contains, so that a change of submodule
+// commit reloads its files
+func expandSubmodules(paths []string) ([]string, error) {
+	var expanded []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(file string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && d.Name() != ".git" {
+				expanded = append(expanded, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read submodule %s: %w", p, err)
+		}
+	}
+	return expanded, nil
+}
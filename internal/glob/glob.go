@@ -0,0 +1,256 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// HasMeta returns true if a path contains any of the glob pattern
+// characters, and so is a pattern rather than a literal path
+func HasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[{")
+}
+
+// Match reports whether a slash-separated path matches a pattern. The
+// pattern is matched against the whole path, segment by segment:
+//
+//   - *, ? and [...] character classes match within a segment, as with
+//     path.Match
+//   - a ** segment matches any number of segments, including none
+//   - {a,b,...} matches any of the comma-separated alternatives, which may
+//     themselves contain wildcards, slashes or further braces
+func Match(pattern, name string) bool {
+	names := strings.Split(name, "/")
+	for _, alternative := range Expand(pattern) {
+		if matchSegments(strings.Split(alternative, "/"), names) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDir reports whether files within a directory (a slash-separated
+// path) could match a pattern, so that a directory walk can skip those that
+// cannot
+func MatchDir(pattern, dir string) bool {
+	if dir == "." || dir == "" {
+		return true
+	}
+	dirs := strings.Split(dir, "/")
+	for _, alternative := range Expand(pattern) {
+		if matchPrefix(strings.Split(alternative, "/"), dirs) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that a pattern is well formed
+func Validate(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if braceDepth(pattern) != 0 {
+		return fmt.Errorf("invalid pattern '%s': unbalanced braces", pattern)
+	}
+	for _, alternative := range Expand(pattern) {
+		for _, segment := range strings.Split(alternative, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Split divides a slash-separated pattern into the directory before its
+// first segment containing a wildcard (or "." if there is none) and the
+// rest of the pattern, which is relative to that directory
+func Split(pattern string) (base, rest string) {
+	segments := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segments) && !HasMeta(segments[i]) {
+		i++
+	}
+
+	base = strings.Join(segments[:i], "/")
+	rest = strings.Join(segments[i:], "/")
+	switch {
+	case base == "" && strings.HasPrefix(pattern, "/"):
+		base = "/"
+	case base == "":
+		base = "."
+	}
+	return base, rest
+}
+
+// Expand returns the alternatives of a pattern's braces, so that
+// "*.{md,rst}" gives "*.md" and "*.rst". A pattern without braces is its
+// only alternative; braces without a comma are literal.
+func Expand(pattern string) []string {
+	start, end, parts := firstBraces(pattern)
+	if start < 0 {
+		return []string{pattern}
+	}
+
+	var expanded []string
+	for _, part := range parts {
+		expanded = append(expanded, Expand(pattern[:start]+part+pattern[end+1:])...)
+	}
+	return expanded
+}
+
+// firstBraces finds the first pair of braces holding alternatives, returning
+// their positions and the alternatives, or -1 if there are none
+func firstBraces(pattern string) (int, int, []string) {
+	for start := 0; start < len(pattern); start++ {
+		switch pattern[start] {
+		case '\\':
+			start++
+		case '[':
+			start = classEnd(pattern, start)
+		case '{':
+			if end, parts := alternatives(pattern, start); end >= 0 {
+				return start, end, parts
+			}
+		}
+	}
+	return -1, -1, nil
+}
+
+// alternatives returns the position of the brace closing the one at start,
+// and the comma-separated alternatives between them; or -1 if the brace is
+// not closed or holds no comma
+func alternatives(pattern string, start int) (int, []string) {
+	depth := 0
+	partStart := start + 1
+	var parts []string
+
+	for i := start + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = classEnd(pattern, i)
+		case '{':
+			depth++
+		case ',':
+			if depth == 0 {
+				parts = append(parts, pattern[partStart:i])
+				partStart = i + 1
+			}
+		case '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if parts == nil {
+				return -1, nil
+			}
+			return i, append(parts, pattern[partStart:i])
+		}
+	}
+	return -1, nil
+}
+
+// classEnd returns the position of the ] closing a character class that
+// starts at i, or i if the class is not closed
+func classEnd(pattern string, i int) int {
+	j := i + 1
+	if j < len(pattern) && pattern[j] == '^' {
+		j++
+	}
+	if j < len(pattern) && pattern[j] == ']' {
+		j++
+	}
+	for ; j < len(pattern); j++ {
+		switch pattern[j] {
+		case '\\':
+			j++
+		case ']':
+			return j
+		}
+	}
+	return i
+}
+
+// braceDepth returns the number of braces left open at the end of a
+// pattern, or -1 if a brace is closed that was never opened
+func braceDepth(pattern string) int {
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = classEnd(pattern, i)
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return -1
+			}
+			depth--
+		}
+	}
+	return depth
+}
+
+// matchSegments reports whether the segments of a path match those of a
+// pattern without braces
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated ** segments, then try every split point
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchPrefix reports whether the leading segments of a pattern match a
+// directory, leaving at least one segment to match the files within it
+func matchPrefix(pattern, dir []string) bool {
+	for len(dir) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if matched, err := path.Match(pattern[0], dir[0]); err != nil || !matched {
+			return false
+		}
+		pattern, dir = pattern[1:], dir[1:]
+	}
+	return len(pattern) > 0
+}
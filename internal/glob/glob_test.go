@@ -0,0 +1,143 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package glob
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.md", "guide.md", true},
+		{"*.md", "docs/guide.md", false},
+		{"docs/*.md", "docs/guide.md", true},
+		{"**/*.md", "guide.md", true},
+		{"**/*.md", "docs/v1/guide.md", true},
+		{"docs/**", "docs/v1/guide.md", true},
+		{"docs/**/reference/*.md", "docs/reference/api.md", true},
+		{"docs/**/reference/*.md", "docs/v1/x/reference/api.md", true},
+		{"docs/**/reference/*.md", "docs/v1/api.md", false},
+		{"docs/**/reference/*.md", "docs/reference/v1/api.md", false},
+		{"a/**/b/**/*.html", "a/b/index.html", true},
+		{"a/**/b/**/*.html", "a/x/b/y/z/index.html", true},
+		{"a/**/b/**/*.html", "a/x/y/index.html", false},
+		{"**/*.{md,rst}", "docs/intro.rst", true},
+		{"**/*.{md,rst}", "docs/intro.txt", false},
+		{"{docs,guide}/**/*.md", "guide/a/b.md", true},
+		{"{docs,guide}/**/*.md", "other/b.md", false},
+		{"docs/{v1,v{2,3}}/*.md", "docs/v3/a.md", true},
+		{"docs/{*.md,img/*.png}", "docs/img/logo.png", true},
+		{"docs/{literal}.md", "docs/{literal}.md", true},
+		{"docs/[a-c]*.md", "docs/b.md", true},
+		{"docs/[a-c]*.md", "docs/d.md", false},
+		{"docs/[!a-c]*.md", "docs/d.md", false},
+		{"docs/[^a-c]*.md", "docs/d.md", true},
+		{"docs/[{,]x.md", "docs/,x.md", true},
+		{"docs/?.md", "docs/a.md", true},
+		{"docs/?.md", "docs/ab.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.expected {
+			t.Errorf("Match(%q, %q) = %v, expected %v", tt.pattern, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestMatchDir(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		dir      string
+		expected bool
+	}{
+		{"*.md", ".", true},
+		{"*.md", "docs", false},
+		{"docs/*.md", "docs", true},
+		{"docs/*.md", "docs/v1", false},
+		{"docs/**/*.md", "docs/v1/x", true},
+		{"{docs,guide}/*.md", "guide", true},
+		{"{docs,guide}/*.md", "other", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchDir(tt.pattern, tt.dir); got != tt.expected {
+			t.Errorf("MatchDir(%q, %q) = %v, expected %v", tt.pattern, tt.dir, got, tt.expected)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"*.md", "*.md"},
+		{"*.{md,rst}", "*.md *.rst"},
+		{"{a,b}/{c,d}", "a/c a/d b/c b/d"},
+		{"v{1,{2,3}}", "v1 v2 v3"},
+		{"{a}", "{a}"},
+		{`\{a,b}`, `\{a,b}`},
+		{"x{,.bak}", "x x.bak"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(Expand(tt.pattern), " "); got != tt.expected {
+			t.Errorf("Expand(%q) = %s, expected %s", tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"**/*.{md,rst}", false},
+		{"docs/[a-z]*.md", false},
+		{"", true},
+		{"docs/[a-", true},
+		{"*.{md,rst", true},
+		{"*.md}", true},
+		{"{docs,[a-}/*.md", true},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		pattern string
+		base    string
+		rest    string
+	}{
+		{"docs/**/*.md", "docs", "**/*.md"},
+		{"/srv/docs/*.md", "/srv/docs", "*.md"},
+		{"/*.md", "/", "*.md"},
+		{"*.md", ".", "*.md"},
+		{"docs/{a,b}/*.md", "docs", "{a,b}/*.md"},
+		{"docs/v1", "docs/v1", ""},
+	}
+
+	for _, tt := range tests {
+		base, rest := Split(tt.pattern)
+		if base != tt.base || rest != tt.rest {
+			t.Errorf("Split(%q) = %q, %q, expected %q, %q", tt.pattern, base, rest, tt.base, tt.rest)
+		}
+	}
+}
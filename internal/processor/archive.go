@@ -0,0 +1,263 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// Limits on the contents of an archive, so that a small archive cannot
+// expand into more data than the loader can hold (a decompression bomb)
+var (
+	maxArchiveEntries         = 100000     // Entries of any kind
+	maxArchiveFileSize  int64 = 64 << 20   // Uncompressed size of one file
+	maxArchiveTotalSize int64 = 1024 << 20 // Uncompressed size of all files read
+)
+
+// ErrArchiveLimit is returned when an archive exceeds the entry count or
+// total size limits
+var ErrArchiveLimit = errors.New("archive exceeds limits")
+
+// archiveExtensions are the file extensions of supported archives
+var archiveExtensions = []string{".tar", ".tar.gz", ".tgz", ".zip"}
+
+// IsArchive returns true if the file is an archive whose members can be
+// loaded
+func IsArchive(filename string) bool {
+	lower := strings.ToLower(filename)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveEntry is a member of an archive being read
+type archiveEntry struct {
+	name    string    // Path within the archive, as stored
+	regular bool      // Regular file (not a link or device)
+	modTime time.Time // Modification time
+	open    func() (io.ReadCloser, error)
+}
+
+// ProcessArchive processes the supported files in a tar (optionally
+// gzipped) or zip archive without extracting it. Members are named by
+// their path within the archive and take their modification time from the
+// archive. Members with absolute paths or paths outside the archive are
+// rejected, as are files larger than the size limits.
+func ProcessArchive(archivePath string, opts Options) ([]*types.Document, *types.Stats, error) {
+	stats := &types.Stats{}
+	var documents []*types.Document
+
+	// Paths within the archive do not match source-supplied metadata
+	opts.FileMetadata = nil
+
+	entries := 0
+	var total int64
+	skip := func(format string, args ...interface{}) {
+		err := fmt.Errorf(format, args...)
+		fmt.Printf("Skipping archive member: %v\n", err)
+		stats.AddError(fmt.Errorf("archive %s: %w", archivePath, err))
+		stats.FilesSkipped++
+	}
+
+	err := walkArchive(archivePath, func(entry archiveEntry) error {
+		entries++
+		if entries > maxArchiveEntries {
+			return fmt.Errorf("%w: more than %d entries", ErrArchiveLimit, maxArchiveEntries)
+		}
+
+		name, err := memberPath(entry.name)
+		if err != nil {
+			skip("%s: %w", entry.name, err)
+			return nil
+		}
+		if !entry.regular {
+			fmt.Printf("Skipping archive member that is not a regular file: %s\n", name)
+			stats.FilesSkipped++
+			return nil
+		}
+		if !opts.Registry().IsSupported(name) {
+			fmt.Printf("Skipping unsupported file: %s\n", name)
+			stats.FilesSkipped++
+			return nil
+		}
+
+		content, err := readEntry(entry)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		total += int64(len(content))
+		if total > maxArchiveTotalSize {
+			return fmt.Errorf("%w: more than %d bytes uncompressed", ErrArchiveLimit, maxArchiveTotalSize)
+		}
+		if int64(len(content)) > maxArchiveFileSize {
+			skip("%s: larger than %d bytes uncompressed", name, maxArchiveFileSize)
+			return nil
+		}
+
+		modTime := entry.modTime
+		doc, err := processContent(name, content, &modTime, opts)
+		if errors.Is(err, ErrLFSPointer) {
+			fmt.Printf("Skipping Git LFS pointer file: %s\n", name)
+			stats.FilesSkipped++
+			stats.LFSPointers++
+			return nil
+		}
+		if errors.Is(err, converter.ErrUnsupportedFormat) {
+			fmt.Printf("Skipping unsupported file: %s\n", name)
+			stats.FilesSkipped++
+			return nil
+		}
+		if err != nil {
+			fmt.Printf("Error processing file %s: %v\n", name, err)
+			stats.AddError(fmt.Errorf("archive %s: file %s: %w", archivePath, name, err))
+			stats.FilesSkipped++
+			return nil
+		}
+
+		documents = append(documents, doc)
+		stats.FilesProcessed++
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+
+	return documents, stats, nil
+}
+
+// walkArchive calls fn for each entry of an archive, in archive order
+func walkArchive(archivePath string, fn func(archiveEntry) error) error {
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".zip") {
+		return walkZip(archivePath, fn)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return walkTar(r, fn)
+}
+
+// walkTar reads the entries of a tar stream
+func walkTar(r io.Reader, fn func(archiveEntry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		// The member's content is read from the stream before the next
+		// header
+		err = fn(archiveEntry{
+			name:    header.Name,
+			regular: header.Typeflag == tar.TypeReg,
+			modTime: header.ModTime,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(tr), nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip reads the entries of a zip file
+func walkZip(archivePath string, fn func(archiveEntry) error) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		err := fn(archiveEntry{
+			name:    f.Name,
+			regular: f.Mode().IsRegular(),
+			modTime: f.Modified,
+			open:    f.Open,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readEntry reads the content of an archive member, up to one byte more
+// than the file size limit so that larger files can be detected without
+// being read in full
+func readEntry(entry archiveEntry) ([]byte, error) {
+	r, err := entry.open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, maxArchiveFileSize+1))
+}
+
+// memberPath returns the cleaned, slash-separated path of an archive
+// member, or an error if the path is absolute or leads outside the archive
+func memberPath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if path.IsAbs(name) || (len(name) >= 2 && name[1] == ':') {
+		return "", fmt.Errorf("absolute path in archive")
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("path leads outside the archive")
+	}
+	if cleaned == "." {
+		return "", fmt.Errorf("empty path in archive")
+	}
+
+	return cleaned, nil
+}
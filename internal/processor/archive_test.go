@@ -0,0 +1,201 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// archiveMember is a file to write to a test archive
+type archiveMember struct {
+	name    string
+	content string
+}
+
+var archiveTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// writeTarGz writes a gzipped tar archive of the members
+func writeTarGz(t *testing.T, archivePath string, members []archiveMember) {
+	t.Helper()
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, m := range members {
+		header := &tar.Header{
+			Name:     m.name,
+			Mode:     0644,
+			Size:     int64(len(m.content)),
+			ModTime:  archiveTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeZip writes a zip archive of the members
+func writeZip(t *testing.T, archivePath string, members []archiveMember) {
+	t.Helper()
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, m := range members {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, Method: zip.Deflate, Modified: archiveTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(m.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessArchive(t *testing.T) {
+	members := []archiveMember{
+		{"site/index.html", "<html><head><title>Home</title></head><body><p>Welcome</p></body></html>"},
+		{"./site/guide/intro.md", "# Intro\n\nContent"},
+		{"site/logo.png", "PNG"},
+		{"../outside.md", "# Outside"},
+		{"/etc/absolute.md", "# Absolute"},
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		write func(*testing.T, string, []archiveMember)
+	}{
+		{"tar.gz", "docs.tar.gz", writeTarGz},
+		{"tgz", "docs.tgz", writeTarGz},
+		{"zip", "docs.zip", writeZip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(), tt.file)
+			tt.write(t, archivePath, members)
+
+			docs, stats, err := ProcessFiles(archivePath, Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.FilesProcessed != 2 || stats.FilesSkipped != 3 {
+				t.Errorf("expected 2 processed and 3 skipped, got %d and %d", stats.FilesProcessed, stats.FilesSkipped)
+			}
+			// The traversal and absolute paths are reported
+			if len(stats.Errors) != 2 {
+				t.Errorf("expected 2 errors, got %v", stats.Errors)
+			}
+
+			names := make(map[string]bool)
+			for _, doc := range docs {
+				names[doc.FileName] = true
+				if doc.FileModified == nil || !doc.FileModified.Equal(archiveTime) {
+					t.Errorf("%s: expected modified %v, got %v", doc.FileName, archiveTime, doc.FileModified)
+				}
+			}
+			if !names["site/index.html"] || !names["site/guide/intro.md"] {
+				t.Errorf("expected members named by archive path, got %v", names)
+			}
+		})
+	}
+}
+
+func TestProcessArchiveLimits(t *testing.T) {
+	oldFile, oldTotal, oldEntries := maxArchiveFileSize, maxArchiveTotalSize, maxArchiveEntries
+	defer func() { maxArchiveFileSize, maxArchiveTotalSize, maxArchiveEntries = oldFile, oldTotal, oldEntries }()
+	maxArchiveFileSize, maxArchiveTotalSize, maxArchiveEntries = 100, 250, 10
+
+	// Highly compressible content, as in a decompression bomb
+	big := "# Big\n\n" + strings.Repeat("a", 200)
+	small := "# Small\n\n" + strings.Repeat("b", 80)
+	archivePath := filepath.Join(t.TempDir(), "docs.zip")
+
+	// A file over the size limit is skipped
+	writeZip(t, archivePath, []archiveMember{{"big.md", big}, {"small.md", small}})
+	docs, stats, err := ProcessArchive(archivePath, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 1 || docs[0].FileName != "small.md" || len(stats.Errors) != 1 {
+		t.Errorf("expected only small.md loaded and one error, got %d documents, errors %v", len(docs), stats.Errors)
+	}
+
+	// An archive over the total size limit is rejected
+	writeZip(t, archivePath, []archiveMember{{"a.md", small}, {"b.md", small}, {"c.md", small}})
+	if _, _, err := ProcessArchive(archivePath, Options{}); !errors.Is(err, ErrArchiveLimit) {
+		t.Errorf("expected ErrArchiveLimit for total size, got %v", err)
+	}
+
+	// As is one with too many entries
+	var many []archiveMember
+	for i := 0; i < 11; i++ {
+		many = append(many, archiveMember{string(rune('a'+i)) + ".txt", "x"})
+	}
+	writeTarGz(t, filepath.Join(filepath.Dir(archivePath), "many.tar.gz"), many)
+	if _, _, err := ProcessArchive(filepath.Join(filepath.Dir(archivePath), "many.tar.gz"), Options{}); !errors.Is(err, ErrArchiveLimit) {
+		t.Errorf("expected ErrArchiveLimit for entry count, got %v", err)
+	}
+}
+
+func TestMemberPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{"docs/index.md", "docs/index.md", false},
+		{"./docs/../docs/index.md", "docs/index.md", false},
+		{"docs\\win\\index.md", "docs/win/index.md", false},
+		{"../index.md", "", true},
+		{"docs/../../index.md", "", true},
+		{"/etc/passwd", "", true},
+		{"C:\\docs\\index.md", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := memberPath(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("memberPath(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("memberPath(%q) = %q, expected %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
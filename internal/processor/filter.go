@@ -0,0 +1,315 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/glob"
+)
+
+// IgnoreFileNames are the files whose patterns exclude files in their
+// directory and below, in the order they are read
+var IgnoreFileNames = []string{".gitignore", ".docloaderignore"}
+
+// rule is a pattern from an ignore file or an include or exclude option,
+// with gitignore semantics: a pattern without a slash matches a name at any
+// depth, one containing a slash matches the path from the rule's
+// directory, and one ending in a slash matches only directories
+type rule struct {
+	dir      string // Directory the rule applies in, relative to the root ("" for the root)
+	pattern  string
+	negate   bool // Re-include paths matched by an earlier rule
+	dirOnly  bool
+	anchored bool
+}
+
+// parseRule parses a pattern applying in dir, returning false for blank
+// lines and comments
+func parseRule(line, dir string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{dir: dir}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// \# and \! escape a leading # or !
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+	r.pattern = line
+	return r, true
+}
+
+// matches reports whether the rule matches a slash-separated path relative
+// to the root
+func (r rule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.dir != "" {
+		var ok bool
+		if rel, ok = strings.CutPrefix(rel, r.dir+"/"); !ok {
+			return false
+		}
+	}
+	if !r.anchored {
+		return glob.Match(r.pattern, path.Base(rel))
+	}
+	return glob.Match(r.pattern, rel)
+}
+
+// parsePatterns parses include or exclude patterns, which apply from the
+// root of a source
+func parsePatterns(patterns []string) []rule {
+	var rules []rule
+	for _, pattern := range patterns {
+		if r, ok := parseRule(pattern, ""); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// filter decides which of the files found under a source root are loaded,
+// applying the include and exclude patterns and the ignore files found in
+// the root and its subdirectories
+type filter struct {
+	root           string
+	include        []rule
+	exclude        []rule
+	useIgnoreFiles bool
+	ignores        []rule
+	loaded         map[string]bool // Directories whose ignore files have been read
+}
+
+func newFilter(root string, opts Options) *filter {
+	return &filter{
+		root:           root,
+		include:        parsePatterns(opts.Include),
+		exclude:        parsePatterns(opts.Exclude),
+		useIgnoreFiles: opts.IgnoreFiles,
+		loaded:         make(map[string]bool),
+	}
+}
+
+// skipDir reports whether a directory (relative to the root, "." for the
+// root itself) is excluded, reading its ignore files if it is not
+func (f *filter) skipDir(rel string) bool {
+	if rel != "." && f.excluded(rel, true) {
+		return true
+	}
+	f.loadIgnoreFiles(rel)
+	return false
+}
+
+// skipFile reports whether a file, whose directories have been checked with
+// skipDir, is excluded
+func (f *filter) skipFile(rel string) bool {
+	if f.excluded(rel, false) {
+		return true
+	}
+	if len(f.include) == 0 {
+		return false
+	}
+	for _, r := range f.include {
+		if r.matches(rel, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// skipPath reports whether a file is excluded, checking each of its
+// directories in turn
+func (f *filter) skipPath(rel string) bool {
+	if f.skipDir(".") {
+		return true
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if f.skipDir(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	return f.skipFile(rel)
+}
+
+// excluded reports whether a path matches an exclude pattern, or is ignored
+// by an ignore file; as with gitignore, the last matching ignore rule wins
+func (f *filter) excluded(rel string, isDir bool) bool {
+	for _, r := range f.exclude {
+		if r.matches(rel, isDir) {
+			return true
+		}
+	}
+
+	ignored := false
+	for _, r := range f.ignores {
+		if r.matches(rel, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// loadIgnoreFiles reads the ignore files in a directory, once
+func (f *filter) loadIgnoreFiles(rel string) {
+	if !f.useIgnoreFiles || f.loaded[rel] {
+		return
+	}
+	f.loaded[rel] = true
+
+	dir := rel
+	if dir == "." {
+		dir = ""
+	}
+	for _, name := range IgnoreFileNames {
+		rules, err := readIgnoreFile(filepath.Join(f.root, filepath.FromSlash(rel), name), dir)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		f.ignores = append(f.ignores, rules...)
+	}
+}
+
+// readIgnoreFile reads the rules of an ignore file in dir; a missing file
+// has no rules
+func readIgnoreFile(filePath, dir string) ([]rule, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	defer file.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text(), dir); ok {
+			rules = append(rules, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file %s: %w", filePath, err)
+	}
+	return rules, nil
+}
+
+// findFiles lists the files under root whose paths relative to root match
+// a pattern (or all files, if the pattern is empty) and are not excluded,
+// and counts the excluded files. Directories that cannot contain a match,
+// or are excluded, are not searched.
+func findFiles(root, pattern string, opts Options) ([]string, int, error) {
+	f := newFilter(root, opts)
+	var files []string
+	excluded := 0
+
+	err := filepath.WalkDir(root, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := relativePath(root, filePath)
+		if d.IsDir() {
+			if pattern != "" && !glob.MatchDir(pattern, rel) {
+				return filepath.SkipDir
+			}
+			if f.skipDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if pattern != "" && !glob.Match(pattern, rel) {
+			return nil
+		}
+		if f.skipFile(rel) {
+			excluded++
+			return nil
+		}
+		files = append(files, filePath)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, excluded, nil
+}
+
+// FilterFiles removes the files excluded by the include and exclude
+// patterns from a list of files, matching the paths as given; ignore files
+// are not read. It returns the remaining files and the number excluded.
+func FilterFiles(files []string, opts Options) ([]string, int) {
+	f := newFilter(".", opts)
+	f.useIgnoreFiles = false
+	kept := make([]string, 0, len(files))
+	excluded := 0
+
+	for _, file := range files {
+		if f.skipPath(filepath.ToSlash(filepath.Clean(file))) {
+			excluded++
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept, excluded
+}
+
+// Excluded returns true if a file that would be processed from the given
+// source path is excluded by the include and exclude patterns or an ignore
+// file. A source that is a single file excludes nothing.
+func Excluded(file, source string, opts Options) bool {
+	root := source
+	if glob.HasMeta(source) {
+		root, _ = splitPattern(source)
+	} else if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return false
+	}
+	return newFilter(root, opts).skipPath(relativePath(root, file))
+}
+
+// splitPattern divides a glob pattern into the directory to search and the
+// pattern that paths relative to it must match
+func splitPattern(pattern string) (string, string) {
+	base, rest := glob.Split(filepath.ToSlash(pattern))
+	return filepath.FromSlash(base), rest
+}
+
+// relativePath returns the slash-separated path of file relative to root
+func relativePath(root, file string) string {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
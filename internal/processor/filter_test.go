@@ -0,0 +1,189 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files with the given contents under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fileNames returns the documents' file names relative to dir, sorted
+func fileNames(t *testing.T, dir string, files []string) string {
+	t.Helper()
+	var names []string
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filepath.ToSlash(rel))
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestProcessFilesExclusions(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		".gitignore":                 "_build/\n*.tmp.md\n",
+		".docloaderignore":           "# Work in progress\ndrafts/\n!drafts/\n/CHANGES.md\n",
+		"index.md":                   "# Index",
+		"CHANGES.md":                 "# Changes",
+		"notes.tmp.md":               "# Scratch",
+		"guide/intro.md":             "# Intro",
+		"guide/CHANGES.md":           "# Guide changes",
+		"guide/drafts/next.md":       "# Next",
+		"guide/.docloaderignore":     "*.html\n!keep.html\n",
+		"guide/page.html":            "<html><head><title>Page</title></head></html>",
+		"guide/keep.html":            "<html><head><title>Keep</title></head></html>",
+		"_build/html/index.md":       "# Built",
+		"node_modules/pkg/README.md": "# Package",
+		"vendor/lib/README.md":       "# Vendored",
+	})
+
+	documentNames := func(docs []string) string { return fileNames(t, tmpDir, docs) }
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+		excluded int
+		skipped  int // The ignore files themselves, unless excluded
+	}{
+		{
+			"Ignore files",
+			Options{IgnoreFiles: true},
+			"guide/CHANGES.md guide/drafts/next.md guide/intro.md guide/keep.html index.md node_modules/pkg/README.md vendor/lib/README.md",
+			3, // notes.tmp.md, CHANGES.md and guide/page.html; _build is not walked
+			3,
+		},
+		{
+			"Ignore files disabled",
+			Options{},
+			"CHANGES.md _build/html/index.md guide/CHANGES.md guide/drafts/next.md guide/intro.md guide/keep.html guide/page.html index.md node_modules/pkg/README.md notes.tmp.md vendor/lib/README.md",
+			0,
+			3,
+		},
+		{
+			"Exclude patterns",
+			Options{IgnoreFiles: true, Exclude: []string{"node_modules", "vendor/**/README.md", "drafts/"}},
+			"guide/CHANGES.md guide/intro.md guide/keep.html index.md",
+			4,
+			3,
+		},
+		{
+			"Include patterns",
+			Options{IgnoreFiles: true, Include: []string{"guide/**/*.md"}, Exclude: []string{"CHANGES.md"}},
+			"guide/drafts/next.md guide/intro.md",
+			11,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, stats, err := ProcessFiles(tmpDir, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var files []string
+			for _, doc := range docs {
+				files = append(files, doc.FileName)
+			}
+			if got := documentNames(files); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+			if stats.FilesExcluded != tt.excluded || stats.FilesSkipped != tt.skipped {
+				t.Errorf("expected %d excluded and %d skipped, got %d and %d", tt.excluded, tt.skipped, stats.FilesExcluded, stats.FilesSkipped)
+			}
+		})
+	}
+
+	t.Run("Glob pattern", func(t *testing.T) {
+		pattern := filepath.Join(tmpDir, "guide", "*.html")
+		docs, stats, err := ProcessFiles(pattern, Options{IgnoreFiles: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(docs) != 1 || filepath.Base(docs[0].FileName) != "keep.html" || stats.FilesExcluded != 1 {
+			t.Errorf("expected only keep.html and 1 excluded, got %d documents and %d excluded", len(docs), stats.FilesExcluded)
+		}
+
+		pattern = filepath.Join(tmpDir, "**", "README.md")
+		docs, stats, err = ProcessFiles(pattern, Options{IgnoreFiles: true, Exclude: []string{"vendor/**/README.md"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(docs) != 1 || stats.FilesExcluded != 1 {
+			t.Errorf("expected 1 document and 1 excluded, got %d and %d", len(docs), stats.FilesExcluded)
+		}
+	})
+
+	t.Run("Single file", func(t *testing.T) {
+		docs, _, err := ProcessFiles(filepath.Join(tmpDir, "notes.tmp.md"), Options{IgnoreFiles: true})
+		if err != nil || len(docs) != 1 {
+			t.Errorf("expected a single file to be loaded, got %d documents, error %v", len(docs), err)
+		}
+	})
+
+	t.Run("Excluded", func(t *testing.T) {
+		opts := Options{IgnoreFiles: true, Exclude: []string{"vendor/"}}
+		for file, expected := range map[string]bool{
+			"index.md":             false,
+			"notes.tmp.md":         true,
+			"_build/html/index.md": true,
+			"guide/page.html":      true,
+			"guide/keep.html":      false,
+			"vendor/lib/README.md": true,
+			"guide/removed.md":     false,
+		} {
+			if got := Excluded(filepath.Join(tmpDir, file), tmpDir, opts); got != expected {
+				t.Errorf("Excluded(%s) = %v, expected %v", file, got, expected)
+			}
+		}
+		if Excluded(filepath.Join(tmpDir, "notes.tmp.md"), filepath.Join(tmpDir, "notes.tmp.md"), opts) {
+			t.Error("expected a single file source to exclude nothing")
+		}
+	})
+}
+
+func TestFilterFiles(t *testing.T) {
+	files := []string{"docs/a.md", "docs/drafts/b.md", "node_modules/x/README.md", "other/c.rst"}
+	kept, excluded := FilterFiles(files, Options{
+		IgnoreFiles: true,
+		Include:     []string{"*.md"},
+		Exclude:     []string{"drafts", "node_modules/"},
+	})
+	if strings.Join(kept, " ") != "docs/a.md" || excluded != 3 {
+		t.Errorf("expected only docs/a.md and 3 excluded, got %v and %d", kept, excluded)
+	}
+
+	kept, excluded = FilterFiles(nil, Options{})
+	if kept == nil || excluded != 0 {
+		t.Errorf("expected an empty, non-nil list, got %v", kept)
+	}
+}
@@ -0,0 +1,67 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// ReadFileList reads a list of file paths, one per line or separated by NUL
+// characters (as written by find -print0 or git ls-files -z). Blank lines
+// are ignored.
+func ReadFileList(r io.Reader) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
+	}
+
+	// A list containing NUL is NUL separated, so that names may contain
+	// newlines
+	var entries []string
+	if bytes.IndexByte(content, 0) >= 0 {
+		entries = strings.Split(string(content), "\x00")
+	} else {
+		entries = strings.Split(string(content), "\n")
+		for i := range entries {
+			entries[i] = strings.TrimSuffix(entries[i], "\r")
+		}
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) != "" {
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
+
+// ProcessReader converts a single document read from r (such as stdin),
+// whose format is given by name or, failing that, its content. The
+// document is stored under name and has no modification time.
+func ProcessReader(r io.Reader, name string, opts Options) (*types.Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	docType := opts.Registry().DetectContentType(name, content)
+	if docType == types.TypeUnknown {
+		return nil, fmt.Errorf("unsupported file type: %s", name)
+	}
+
+	opts.FileMetadata = nil
+	return ProcessContent(name, content, docType, nil, opts)
+}
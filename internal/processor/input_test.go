@@ -0,0 +1,76 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+func TestReadFileList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Newlines", "docs/a.md\ndocs/b.md\n", []string{"docs/a.md", "docs/b.md"}},
+		{"CRLF and blank lines", "docs/a.md\r\n\r\n  \ndocs/b.md", []string{"docs/a.md", "docs/b.md"}},
+		{"NUL separated", "docs/a b.md\x00docs/new\nline.md\x00", []string{"docs/a b.md", "docs/new\nline.md"}},
+		{"Empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ReadFileList(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(files, "|") != strings.Join(tt.expected, "|") || len(files) != len(tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestProcessReader(t *testing.T) {
+	doc, err := ProcessReader(strings.NewReader("# Generated\n\nContent"), "reference/generated.md", Options{Source: "stdin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.FileName != "reference/generated.md" || doc.Title != "Generated" || doc.DocumentType != types.TypeMarkdown {
+		t.Errorf("unexpected document: %s %q %v", doc.FileName, doc.Title, doc.DocumentType)
+	}
+	if doc.FileModified != nil || doc.Source != "stdin" {
+		t.Errorf("unexpected modified time or source: %v %q", doc.FileModified, doc.Source)
+	}
+
+	doc, err = ProcessReader(strings.NewReader("<html><head><title>Page</title></head></html>"), "out/page.html", Options{StripPath: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.FileName != "page.html" || doc.DocumentType != types.TypeHTML {
+		t.Errorf("unexpected document: %s %v", doc.FileName, doc.DocumentType)
+	}
+
+	// Plain text without a title line is named after its file
+	doc, err = ProcessReader(strings.NewReader("Some notes\nover two lines.\n"), "docs/notes.txt", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.Title != "notes" || doc.DocumentType != types.TypePlainText {
+		t.Errorf("unexpected document: %q %v", doc.Title, doc.DocumentType)
+	}
+
+	if _, err := ProcessReader(strings.NewReader("data"), "notes.png", Options{}); err == nil {
+		t.Error("expected error for an unsupported name")
+	}
+}
@@ -10,27 +10,100 @@
 package processor
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/glob"
 	"github.com/pgedge/pgedge-docloader/internal/types"
 )
 
-// ProcessFiles processes files from the source path
-func ProcessFiles(source string, stripPath bool) ([]*types.Document, *types.Stats, error) {
+// Options controls how files are processed
+type Options struct {
+	StripPath        bool // Keep only the base name of each file
+	StripFrontMatter bool // Remove front matter from the stored content
+
+	// Source-supplied metadata keyed by file path (e.g. from Git history),
+	// in place of the filesystem's: when set, files without an entry have
+	// no timestamps
+	FileMetadata map[string]*types.FileMetadata
+
+	// Repository, ref and commit the files were checked out from, for Git
+	// sources
+	GitURL    string
+	GitRef    string
+	GitCommit string
+
+	// Name of the configured source the files belong to
+	Source string
+
+	// Patterns selecting the files to load from a directory or glob, and
+	// the files to leave out (see ProcessFiles)
+	Include []string
+	Exclude []string
+
+	// Honor .gitignore and .docloaderignore files in the directories
+	// walked
+	IgnoreFiles bool
+
+	// Converters and format mappings to detect and convert files with;
+	// the built-in converters if nil (see Registry)
+	Formats *converter.Registry
+}
+
+// Registry returns the converters and format mappings to use
+func (opts Options) Registry() *converter.Registry {
+	if opts.Formats != nil {
+		return opts.Formats
+	}
+	return converter.Default()
+}
+
+// ErrLFSPointer is returned for Git LFS pointer files, whose content has
+// not been fetched
+var ErrLFSPointer = errors.New("git LFS pointer file")
+
+// lfsPointerPrefix begins every Git LFS pointer file
+var lfsPointerPrefix = []byte("version https://git-lfs.github.com/spec/v1\n")
+
+// IsLFSPointer returns true if content is a Git LFS pointer file rather
+// than the file's real content
+func IsLFSPointer(content []byte) bool {
+	// Pointer files are small text files of key/value lines
+	return len(content) < 1024 && bytes.HasPrefix(content, lfsPointerPrefix) &&
+		bytes.Contains(content, []byte("\noid sha256:"))
+}
+
+// ProcessFiles processes files from the source path. Files within a
+// directory or matching a glob pattern are skipped if they do not match an
+// include pattern (when there are any) or match an exclude pattern or, with
+// IgnoreFiles, a .gitignore or .docloaderignore file; these are counted as
+// excluded rather than skipped. A single file is always processed.
+func ProcessFiles(source string, opts Options) ([]*types.Document, *types.Stats, error) {
 	stats := &types.Stats{}
 	var documents []*types.Document
 
 	// Check if source is a single file, directory, or glob pattern
 	fileInfo, err := os.Stat(source)
-	if err == nil && !fileInfo.IsDir() {
+	if err == nil && !fileInfo.IsDir() && IsArchive(source) {
+		// Archive
+		return ProcessArchive(source, opts)
+	} else if err == nil && !fileInfo.IsDir() {
 		// Single file
-		doc, err := processFile(source, stripPath)
+		doc, err := processFile(source, opts)
+		if errors.Is(err, ErrLFSPointer) {
+			fmt.Printf("Skipping Git LFS pointer file: %s\n", source)
+			stats.FilesSkipped++
+			stats.LFSPointers++
+			return nil, stats, nil
+		}
 		if err != nil {
 			if err == converter.ErrUnsupportedFormat {
 				return nil, nil, fmt.Errorf("unsupported file type: %s", source)
@@ -42,111 +115,118 @@ func ProcessFiles(source string, stripPath bool) ([]*types.Document, *types.Stat
 	} else {
 		// Directory or glob pattern
 		var files []string
+		var excluded int
 
-		// Check if it's a glob pattern
-		if strings.ContainsAny(source, "*?[]") {
-			// Check for ** recursive glob pattern
-			if strings.Contains(source, "**") {
-				matches, err := recursiveGlob(source)
-				if err != nil {
-					return nil, nil, fmt.Errorf("failed to process glob pattern: %w", err)
-				}
-				files = matches
-			} else {
-				// Use standard glob for non-recursive patterns
-				matches, err := filepath.Glob(source)
-				if err != nil {
-					return nil, nil, fmt.Errorf("invalid glob pattern: %w", err)
-				}
-				files = matches
+		if glob.HasMeta(source) {
+			// Glob pattern - search the directory before the first
+			// wildcard for matching files
+			root, pattern := splitPattern(source)
+			files, excluded, err = findFiles(root, pattern, opts)
+			if errors.Is(err, fs.ErrNotExist) {
+				files, err = nil, nil
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to process glob pattern: %w", err)
 			}
 		} else {
-			// Directory - walk it recursively
-			err := filepath.WalkDir(source, func(path string, d os.DirEntry, err error) error {
-				if err != nil {
-					return err
-				}
-				if !d.IsDir() {
-					files = append(files, path)
-				}
-				return nil
-			})
+			// Directory - walk it recursively, skipping excluded
+			// directories
+			files, excluded, err = findFiles(source, "", opts)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to read directory: %w", err)
 			}
 		}
 
-		// Process each file
-		for _, file := range files {
-			if !converter.IsSupported(file) {
-				fmt.Printf("Skipping unsupported file: %s\n", file)
-				stats.FilesSkipped++
-				continue
-			}
-
-			doc, err := processFile(file, stripPath)
-			if err != nil {
-				fmt.Printf("Error processing file %s: %v\n", file, err)
-				stats.AddError(fmt.Errorf("file %s: %w", file, err))
-				stats.FilesSkipped++
-				continue
-			}
-
-			documents = append(documents, doc)
-			stats.FilesProcessed++
-		}
+		documents, stats = ProcessFileList(files, opts)
+		stats.FilesExcluded += excluded
 	}
 
 	return documents, stats, nil
 }
 
-// recursiveGlob implements recursive glob matching with ** support
-func recursiveGlob(pattern string) ([]string, error) {
-	// Split pattern at /** to get base dir and file pattern
-	parts := strings.Split(pattern, "**")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid recursive glob pattern: %s", pattern)
-	}
+// ProcessFileList processes each of the given files, and the files within
+// archives, skipping unsupported files and recording per-file errors in the
+// returned stats
+func ProcessFileList(files []string, opts Options) ([]*types.Document, *types.Stats) {
+	stats := &types.Stats{}
+	var documents []*types.Document
 
-	baseDir := strings.TrimSuffix(parts[0], "/")
-	filePattern := strings.TrimPrefix(parts[1], "/")
+	for _, file := range files {
+		if IsArchive(file) {
+			archiveDocuments, archiveStats, err := ProcessArchive(file, opts)
+			if err != nil {
+				fmt.Printf("Error processing archive %s: %v\n", file, err)
+				stats.AddError(err)
+				stats.FilesSkipped++
+				continue
+			}
+			documents = append(documents, archiveDocuments...)
+			stats.Merge(archiveStats)
+			continue
+		}
 
-	// If baseDir is empty, use current directory
-	if baseDir == "" {
-		baseDir = "."
-	}
+		if !opts.Registry().IsSupported(file) {
+			fmt.Printf("Skipping unsupported file: %s\n", file)
+			stats.FilesSkipped++
+			continue
+		}
 
-	var matches []string
-	err := filepath.WalkDir(baseDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
+		doc, err := processFile(file, opts)
+		if errors.Is(err, ErrLFSPointer) {
+			fmt.Printf("Skipping Git LFS pointer file: %s\n", file)
+			stats.FilesSkipped++
+			stats.LFSPointers++
+			continue
 		}
-		if d.IsDir() {
-			return nil
+		if errors.Is(err, converter.ErrUnsupportedFormat) {
+			// The content did not reveal a supported format
+			fmt.Printf("Skipping unsupported file: %s\n", file)
+			stats.FilesSkipped++
+			continue
 		}
-
-		// Match the file against the pattern
-		matched, err := filepath.Match(filePattern, filepath.Base(path))
 		if err != nil {
-			return err
+			fmt.Printf("Error processing file %s: %v\n", file, err)
+			stats.AddError(fmt.Errorf("file %s: %w", file, err))
+			stats.FilesSkipped++
+			continue
 		}
 
-		if matched {
-			matches = append(matches, path)
-		}
+		documents = append(documents, doc)
+		stats.FilesProcessed++
+	}
 
-		return nil
-	})
+	return documents, stats
+}
 
-	if err != nil {
-		return nil, err
+// MatchesSource returns true if a file would be processed from the given
+// source path: the file itself, a file within the directory, or a file
+// matching the glob pattern
+func MatchesSource(file, source string) bool {
+	file = filepath.Clean(file)
+
+	if !glob.HasMeta(source) {
+		source = filepath.Clean(source)
+		return file == source || strings.HasPrefix(file, source+string(filepath.Separator))
 	}
 
-	return matches, nil
+	root, pattern := splitPattern(source)
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return glob.Match(pattern, filepath.ToSlash(rel))
+}
+
+// FileName returns the name a file is stored under in the file name column
+func FileName(filePath string, opts Options) string {
+	if opts.StripPath {
+		return filepath.Base(filePath)
+	}
+	return filePath
 }
 
 // processFile processes a single file
-func processFile(filePath string, stripPath bool) (*types.Document, error) {
+func processFile(filePath string, opts Options) (*types.Document, error) {
 	// Read file content
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -159,47 +239,97 @@ func processFile(filePath string, stripPath bool) (*types.Document, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Detect document type
-	docType := converter.DetectDocumentType(filePath)
+	// Get file metadata
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	modTime := fileInfo.ModTime()
+
+	doc, err := processContent(filePath, sourceContent, &modTime, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get creation time (platform-specific)
+	if ct := getCreationTime(fileInfo); ct != nil {
+		doc.FileCreated = ct
+	}
+
+	// Apply source-supplied metadata
+	if opts.FileMetadata != nil {
+		doc.FileCreated, doc.FileModified = nil, nil
+		if meta, ok := opts.FileMetadata[filepath.Clean(filePath)]; ok {
+			doc.FileCreated = meta.Created
+			doc.FileModified = meta.Modified
+			doc.Author = meta.Author
+			doc.Commit = meta.Commit
+		}
+	}
+
+	return doc, nil
+}
+
+// processContent converts the content of a file (or archive member) named
+// filePath into a document
+func processContent(filePath string, sourceContent []byte, modTime *time.Time, opts Options) (*types.Document, error) {
+	if IsLFSPointer(sourceContent) {
+		return nil, ErrLFSPointer
+	}
+
+	// Detect document type, from the content if the name is not enough
+	docType := opts.Registry().DetectContentType(filePath, sourceContent)
 	if docType == types.TypeUnknown {
 		return nil, converter.ErrUnsupportedFormat
 	}
 
+	return ProcessContent(filePath, sourceContent, docType, modTime, opts)
+}
+
+// ProcessContent converts content of a known document type into a
+// document named filePath, for sources whose documents are not files
+// (such as web pages)
+func ProcessContent(filePath string, sourceContent []byte, docType types.DocumentType, modTime *time.Time, opts Options) (*types.Document, error) {
 	// Convert to markdown
-	markdown, title, err := converter.Convert(sourceContent, docType)
+	result, err := opts.Registry().Convert(sourceContent, docType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert document: %w", err)
 	}
-
-	// Get file metadata
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info: %w", err)
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s: %s\n", filePath, warning)
 	}
 
-	// Extract timestamps
-	modTime := fileInfo.ModTime()
+	// Remove front matter the converter has returned as metadata
+	markdown := result.Markdown
+	if opts.StripFrontMatter && result.Metadata != nil {
+		if _, body, err := converter.SplitFrontMatter([]byte(markdown)); err == nil {
+			markdown = string(body)
+		}
+	}
 
-	// Get creation time (platform-specific)
-	var createTime *time.Time
-	if ct := getCreationTime(fileInfo); ct != nil {
-		createTime = ct
+	// Plain text has no markup to give it a title unless its first line
+	// is one, so name it after its file
+	title := result.Title
+	if title == "" && docType == types.TypePlainText {
+		base := filepath.Base(filePath)
+		title = strings.TrimSuffix(base, filepath.Ext(base))
 	}
 
 	// Determine filename (with or without path)
-	fileName := filePath
-	if stripPath {
-		fileName = filepath.Base(filePath)
-	}
+	fileName := FileName(filePath, opts)
 
 	doc := &types.Document{
 		Title:         title,
 		Content:       markdown,
 		SourceContent: sourceContent,
 		FileName:      fileName,
-		FileCreated:   createTime,
-		FileModified:  &modTime,
+		FileModified:  modTime,
 		DocumentType:  docType,
+		Metadata:      result.Metadata,
+		GitURL:        opts.GitURL,
+		GitRef:        opts.GitRef,
+		GitCommit:     opts.GitCommit,
+		Source:        opts.Source,
 	}
 
 	return doc, nil
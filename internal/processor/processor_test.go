@@ -13,6 +13,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/types"
 )
 
 func TestProcessFile(t *testing.T) {
@@ -73,7 +77,7 @@ func TestProcessFile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			filePath := filepath.Join(tmpDir, tt.filename)
-			doc, err := processFile(filePath, tt.stripPath)
+			doc, err := processFile(filePath, Options{StripPath: tt.stripPath})
 
 			if tt.wantErr {
 				if err == nil {
@@ -121,10 +125,10 @@ func TestProcessFiles(t *testing.T) {
 
 	// Create test files
 	testFiles := map[string]string{
-		"doc1.md":    "# Document 1\n\nContent 1",
-		"doc2.md":    "# Document 2\n\nContent 2",
-		"doc3.html":  "<html><head><title>Doc 3</title></head><body><p>Content 3</p></body></html>",
-		"readme.txt": "This should be skipped",
+		"doc1.md":   "# Document 1\n\nContent 1",
+		"doc2.md":   "# Document 2\n\nContent 2",
+		"doc3.html": "<html><head><title>Doc 3</title></head><body><p>Content 3</p></body></html>",
+		"data.csv":  "This should be skipped",
 	}
 
 	for filename, content := range testFiles {
@@ -135,7 +139,7 @@ func TestProcessFiles(t *testing.T) {
 	}
 
 	t.Run("Process directory", func(t *testing.T) {
-		docs, stats, err := ProcessFiles(tmpDir, false)
+		docs, stats, err := ProcessFiles(tmpDir, Options{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -156,7 +160,7 @@ func TestProcessFiles(t *testing.T) {
 
 	t.Run("Process glob pattern", func(t *testing.T) {
 		pattern := filepath.Join(tmpDir, "*.md")
-		docs, stats, err := ProcessFiles(pattern, false)
+		docs, stats, err := ProcessFiles(pattern, Options{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -173,7 +177,7 @@ func TestProcessFiles(t *testing.T) {
 
 	t.Run("Process single file", func(t *testing.T) {
 		filePath := filepath.Join(tmpDir, "doc1.md")
-		docs, stats, err := ProcessFiles(filePath, false)
+		docs, stats, err := ProcessFiles(filePath, Options{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -188,8 +192,8 @@ func TestProcessFiles(t *testing.T) {
 	})
 
 	t.Run("Unsupported single file", func(t *testing.T) {
-		filePath := filepath.Join(tmpDir, "readme.txt")
-		_, _, err := ProcessFiles(filePath, false)
+		filePath := filepath.Join(tmpDir, "data.csv")
+		_, _, err := ProcessFiles(filePath, Options{})
 		if err == nil {
 			t.Error("expected error for unsupported file, got nil")
 		}
@@ -227,7 +231,7 @@ func TestProcessFiles(t *testing.T) {
 
 		// Test recursive glob pattern
 		pattern := filepath.Join(nestedDir, "**/*.md")
-		docs, stats, err := ProcessFiles(pattern, false)
+		docs, stats, err := ProcessFiles(pattern, Options{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -241,3 +245,268 @@ func TestProcessFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessFilesDoublestar(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"docs/reference/api.md":       "# API",
+		"docs/v1/reference/old.rst":   "Old\n===",
+		"docs/v1/guide.md":            "# Guide",
+		"docs/reference/v1/nested.md": "# Nested",
+		"a/b/index.html":              "<html><head><title>B</title></head></html>",
+		"a/x/b/y/page.html":           "<html><head><title>Y</title></head></html>",
+		"a/x/y/other.html":            "<html><head><title>Other</title></head></html>",
+		"guide/intro.md":              "# Intro",
+		"notes/todo.md":               "# Todo",
+	})
+
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"docs/**/reference/*.{md,rst}", "docs/reference/api.md docs/v1/reference/old.rst"},
+		{"a/**/b/**/*.html", "a/b/index.html a/x/b/y/page.html"},
+		{"**/*.rst", "docs/v1/reference/old.rst"},
+		{"{docs,guide}/*/*.md", "docs/reference/api.md docs/v1/guide.md"},
+		{"{guide,notes}/[i-n]*.md", "guide/intro.md"},
+		{"missing/**/*.md", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			docs, _, err := ProcessFiles(filepath.Join(tmpDir, tt.pattern), Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var files []string
+			for _, doc := range docs {
+				files = append(files, doc.FileName)
+			}
+			if got := fileNames(t, tmpDir, files); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessFilesSniffing(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"INSTALL":        "<!DOCTYPE html><html><head><title>Install</title></head></html>",
+		"notes.txt":      "---\ntitle: Notes\n---\n\nSome notes",
+		"todo.txt":       "Plain text",
+		"README":         "Widgets\n\nRead me first.",
+		"manual.xml":     "<?xml version=\"1.0\"?>\n<chapter><title>Manual</title><para>Text</para></chapter>",
+		"pom.xml":        "<?xml version=\"1.0\"?>\n<project></project>",
+		"guide.markdown": "# Guide",
+	})
+
+	docs, stats, err := ProcessFiles(tmpDir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	types := make(map[string]string)
+	for _, doc := range docs {
+		types[filepath.Base(doc.FileName)] = doc.DocumentType.String()
+	}
+	expected := map[string]string{
+		"INSTALL":        "HTML",
+		"notes.txt":      "Markdown",
+		"todo.txt":       "Plain Text",
+		"README":         "Plain Text",
+		"manual.xml":     "SGML/DocBook",
+		"guide.markdown": "Markdown",
+	}
+	if len(types) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, types)
+	}
+	for name, docType := range expected {
+		if types[name] != docType {
+			t.Errorf("%s: expected %s, got %q", name, docType, types[name])
+		}
+	}
+	if stats.FilesSkipped != 1 || len(stats.Errors) != 0 {
+		t.Errorf("expected 1 skipped and no errors, got %d and %v", stats.FilesSkipped, stats.Errors)
+	}
+}
+
+func TestProcessFilesFormatMap(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"header.inc": "# Header",
+		"guide.md":   "Guide\n=====",
+	})
+
+	formats, err := converter.Default().WithFormatMap(map[string]string{
+		"*.inc": "markdown",
+		".md":   "rst",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	docs, stats, err := ProcessFiles(tmpDir, Options{Formats: formats})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 2 || stats.FilesSkipped != 0 {
+		t.Fatalf("expected 2 documents, got %d (%d skipped)", len(docs), stats.FilesSkipped)
+	}
+	for _, doc := range docs {
+		expected := types.TypeMarkdown
+		if filepath.Base(doc.FileName) == "guide.md" {
+			expected = types.TypeReStructuredText
+		}
+		if doc.DocumentType != expected {
+			t.Errorf("%s: expected %s, got %s", doc.FileName, expected, doc.DocumentType)
+		}
+	}
+
+	// Without the mappings, the include file is not supported
+	if _, stats, _ = ProcessFiles(tmpDir, Options{}); stats.FilesSkipped != 1 {
+		t.Errorf("expected 1 skipped without the mappings, got %d", stats.FilesSkipped)
+	}
+}
+
+func TestProcessFileFrontMatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "page.md")
+	content := "---\ntitle: Page\nproduct: pgAdmin\n---\n\n# Page\n\nBody\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	doc, err := processFile(filePath, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if doc.Metadata["product"] != "pgAdmin" {
+		t.Errorf("expected product metadata, got %v", doc.Metadata)
+	}
+
+	if doc.Content != content {
+		t.Error("expected front matter to be kept in content by default")
+	}
+
+	doc, err = processFile(filePath, Options{StripFrontMatter: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if doc.Content != "# Page\n\nBody\n" {
+		t.Errorf("expected front matter to be stripped, got %q", doc.Content)
+	}
+
+	if string(doc.SourceContent) != content {
+		t.Error("expected source content to be unchanged")
+	}
+}
+
+func TestProcessFileWithFileMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "page.md")
+	if err := os.WriteFile(filePath, []byte("# Page"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	modified := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	opts := Options{
+		FileMetadata: map[string]*types.FileMetadata{
+			filePath: {
+				Created:  &created,
+				Modified: &modified,
+				Author:   "Carol",
+				Commit:   "abc123",
+			},
+		},
+	}
+
+	doc, err := processFile(filePath, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !doc.FileCreated.Equal(created) || !doc.FileModified.Equal(modified) {
+		t.Errorf("expected Git timestamps, got created %v modified %v", doc.FileCreated, doc.FileModified)
+	}
+	if doc.Author != "Carol" || doc.Commit != "abc123" {
+		t.Errorf("expected author and commit, got %s %s", doc.Author, doc.Commit)
+	}
+
+	// A file the source has no metadata for has no timestamps, rather than
+	// those of the checkout
+	opts.FileMetadata = map[string]*types.FileMetadata{}
+	if doc, err = processFile(filePath, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.FileCreated != nil || doc.FileModified != nil || doc.Author != "" || doc.Commit != "" {
+		t.Errorf("expected no file metadata, got %+v", doc)
+	}
+}
+
+func TestProcessFilesLFSPointer(t *testing.T) {
+	tmpDir := t.TempDir()
+	pointer := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+		"size 12345\n"
+	files := map[string]string{
+		"guide.md":   "# Guide",
+		"diagram.md": pointer,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	docs, stats, err := ProcessFiles(tmpDir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Title != "Guide" {
+		t.Errorf("expected only guide.md to be loaded, got %d documents", len(docs))
+	}
+	if stats.FilesSkipped != 1 || stats.LFSPointers != 1 {
+		t.Errorf("expected 1 LFS pointer skipped, got skipped %d pointers %d", stats.FilesSkipped, stats.LFSPointers)
+	}
+	if stats.HasErrors() {
+		t.Errorf("expected pointer files not to be errors, got %v", stats.Errors)
+	}
+
+	if IsLFSPointer([]byte("version https://git-lfs.github.com/spec/v1\n# Not a pointer")) {
+		t.Error("expected content without an oid not to be a pointer")
+	}
+}
+
+func TestMatchesSource(t *testing.T) {
+	tests := []struct {
+		file    string
+		source  string
+		matches bool
+	}{
+		{"/repo/docs/a.md", "/repo/docs", true},
+		{"/repo/docs/sub/a.md", "/repo/docs", true},
+		{"/repo/docsx/a.md", "/repo/docs", false},
+		{"/repo/docs/a.md", "/repo/docs/a.md", true},
+		{"/repo/docs/a.md", "/repo/docs/*.md", true},
+		{"/repo/docs/sub/a.md", "/repo/docs/*.md", false},
+		{"/repo/docs/sub/a.md", "/repo/docs/**/*.md", true},
+		{"/repo/other/a.md", "/repo/docs/**/*.md", false},
+		{"/repo/docs/sub/a.rst", "/repo/docs/**/*.md", false},
+		{"/repo/docs/v1/reference/a.md", "/repo/docs/**/reference/*.md", true},
+		{"/repo/docs/reference/v1/a.md", "/repo/docs/**/reference/*.md", false},
+		{"/repo/docs/sub/a.rst", "/repo/docs/**/*.{md,rst}", true},
+		{"/repo/guide/a.md", "/repo/{docs,guide}/*.md", true},
+		{"/repo/a/x/b/y/index.html", "/repo/a/**/b/**/*.html", true},
+		{"/other/docs/a.md", "/repo/**/*.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesSource(tt.file, tt.source); got != tt.matches {
+			t.Errorf("MatchesSource(%q, %q) = %v, expected %v", tt.file, tt.source, got, tt.matches)
+		}
+	}
+}
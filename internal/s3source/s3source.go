@@ -0,0 +1,195 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package s3source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// defaultRegion is used when no region is configured, as S3-compatible
+// services often ignore the region but requests must be signed for one
+const defaultRegion = "us-east-1"
+
+// maxObjectSize is the size of the largest object loaded, which tests may
+// lower
+var maxObjectSize int64 = 64 << 20
+
+// Source loads documents from the objects in an S3 (or S3-compatible)
+// bucket
+type Source struct {
+	client   *s3.Client
+	bucket   string
+	prefixes []string
+}
+
+// New creates a Source from configuration. Credentials are taken from the
+// environment (AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY), or from the
+// configured or default profile of the shared configuration files.
+func New(ctx context.Context, cfg *types.Config) (*Source, error) {
+	if err := ValidateEndpoint(cfg.S3Endpoint); err != nil {
+		return nil, err
+	}
+
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if cfg.S3Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.S3Region))
+	}
+	if cfg.S3Profile != "" {
+		loadOpts = append(loadOpts, awsconfig.WithSharedConfigProfile(cfg.S3Profile))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 configuration: %w", err)
+	}
+	if awsCfg.Region == "" {
+		awsCfg.Region = defaultRegion
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.S3Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.S3Endpoint)
+		}
+		o.UsePathStyle = cfg.S3PathStyle
+		// Objects are read whole, and S3-compatible services may not
+		// return checksums
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+
+	prefixes := cfg.S3Prefix
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+
+	return &Source{
+		client:   client,
+		bucket:   cfg.S3Bucket,
+		prefixes: prefixes,
+	}, nil
+}
+
+// ValidateEndpoint checks that an S3 endpoint, if given, is an absolute
+// http or https URL
+func ValidateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid S3 endpoint '%s': expected an http or https URL", endpoint)
+	}
+	return nil
+}
+
+// Load lists the objects under each prefix and converts the supported ones
+// into documents, named by object key. Unsupported objects are skipped, and
+// per-object errors are recorded in the returned stats; failing to list the
+// bucket is an error.
+func (s *Source) Load(ctx context.Context, opts processor.Options) ([]*types.Document, *types.Stats, error) {
+	stats := &types.Stats{}
+	var documents []*types.Document
+
+	// Object keys do not match source-supplied metadata
+	opts.FileMetadata = nil
+
+	seen := make(map[string]bool)
+	for _, prefix := range s.prefixes {
+		pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+			Bucket: aws.String(s.bucket),
+			Prefix: aws.String(prefix),
+		})
+		for pages.HasMorePages() {
+			page, err := pages.NextPage(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.bucket, prefix, err)
+			}
+
+			for _, object := range page.Contents {
+				key := aws.ToString(object.Key)
+				// Prefixes may overlap, and keys ending in / are folder
+				// placeholders
+				if seen[key] || strings.HasSuffix(key, "/") {
+					continue
+				}
+				seen[key] = true
+
+				if !opts.Registry().IsSupported(key) {
+					fmt.Printf("Skipping unsupported file: %s\n", key)
+					stats.FilesSkipped++
+					continue
+				}
+
+				doc, err := s.loadObject(ctx, key, aws.ToInt64(object.Size), opts)
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				if errors.Is(err, converter.ErrUnsupportedFormat) {
+					fmt.Printf("Skipping unsupported file: %s\n", key)
+					stats.FilesSkipped++
+					continue
+				}
+				if err != nil {
+					fmt.Printf("Error processing object %s: %v\n", key, err)
+					stats.AddError(fmt.Errorf("s3://%s/%s: %w", s.bucket, key, err))
+					stats.FilesSkipped++
+					continue
+				}
+
+				documents = append(documents, doc)
+				stats.FilesProcessed++
+			}
+		}
+	}
+
+	return documents, stats, nil
+}
+
+// loadObject downloads an object and converts it into a document
+func (s *Source) loadObject(ctx context.Context, key string, size int64, opts processor.Options) (*types.Document, error) {
+	if size > maxObjectSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxObjectSize)
+	}
+
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer out.Body.Close()
+
+	// The object may have grown since it was listed
+	content, err := io.ReadAll(io.LimitReader(out.Body, maxObjectSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	if int64(len(content)) > maxObjectSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxObjectSize)
+	}
+
+	docType := opts.Registry().DetectContentType(key, content)
+	if docType == types.TypeUnknown {
+		return nil, converter.ErrUnsupportedFormat
+	}
+	return processor.ProcessContent(key, content, docType, out.LastModified, opts)
+}
@@ -0,0 +1,296 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package s3source
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+var objectTime = time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+
+// fakeS3 is an in-process stand-in for an S3-compatible service, serving
+// one bucket with path-style addressing
+type fakeS3 struct {
+	*httptest.Server
+	bucket   string
+	objects  map[string]string
+	pageSize int // Keys per ListObjectsV2 page
+
+	mu          sync.Mutex
+	credentials []string // Access key of each request
+	gets        []string // Keys of objects fetched
+}
+
+// listResult is a ListObjectsV2 response
+type listResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	Prefix                string
+	KeyCount              int
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []listObject
+}
+
+type listObject struct {
+	Key          string
+	LastModified string
+	Size         int
+}
+
+func newFakeS3(t *testing.T, bucket string, objects map[string]string) *fakeS3 {
+	t.Helper()
+	f := &fakeS3{bucket: bucket, objects: objects, pageSize: 2}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	// Authorization: AWS4-HMAC-SHA256 Credential=KEY/date/region/s3/aws4_request, ...
+	auth := r.Header.Get("Authorization")
+	_, credential, _ := strings.Cut(auth, "Credential=")
+	key, _, _ := strings.Cut(credential, "/")
+	f.mu.Lock()
+	f.credentials = append(f.credentials, key)
+	f.mu.Unlock()
+
+	objectKey, isObject := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	switch {
+	case r.URL.Path == "/"+f.bucket && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r)
+	case isObject && r.Method == http.MethodGet:
+		content, ok := f.objects[objectKey]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>Not found</Message></Error>`)
+			return
+		}
+		f.mu.Lock()
+		f.gets = append(f.gets, objectKey)
+		f.mu.Unlock()
+		w.Header().Set("Last-Modified", objectTime.Format(http.TimeFormat))
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		fmt.Fprint(w, content)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code><Message>Not found</Message></Error>`)
+	}
+}
+
+// list serves a page of keys with the requested prefix, continuing after
+// the key given as the continuation token
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	after := r.URL.Query().Get("continuation-token")
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := listResult{Name: f.bucket, Prefix: prefix}
+	if len(keys) > f.pageSize {
+		keys = keys[:f.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, listObject{
+			Key:          key,
+			LastModified: objectTime.Format(time.RFC3339),
+			Size:         len(f.objects[key]),
+		})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result) //nolint:errcheck // the client reports a bad response
+}
+
+// isolateAWS keeps the test from reading the user's AWS configuration or
+// credentials
+func isolateAWS(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	isolateAWS(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "envkey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	fake := newFakeS3(t, "docs", map[string]string{
+		"api/index.html":      "<html><head><title>API</title></head><body><p>Reference</p></body></html>",
+		"api/v1/users.md":     "# Users\n\nThe users endpoint",
+		"api/v1/logo.png":     "PNG",
+		"api/v1/":             "",
+		"api/v2/orders.rst":   "Orders\n======\n\nThe orders endpoint",
+		"guide/intro.md":      "# Intro",
+		"changelog/v1.md":     "# Changes",
+		"api/v2/internal.xml": "<notes/>",
+	})
+
+	src, err := New(context.Background(), &types.Config{
+		S3Bucket:    "docs",
+		S3Prefix:    []string{"api/", "api/v1/", "changelog/"},
+		S3Endpoint:  fake.URL,
+		S3PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	docs, stats, err := src.Load(context.Background(), processor.Options{Source: "reference"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var names []string
+	for _, doc := range docs {
+		names = append(names, doc.FileName)
+		if doc.FileModified == nil || !doc.FileModified.Equal(objectTime) {
+			t.Errorf("%s: expected modified %v, got %v", doc.FileName, objectTime, doc.FileModified)
+		}
+		if doc.Source != "reference" {
+			t.Errorf("%s: expected source reference, got %q", doc.FileName, doc.Source)
+		}
+	}
+	sort.Strings(names)
+	expected := "api/index.html api/v1/users.md api/v2/orders.rst changelog/v1.md"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("expected documents %s, got %v", expected, names)
+	}
+	if stats.FilesProcessed != 4 || stats.FilesSkipped != 2 {
+		t.Errorf("expected 4 processed and 2 skipped, got %d and %d", stats.FilesProcessed, stats.FilesSkipped)
+	}
+
+	// Unsupported objects and overlapping prefixes are not downloaded,
+	// though a .xml object is, to detect its format from its content
+	if len(fake.gets) != 5 {
+		t.Errorf("expected 5 objects fetched, got %v", fake.gets)
+	}
+	for _, key := range fake.credentials {
+		if key != "envkey" {
+			t.Fatalf("expected requests signed with the environment credentials, got %q", key)
+		}
+	}
+}
+
+func TestLoadProfileCredentials(t *testing.T) {
+	dir := isolateAWS(t)
+	credentials := "[default]\naws_access_key_id = defaultkey\naws_secret_access_key = x\n\n" +
+		"[docs]\naws_access_key_id = profilekey\naws_secret_access_key = y\n"
+	if err := os.WriteFile(filepath.Join(dir, "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := newFakeS3(t, "docs", map[string]string{"index.md": "# Index"})
+	src, err := New(context.Background(), &types.Config{
+		S3Bucket:    "docs",
+		S3Endpoint:  fake.URL,
+		S3PathStyle: true,
+		S3Profile:   "docs",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	docs, _, err := src.Load(context.Background(), processor.Options{StripPath: true})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(docs) != 1 || docs[0].FileName != "index.md" || docs[0].Title != "Index" {
+		t.Errorf("unexpected documents: %+v", docs)
+	}
+	if len(fake.credentials) == 0 || fake.credentials[0] != "profilekey" {
+		t.Errorf("expected requests signed with the profile credentials, got %v", fake.credentials)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	isolateAWS(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "envkey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+
+	old := maxObjectSize
+	defer func() { maxObjectSize = old }()
+	maxObjectSize = 20
+
+	fake := newFakeS3(t, "docs", map[string]string{
+		"big.md":   "# Big\n\n" + strings.Repeat("a", 50),
+		"small.md": "# Small",
+	})
+
+	// Oversize objects are skipped and reported
+	src, err := New(context.Background(), &types.Config{S3Bucket: "docs", S3Endpoint: fake.URL, S3PathStyle: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	docs, stats, err := src.Load(context.Background(), processor.Options{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(docs) != 1 || docs[0].FileName != "small.md" || len(stats.Errors) != 1 {
+		t.Errorf("expected only small.md and one error, got %d documents, errors %v", len(docs), stats.Errors)
+	}
+
+	// A bucket that cannot be listed is an error
+	src, err = New(context.Background(), &types.Config{S3Bucket: "missing", S3Endpoint: fake.URL, S3PathStyle: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, _, err := src.Load(context.Background(), processor.Options{}); err == nil {
+		t.Error("expected error for a missing bucket")
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{"", false},
+		{"http://localhost:9000", false},
+		{"https://s3.example.com", false},
+		{"localhost:9000", true},
+		{"ftp://example.com", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateEndpoint(tt.endpoint); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateEndpoint(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+		}
+	}
+}
@@ -9,33 +9,37 @@
 
 package types
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
 
-// DocumentType represents the type of source document
-type DocumentType int
+// DocumentType represents the type of source document. Its value is the
+// name of the converter for the format, so that formats can be added
+// without changing this package.
+type DocumentType string
 
+// The built-in document types
 const (
-	TypeUnknown DocumentType = iota
-	TypeHTML
-	TypeMarkdown
-	TypeReStructuredText
-	TypeSGML
+	TypeUnknown          DocumentType = ""
+	TypeHTML             DocumentType = "HTML"
+	TypeMarkdown         DocumentType = "Markdown"
+	TypeReStructuredText DocumentType = "reStructuredText"
+	TypeSGML             DocumentType = "SGML/DocBook"
+	TypeAsciiDoc         DocumentType = "AsciiDoc"
+	TypeNotebook         DocumentType = "Jupyter Notebook"
+	TypePlainText        DocumentType = "Plain Text"
+	TypeManPage          DocumentType = "Man Page"
 )
 
 // String returns the string representation of the DocumentType
 func (dt DocumentType) String() string {
-	switch dt {
-	case TypeHTML:
-		return "HTML"
-	case TypeMarkdown:
-		return "Markdown"
-	case TypeReStructuredText:
-		return "reStructuredText"
-	case TypeSGML:
-		return "SGML/DocBook"
-	default:
+	if dt == TypeUnknown {
 		return "Unknown"
 	}
+	return string(dt)
 }
 
 // Document represents a processed document with all extracted metadata
@@ -47,6 +51,91 @@ type Document struct {
 	FileCreated   *time.Time
 	FileModified  *time.Time
 	DocumentType  DocumentType
+	Metadata      map[string]interface{} // Front matter, if any
+	Author        string                 // Last commit author, for Git sources
+	Commit        string                 // Last commit SHA, for Git sources
+	GitURL        string                 // Repository the document was loaded from, for Git sources
+	GitRef        string                 // Git ref the document was loaded from
+	GitCommit     string                 // Commit checked out when the document was loaded, for Git sources
+	Source        string                 // Name of the configured source the document was loaded from
+}
+
+// FileMetadata holds metadata for a file supplied by its source (such as
+// Git history), which takes precedence over the filesystem
+type FileMetadata struct {
+	Created  *time.Time
+	Modified *time.Time
+	Author   string
+	Commit   string
+}
+
+// Source types
+const (
+	SourceLocal = "local"
+	SourceGit   = "git"
+	SourceWeb   = "web"
+	SourceS3    = "s3"
+)
+
+// Output formats of external converter commands
+const (
+	ConverterOutputMarkdown = "markdown"
+	ConverterOutputJSON     = "json"
+)
+
+// ConverterConfig describes an external program that converts documents
+// with the given extensions to Markdown
+type ConverterConfig struct {
+	Name       string        // Format name, stored as the document type
+	Extensions []string      // Extensions of the format, such as ".java"
+	Command    []string      // Program and arguments; {file} is replaced by a file holding the document, which is otherwise given on stdin
+	Output     string        // ConverterOutputMarkdown or ConverterOutputJSON
+	Timeout    time.Duration // Time allowed for each document
+}
+
+// SourceConfig describes a single document source: a set of local paths or
+// patterns, a Git repository, a website, or an S3 bucket
+type SourceConfig struct {
+	Name string // Identifies the source (defaults to its path or URL)
+	Type string // SourceLocal, SourceGit, SourceWeb or SourceS3
+
+	// Local source
+	Paths []string
+
+	// Git source
+	GitURL     string
+	GitBranch  string
+	GitTag     string
+	GitRefs    []string
+	GitDocPath []string
+
+	// Git credentials (default to the global settings)
+	GitTokenEnv string
+	GitUsername string
+	GitSSHKey   string
+
+	// Web source
+	WebURLs      []string
+	WebSitemap   string
+	WebPrefixes  []string
+	WebMaxDepth  int
+	WebMaxPages  int
+	WebRateLimit float64
+
+	// S3 source
+	S3Bucket    string
+	S3Prefixes  []string
+	S3Endpoint  string
+	S3Region    string
+	S3PathStyle bool
+	S3Profile   string
+
+	StripPath bool
+
+	// Custom column values for documents from this source, overriding the
+	// global values (column name -> value or type)
+	CustomColumns     map[string]string
+	CustomColumnTypes map[string]string
 }
 
 // Config represents the application configuration
@@ -54,15 +143,67 @@ type Config struct {
 	// Source configuration - Local (mutually exclusive with Git source)
 	Source    []string // Source paths/patterns (supports multiple via repeated flag or YAML list)
 	StripPath bool
+	FilesFrom string // File listing paths to load, or - for stdin
+	Stdin     bool   // Load one document from stdin
+	StdinName string // Name (and so format) of the document read from stdin
+
+	// File selection within directories and glob patterns
+	Include       []string // Patterns of the files to load (default: all)
+	Exclude       []string // Patterns of the files and directories to leave out
+	NoIgnoreFiles bool     // Do not honor .gitignore and .docloaderignore files
+
+	// Formats of files matching patterns, overriding the extension
+	// (pattern -> format name)
+	FormatMap map[string]string
+
+	// External programs that convert formats to Markdown
+	Converters []ConverterConfig
+
+	// Front matter handling
+	StripFrontMatter bool // Remove front matter from the stored content
+
+	// Jupyter notebook handling
+	NotebookOutputs     bool // Include the text outputs of code cells
+	NotebookOutputLimit int  // Size in bytes at which each output is truncated (0 for no limit)
 
 	// Source configuration - Git (mutually exclusive with local source)
-	GitURL       string   // Git repository URL
-	GitBranch    string   // Branch to checkout (mutually exclusive with GitTag)
-	GitTag       string   // Tag to checkout (mutually exclusive with GitBranch)
-	GitDocPath   []string // Paths within repo to process (supports multiple patterns)
-	GitCloneDir  string   // Directory to store cloned repos (default: temp)
-	GitKeepClone bool     // Keep cloned repo after processing
-	GitSkipFetch bool     // Skip fetch if repo already exists
+	GitURL         string   // Git repository URL
+	GitBranch      string   // Branch to checkout (mutually exclusive with GitTag)
+	GitTag         string   // Tag to checkout (mutually exclusive with GitBranch)
+	GitDocPath     []string // Paths within repo to process (supports multiple patterns)
+	GitCloneDir    string   // Directory to store cloned repos (default: temp)
+	GitKeepClone   bool     // Keep cloned repo after processing
+	GitSkipFetch   bool     // Skip fetch if repo already exists
+	GitRefs        []string // Branches, tags or tag globs to load together (via worktrees)
+	GitFullHistory bool     // Clone full history (for accurate per-file timestamps)
+	GitIncremental bool     // Only load files changed since the last loaded commit
+	GitTokenEnv    string   // Environment variable holding an HTTPS access token
+	GitUsername    string   // Username sent with the token (default: x-access-token)
+	GitSSHKey      string   // SSH private key for SSH repository URLs
+	GitSubmodules  bool     // Initialize submodules within the doc paths
+	GitLFS         string   // Git LFS pointer files: skip (default) or fetch
+	GitBackend     string   // Git implementation: exec (default) or go-git
+
+	// Source configuration - Web
+	WebURL       []string // Pages to start crawling from
+	WebSitemap   string   // sitemap.xml listing pages to load
+	WebPrefix    []string // URL prefixes pages must be within (default: the start URLs' directories)
+	WebMaxDepth  int      // Links to follow from a start page
+	WebMaxPages  int      // Pages to load at most
+	WebRateLimit float64  // Requests per second (0 for no limit)
+
+	// Source configuration - S3
+	S3Bucket    string   // Bucket to load objects from
+	S3Prefix    []string // Key prefixes to load (default: the whole bucket)
+	S3Endpoint  string   // Endpoint URL of an S3-compatible service (default: AWS)
+	S3Region    string   // Region (default: from the environment or profile)
+	S3PathStyle bool     // Address the bucket in the path rather than the host name
+	S3Profile   string   // Shared configuration profile to take credentials from
+
+	// Additional sources from the configuration file's sources list; the
+	// local, Git, web and S3 source options above form the first entries
+	// (see SourceEntries)
+	Sources []SourceConfig
 
 	// Database configuration
 	DBHost     string
@@ -87,10 +228,30 @@ type Config struct {
 	ColumnFileModified  string
 	ColumnRowCreated    string
 	ColumnRowUpdated    string
+	ColumnRunID         string
+	ColumnAuthor        string            // Last commit author, for Git sources
+	ColumnCommit        string            // Last commit SHA, for Git sources
+	ColumnGitRef        string            // Git ref (version) the document was loaded from
+	ColumnMetadata      string            // JSONB column receiving the whole front matter block
+	FrontMatterColumns  map[string]string // Front matter key -> column name
+
+	// Run log table (one row per loader run, optional)
+	RunLogTable string
+
+	// State table (last loaded commit per target and Git source, optional)
+	StateTable string
 
-	// Custom metadata columns (column name -> value)
+	// History table (previous versions of updated documents, optional)
+	HistoryTable string
+
+	// Custom metadata columns (column name -> value, which may be a
+	// text/template evaluated per document)
 	CustomColumns map[string]string
 
+	// Postgres types of custom metadata columns (column name -> type);
+	// columns without a type are bound as text
+	CustomColumnTypes map[string]string
+
 	// Operation mode
 	UpdateMode bool
 
@@ -98,15 +259,228 @@ type Config struct {
 	ConfigFile string
 }
 
+// SourceEntries returns all configured sources: the local paths given with
+// the source option, then the Git repository given with the git-url option,
+// then the website given with the web-url or web-sitemap options, then the
+// bucket given with the s3-bucket option, then the entries of the sources
+// list
+func (c *Config) SourceEntries() []SourceConfig {
+	var entries []SourceConfig
+
+	if len(c.Source) > 0 {
+		entries = append(entries, SourceConfig{
+			Name:      strings.Join(c.Source, ","),
+			Type:      SourceLocal,
+			Paths:     c.Source,
+			StripPath: c.StripPath,
+		})
+	}
+
+	if c.GitURL != "" {
+		entries = append(entries, SourceConfig{
+			Name:       c.GitURL,
+			Type:       SourceGit,
+			GitURL:     c.GitURL,
+			GitBranch:  c.GitBranch,
+			GitTag:     c.GitTag,
+			GitRefs:    c.GitRefs,
+			GitDocPath: c.GitDocPath,
+			StripPath:  c.StripPath,
+		})
+	}
+
+	if len(c.WebURL) > 0 || c.WebSitemap != "" {
+		name := c.WebSitemap
+		if name == "" {
+			name = strings.Join(c.WebURL, ",")
+		}
+		entries = append(entries, SourceConfig{
+			Name:         name,
+			Type:         SourceWeb,
+			WebURLs:      c.WebURL,
+			WebSitemap:   c.WebSitemap,
+			WebPrefixes:  c.WebPrefix,
+			WebMaxDepth:  c.WebMaxDepth,
+			WebMaxPages:  c.WebMaxPages,
+			WebRateLimit: c.WebRateLimit,
+		})
+	}
+
+	if c.S3Bucket != "" {
+		entries = append(entries, SourceConfig{
+			Name:        S3SourceName(c.S3Bucket, c.S3Prefix),
+			Type:        SourceS3,
+			S3Bucket:    c.S3Bucket,
+			S3Prefixes:  c.S3Prefix,
+			S3Endpoint:  c.S3Endpoint,
+			S3Region:    c.S3Region,
+			S3PathStyle: c.S3PathStyle,
+			S3Profile:   c.S3Profile,
+			StripPath:   c.StripPath,
+		})
+	}
+
+	return append(entries, c.Sources...)
+}
+
+// S3SourceName names an S3 source after its bucket and key prefixes
+func S3SourceName(bucket string, prefixes []string) string {
+	if len(prefixes) == 0 {
+		return "s3://" + bucket
+	}
+	names := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		names[i] = "s3://" + bucket + "/" + strings.TrimPrefix(prefix, "/")
+	}
+	return strings.Join(names, ",")
+}
+
+// SourceByName returns the entry of the sources list with the given name,
+// or nil if there is none
+func (c *Config) SourceByName(name string) *SourceConfig {
+	for i := range c.Sources {
+		if c.Sources[i].Name == name {
+			return &c.Sources[i]
+		}
+	}
+	return nil
+}
+
+// ForSource returns a copy of the configuration with the source options set
+// from a single source entry, for use by code that loads one source
+func (c *Config) ForSource(src SourceConfig) *Config {
+	cfg := *c
+	cfg.Source = src.Paths
+	cfg.StripPath = src.StripPath
+	cfg.GitURL = src.GitURL
+	cfg.GitBranch = src.GitBranch
+	cfg.GitTag = src.GitTag
+	cfg.GitRefs = src.GitRefs
+	cfg.GitDocPath = src.GitDocPath
+	cfg.WebURL = src.WebURLs
+	cfg.WebSitemap = src.WebSitemap
+	cfg.WebPrefix = src.WebPrefixes
+	cfg.WebMaxDepth = src.WebMaxDepth
+	cfg.WebMaxPages = src.WebMaxPages
+	cfg.WebRateLimit = src.WebRateLimit
+	cfg.S3Bucket = src.S3Bucket
+	cfg.S3Prefix = src.S3Prefixes
+	cfg.S3Endpoint = src.S3Endpoint
+	cfg.S3Region = src.S3Region
+	cfg.S3PathStyle = src.S3PathStyle
+	cfg.S3Profile = src.S3Profile
+	if src.GitTokenEnv != "" {
+		cfg.GitTokenEnv = src.GitTokenEnv
+	}
+	if src.GitUsername != "" {
+		cfg.GitUsername = src.GitUsername
+	}
+	if src.GitSSHKey != "" {
+		cfg.GitSSHKey = src.GitSSHKey
+	}
+	cfg.Sources = nil
+	return &cfg
+}
+
+// columnTypes lists the Postgres types that custom column values may be
+// cast to
+var columnTypes = map[string]bool{
+	"text":             true,
+	"varchar":          true,
+	"smallint":         true,
+	"integer":          true,
+	"int":              true,
+	"bigint":           true,
+	"numeric":          true,
+	"real":             true,
+	"double precision": true,
+	"boolean":          true,
+	"bool":             true,
+	"json":             true,
+	"jsonb":            true,
+	"date":             true,
+	"timestamp":        true,
+	"timestamptz":      true,
+	"uuid":             true,
+}
+
+// IsSupportedColumnType returns true if custom column values may be cast
+// to the given Postgres type (or an array of it, e.g. "text[]")
+func IsSupportedColumnType(colType string) bool {
+	colType = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(colType)), "[]")
+	return columnTypes[colType]
+}
+
+// RunInfo describes a single invocation of the loader
+type RunInfo struct {
+	ID        string
+	StartedAt time.Time
+	Sources   []string
+	GitURL    string
+	GitRef    string
+	GitCommit string
+	Checkouts []GitCheckout // Each Git ref loaded, with its commit
+	Version   string
+	Commit    string
+}
+
+// GitCheckout is a Git ref loaded by a run and the commit it resolved to
+type GitCheckout struct {
+	URL    string
+	Ref    string
+	Commit string
+}
+
+// NewRunInfo creates a RunInfo with a random (UUID v4 formatted) ID
+func NewRunInfo() *RunInfo {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) //nolint:errcheck // crypto/rand.Read never returns an error
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+	id := h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+
+	return &RunInfo{
+		ID:        id,
+		StartedAt: time.Now(),
+	}
+}
+
+// FileChanges lists the rows to rename or delete in the target table for an
+// incremental load, by stored file name
+type FileChanges struct {
+	Ref     string            // Git ref the changes were made on
+	Renamed map[string]string // Old file name -> new file name
+	Deleted []string
+}
+
 // Stats tracks processing statistics
 type Stats struct {
 	FilesProcessed int
 	FilesSkipped   int
+	FilesExcluded  int // Files left out by include/exclude patterns or ignore files
 	FilesInserted  int
 	FilesUpdated   int
+	FilesDeleted   int
+	FilesRenamed   int
+	LFSPointers    int // Git LFS pointer files skipped (also counted as skipped)
 	Errors         []error
 }
 
+// Merge adds the counts and errors of another set of stats
+func (s *Stats) Merge(other *Stats) {
+	s.FilesProcessed += other.FilesProcessed
+	s.FilesSkipped += other.FilesSkipped
+	s.FilesExcluded += other.FilesExcluded
+	s.FilesInserted += other.FilesInserted
+	s.FilesUpdated += other.FilesUpdated
+	s.FilesDeleted += other.FilesDeleted
+	s.FilesRenamed += other.FilesRenamed
+	s.LFSPointers += other.LFSPointers
+	s.Errors = append(s.Errors, other.Errors...)
+}
+
 // AddError adds an error to the stats
 func (s *Stats) AddError(err error) {
 	s.Errors = append(s.Errors, err)
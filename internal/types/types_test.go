@@ -23,6 +23,7 @@ func TestDocumentTypeString(t *testing.T) {
 		{"HTML type", TypeHTML, "HTML"},
 		{"Markdown type", TypeMarkdown, "Markdown"},
 		{"RST type", TypeReStructuredText, "reStructuredText"},
+		{"SGML type", TypeSGML, "SGML/DocBook"},
 		{"Unknown type", TypeUnknown, "Unknown"},
 	}
 
@@ -64,3 +65,24 @@ func TestStatsHasErrors(t *testing.T) {
 		t.Error("expected HasErrors to return true after adding error")
 	}
 }
+
+func TestNewRunInfo(t *testing.T) {
+	run1 := NewRunInfo()
+	run2 := NewRunInfo()
+
+	if len(run1.ID) != 36 {
+		t.Errorf("expected 36 character run ID, got %q", run1.ID)
+	}
+
+	if run1.ID[14] != '4' {
+		t.Errorf("expected version 4 UUID, got %q", run1.ID)
+	}
+
+	if run1.ID == run2.ID {
+		t.Error("expected run IDs to be unique")
+	}
+
+	if run1.StartedAt.IsZero() {
+		t.Error("expected StartedAt to be set")
+	}
+}
@@ -0,0 +1,214 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package websource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsRules are the rules of a host's robots.txt that apply to the
+// crawler
+type robotsRules struct {
+	rules    []robotsRule
+	delay    time.Duration // Crawl-delay
+	disallow bool          // Disallow everything (robots.txt unavailable)
+}
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsKey identifies the host a robots.txt applies to
+func robotsKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// allowed returns true if the host's robots.txt allows the crawler to
+// fetch a URL, fetching the robots.txt the first time the host is seen
+func (c *Crawler) allowed(ctx context.Context, u *url.URL) (bool, error) {
+	key := robotsKey(u)
+	rules, ok := c.robots[key]
+	if !ok {
+		var err error
+		if rules, err = c.fetchRobots(ctx, u); err != nil {
+			return false, err
+		}
+		c.robots[key] = rules
+	}
+
+	target := u.EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return rules.allows(target), nil
+}
+
+// fetchRobots fetches and parses a host's robots.txt. As RFC 9309
+// requires, a missing robots.txt allows everything and one that cannot be
+// fetched disallows everything.
+func (c *Crawler) fetchRobots(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	resp, err := c.get(ctx, robotsURL, 0)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		fmt.Printf("Warning: not crawling %s, failed to fetch robots.txt: %v\n", robotsKey(u), err)
+		return &robotsRules{disallow: true}, nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		content, err := read(resp)
+		if err != nil {
+			fmt.Printf("Warning: not crawling %s, failed to read robots.txt: %v\n", robotsKey(u), err)
+			return &robotsRules{disallow: true}, nil
+		}
+		return parseRobots(content, userAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &robotsRules{}, nil
+	default:
+		fmt.Printf("Warning: not crawling %s, robots.txt returned HTTP %s\n", robotsKey(u), resp.Status)
+		return &robotsRules{disallow: true}, nil
+	}
+}
+
+// parseRobots parses the groups of a robots.txt that apply to the agent:
+// those naming it, or else those for all agents (*)
+func parseRobots(content []byte, agent string) *robotsRules {
+	var named, wildcard robotsRules
+	var matchesNamed, matchesAny, foundNamed bool
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				matchesNamed, matchesAny = false, false
+				inRules = false
+			}
+			if value == "*" {
+				matchesAny = true
+			} else if strings.EqualFold(value, agent) {
+				matchesNamed = true
+				foundNamed = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			// An empty Disallow allows everything, so adds no rule
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			if matchesNamed {
+				named.rules = append(named.rules, rule)
+			}
+			if matchesAny {
+				wildcard.rules = append(wildcard.rules, rule)
+			}
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			delay := time.Duration(seconds * float64(time.Second))
+			if matchesNamed {
+				named.delay = delay
+			}
+			if matchesAny {
+				wildcard.delay = delay
+			}
+		}
+	}
+
+	if foundNamed {
+		return &named
+	}
+	return &wildcard
+}
+
+// allows returns true if the rules allow a path (with any query string).
+// The longest matching rule applies, and Allow wins a tie.
+func (r *robotsRules) allows(target string) bool {
+	if r.disallow {
+		return false
+	}
+
+	allow := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, target) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			longest = len(rule.pattern)
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// crawlDelay returns the time to leave between requests to the host
+func (r *robotsRules) crawlDelay() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.delay
+}
+
+// robotsMatch matches a path against a robots.txt pattern, in which *
+// matches any characters and a trailing $ anchors the pattern at the end
+// of the path
+func robotsMatch(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(target, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i, part := range parts[1:] {
+		// The last part of an anchored pattern must end the path
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(target[pos:], part)
+		}
+		idx := strings.Index(target[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	return !anchored || pos == len(target)
+}
@@ -0,0 +1,88 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package websource
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		target   string
+		expected bool
+	}{
+		{"/", "/docs/", true},
+		{"/docs", "/docs/index.html", true},
+		{"/docs/", "/doc", false},
+		{"/*.md", "/docs/intro.md", true},
+		{"/*.md$", "/docs/intro.md", true},
+		{"/*.md$", "/docs/intro.md?raw=1", false},
+		{"/index.html$", "/index.html", true},
+		{"/index.html$", "/index.html/more", false},
+		{"/*/private/*.html", "/a/private/b.html", true},
+		{"/*/private/*.html", "/a/public/b.html", false},
+		{"/search?q=", "/search?q=docs", true},
+	}
+
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.target); got != tt.expected {
+			t.Errorf("robotsMatch(%q, %q) = %v, expected %v", tt.pattern, tt.target, got, tt.expected)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	content := []byte(`User-agent: Googlebot
+Disallow: /
+
+user-agent: *
+Disallow: /tmp/ # temporary files
+Crawl-delay: 1.5
+
+User-agent: other
+User-agent: *
+Disallow: /drafts/
+Allow: /drafts/published/
+Disallow:
+`)
+
+	rules := parseRobots(content, userAgent)
+	if rules.delay != 1500*time.Millisecond {
+		t.Errorf("expected crawl delay 1.5s, got %v", rules.delay)
+	}
+
+	tests := []struct {
+		target   string
+		expected bool
+	}{
+		{"/", true},
+		{"/tmp/file.html", false},
+		{"/drafts/new.html", false},
+		{"/drafts/published/post.html", true},
+	}
+	for _, tt := range tests {
+		if got := rules.allows(tt.target); got != tt.expected {
+			t.Errorf("allows(%q) = %v, expected %v", tt.target, got, tt.expected)
+		}
+	}
+
+	// A group naming the crawler replaces the * groups
+	rules = parseRobots([]byte("User-agent: *\nDisallow: /\n\nUser-agent: PGEDGE-DOCLOADER\nDisallow: /private/\n"), userAgent)
+	if !rules.allows("/docs/") || rules.allows("/private/x") || rules.delay != 0 {
+		t.Errorf("expected the named group to apply, got %+v", rules)
+	}
+
+	// An empty robots.txt allows everything
+	if !parseRobots(nil, userAgent).allows("/anything") {
+		t.Error("expected an empty robots.txt to allow everything")
+	}
+}
@@ -0,0 +1,151 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package websource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// sitemapDoc is a sitemap (urlset) or sitemap index (sitemapindex)
+type sitemapDoc struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// lastModFormats are the W3C datetime formats used in sitemaps
+var lastModFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+// readSitemaps reads the configured sitemap and the sitemaps listed in any
+// sitemap index, returning the pages they list. Failing to read the
+// configured sitemap is an error; failing to read a listed one is
+// recorded in stats.
+func (c *Crawler) readSitemaps(ctx context.Context, stats *types.Stats) ([]queued, error) {
+	var entries []queued
+	pending := []*url.URL{c.sitemap}
+	seen := map[string]bool{c.sitemap.String(): true}
+
+	for read := 0; len(pending) > 0; read++ {
+		if read >= maxSitemaps {
+			fmt.Printf("Reached the limit of %d sitemaps, not reading %d more\n", maxSitemaps, len(pending))
+			break
+		}
+		sitemapURL := pending[0]
+		pending = pending[1:]
+
+		fmt.Printf("Reading sitemap: %s\n", sitemapURL)
+		doc, err := c.fetchSitemap(ctx, sitemapURL)
+		if err != nil {
+			if read == 0 || ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to read sitemap %s: %w", sitemapURL, err)
+			}
+			fmt.Printf("Error reading sitemap %s: %v\n", sitemapURL, err)
+			stats.AddError(fmt.Errorf("sitemap %s: %w", sitemapURL, err))
+			continue
+		}
+
+		for _, s := range doc.Sitemaps {
+			u, err := sitemapURL.Parse(strings.TrimSpace(s.Loc))
+			if err != nil {
+				continue
+			}
+			u = normalize(u)
+			if !seen[u.String()] {
+				seen[u.String()] = true
+				pending = append(pending, u)
+			}
+		}
+
+		for _, entry := range doc.URLs {
+			u, err := sitemapURL.Parse(strings.TrimSpace(entry.Loc))
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				fmt.Printf("Skipping invalid sitemap URL: %s\n", entry.Loc)
+				stats.FilesSkipped++
+				continue
+			}
+			entries = append(entries, queued{
+				url:      normalize(u),
+				modified: parseLastMod(entry.LastMod),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// fetchSitemap fetches and parses a sitemap, which may be gzipped
+func (c *Crawler) fetchSitemap(ctx context.Context, u *url.URL) (*sitemapDoc, error) {
+	resp, err := c.get(ctx, u, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	content, err := read(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gzipped sitemaps are served as such, rather than with a
+	// Content-Encoding the HTTP client would decode
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		if content, err = io.ReadAll(io.LimitReader(gz, maxPageSize+1)); err != nil {
+			return nil, err
+		}
+		if int64(len(content)) > maxPageSize {
+			return nil, fmt.Errorf("larger than %d bytes uncompressed", maxPageSize)
+		}
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+	return &doc, nil
+}
+
+// parseLastMod parses a sitemap lastmod value, returning nil if it is
+// missing or invalid
+func parseLastMod(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, format := range lastModFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,443 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package websource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/pgedge/pgedge-docloader/internal/converter"
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+// userAgent identifies the crawler to web servers, and is the name it
+// looks for in robots.txt
+const userAgent = "pgedge-docloader"
+
+// Limits on what is fetched, which tests may lower
+var (
+	maxPageSize    int64 = 64 << 20 // Size of one page or sitemap
+	maxSitemaps          = 1000     // Sitemaps read, including those listed in sitemap indexes
+	requestTimeout       = 30 * time.Second
+)
+
+// serverPageExtensions are the extensions of links that are fetched as
+// pages although the converter does not recognize them; links with other
+// unrecognized extensions (images, PDFs and so on) are not followed
+var serverPageExtensions = map[string]bool{
+	".asp":   true,
+	".aspx":  true,
+	".cgi":   true,
+	".jsp":   true,
+	".php":   true,
+	".shtml": true,
+}
+
+// Crawler loads pages from a website, starting from a list of URLs and the
+// pages listed in a sitemap, and following links within a set of URL
+// prefixes
+type Crawler struct {
+	starts   []*url.URL
+	sitemap  *url.URL
+	prefixes []*url.URL
+	maxDepth int           // Links followed from a start page
+	maxPages int           // Pages fetched at most (0 for no limit)
+	interval time.Duration // Minimum time between requests
+	client   *http.Client
+	robots   map[string]*robotsRules // By scheme and host
+	last     time.Time               // Time of the last request
+}
+
+// queued is a page waiting to be fetched
+type queued struct {
+	url      *url.URL
+	depth    int        // Links followed to reach the page
+	modified *time.Time // Last modification time given by the sitemap
+}
+
+// page is a fetched page
+type page struct {
+	url      *url.URL // After any redirects
+	content  []byte
+	docType  types.DocumentType
+	modified *time.Time
+}
+
+// New creates a Crawler from configuration
+func New(cfg *types.Config) (*Crawler, error) {
+	if err := ValidateURLs(cfg.WebURL, cfg.WebSitemap, cfg.WebPrefix); err != nil {
+		return nil, err
+	}
+
+	c := &Crawler{
+		maxDepth: cfg.WebMaxDepth,
+		maxPages: cfg.WebMaxPages,
+		client:   &http.Client{Timeout: requestTimeout},
+		robots:   make(map[string]*robotsRules),
+	}
+	if cfg.WebRateLimit > 0 {
+		c.interval = time.Duration(float64(time.Second) / cfg.WebRateLimit)
+	}
+
+	for _, raw := range cfg.WebURL {
+		u, _ := parseURL(raw) //nolint:errcheck // validated above
+		c.starts = append(c.starts, u)
+	}
+	if cfg.WebSitemap != "" {
+		c.sitemap, _ = parseURL(cfg.WebSitemap) //nolint:errcheck // validated above
+	}
+
+	// Without prefixes, pages must be within the directory of a start URL
+	// or the sitemap
+	for _, raw := range cfg.WebPrefix {
+		u, _ := parseURL(raw) //nolint:errcheck // validated above
+		c.prefixes = append(c.prefixes, u)
+	}
+	if len(c.prefixes) == 0 {
+		dirs := c.starts
+		if c.sitemap != nil {
+			dirs = append(dirs[:len(dirs):len(dirs)], c.sitemap)
+		}
+		for _, u := range dirs {
+			prefix := *u
+			prefix.Path = u.Path[:strings.LastIndex(u.Path, "/")+1]
+			prefix.RawPath = ""
+			prefix.RawQuery = ""
+			c.prefixes = append(c.prefixes, &prefix)
+		}
+	}
+
+	return c, nil
+}
+
+// ValidateURLs checks that the start, sitemap and prefix URLs of a web
+// source are absolute http or https URLs
+func ValidateURLs(urls []string, sitemap string, prefixes []string) error {
+	all := append(append([]string{}, urls...), prefixes...)
+	if sitemap != "" {
+		all = append(all, sitemap)
+	}
+	for _, raw := range all {
+		if _, err := parseURL(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseURL parses an absolute http or https URL
+func parseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL '%s': expected an http or https URL", raw)
+	}
+	return normalize(u), nil
+}
+
+// normalize lowercases the scheme and host of a URL and removes its
+// fragment, so that each page is fetched once
+func normalize(u *url.URL) *url.URL {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	n.Fragment = ""
+	n.RawFragment = ""
+	if n.Path == "" {
+		n.Path = "/"
+		n.RawPath = ""
+	}
+	return &n
+}
+
+// inScope returns true if a URL is within one of the crawler's prefixes
+func (c *Crawler) inScope(u *url.URL) bool {
+	for _, prefix := range c.prefixes {
+		if u.Scheme == prefix.Scheme && u.Host == prefix.Host &&
+			strings.HasPrefix(u.Path, prefix.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load crawls the website and converts the pages it finds into documents,
+// named by URL. Pages disallowed by robots.txt, outside the prefixes or of
+// unsupported types are skipped, and per-page errors are recorded in the
+// returned stats.
+func (c *Crawler) Load(ctx context.Context, opts processor.Options) ([]*types.Document, *types.Stats, error) {
+	stats := &types.Stats{}
+	var documents []*types.Document
+
+	// Pages are named by their full URL, and have no file metadata
+	opts.StripPath = false
+	opts.FileMetadata = nil
+	formats := opts.Registry()
+
+	var queue []queued
+	seen := make(map[string]bool)
+	enqueue := func(item queued) {
+		key := item.url.String()
+		if !seen[key] {
+			seen[key] = true
+			queue = append(queue, item)
+		}
+	}
+
+	if c.sitemap != nil {
+		entries, err := c.readSitemaps(ctx, stats)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			if !c.inScope(entry.url) {
+				fmt.Printf("Skipping sitemap page outside the prefixes: %s\n", entry.url)
+				stats.FilesSkipped++
+				continue
+			}
+			enqueue(entry)
+		}
+	}
+	for _, u := range c.starts {
+		enqueue(queued{url: u})
+	}
+
+	fetched := 0
+	for len(queue) > 0 {
+		if c.maxPages > 0 && fetched >= c.maxPages {
+			fmt.Printf("Reached the limit of %d pages, not crawling %d more\n", c.maxPages, len(queue))
+			break
+		}
+		item := queue[0]
+		queue = queue[1:]
+
+		allowed, err := c.allowed(ctx, item.url)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			fmt.Printf("Skipping page disallowed by robots.txt: %s\n", item.url)
+			stats.FilesSkipped++
+			continue
+		}
+
+		fetched++
+		p, err := c.fetch(ctx, item.url, formats)
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if err != nil {
+			fmt.Printf("Error fetching page %s: %v\n", item.url, err)
+			stats.AddError(fmt.Errorf("page %s: %w", item.url, err))
+			stats.FilesSkipped++
+			continue
+		}
+
+		// A page redirected elsewhere is loaded under its final URL
+		if key := p.url.String(); key != item.url.String() {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if !c.inScope(p.url) {
+				fmt.Printf("Skipping page redirected outside the prefixes: %s -> %s\n", item.url, p.url)
+				stats.FilesSkipped++
+				continue
+			}
+		}
+
+		if p.docType == types.TypeHTML && item.depth < c.maxDepth {
+			for _, link := range c.links(p, formats) {
+				enqueue(queued{url: link, depth: item.depth + 1})
+			}
+		}
+
+		if p.docType == types.TypeUnknown {
+			fmt.Printf("Skipping unsupported page: %s\n", p.url)
+			stats.FilesSkipped++
+			continue
+		}
+
+		modified := p.modified
+		if modified == nil {
+			modified = item.modified
+		}
+		doc, err := processor.ProcessContent(p.url.String(), p.content, p.docType, modified, opts)
+		if err != nil {
+			fmt.Printf("Error processing page %s: %v\n", p.url, err)
+			stats.AddError(fmt.Errorf("page %s: %w", p.url, err))
+			stats.FilesSkipped++
+			continue
+		}
+
+		documents = append(documents, doc)
+		stats.FilesProcessed++
+	}
+
+	return documents, stats, nil
+}
+
+// get requests a URL, waiting as needed to keep to the rate limit and the
+// host's robots.txt crawl delay
+func (c *Crawler) get(ctx context.Context, u *url.URL, delay time.Duration) (*http.Response, error) {
+	if delay < c.interval {
+		delay = c.interval
+	}
+	if wait := delay - time.Since(c.last); !c.last.IsZero() && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	c.last = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return c.client.Do(req)
+}
+
+// read reads a response body of at most maxPageSize bytes
+func read(resp *http.Response) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxPageSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxPageSize)
+	}
+	return content, nil
+}
+
+// fetch fetches a page and determines its document type, from its content
+// type or else from the extension of its URL
+func (c *Crawler) fetch(ctx context.Context, u *url.URL, formats *converter.Registry) (*page, error) {
+	resp, err := c.get(ctx, u, c.robots[robotsKey(u)].crawlDelay())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	content, err := read(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &page{
+		url:     normalize(resp.Request.URL),
+		content: content,
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")) //nolint:errcheck // unparsable types fall back to the extension
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		p.docType = types.TypeHTML
+	case "text/markdown", "text/x-markdown":
+		p.docType = types.TypeMarkdown
+	case "text/x-rst", "text/prs.fallenstein.rst":
+		p.docType = types.TypeReStructuredText
+	case "text/asciidoc", "text/x-asciidoc":
+		p.docType = types.TypeAsciiDoc
+	case "application/x-ipynb+json":
+		p.docType = types.TypeNotebook
+	case "text/troff":
+		p.docType = types.TypeManPage
+	default:
+		p.docType = formats.DetectContentType(p.url.Path, content)
+	}
+
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		p.modified = &modified
+	}
+
+	return p, nil
+}
+
+// links returns the URLs within the prefixes that an HTML page links to,
+// unless the page asks for its links not to be followed
+func (c *Crawler) links(p *page, formats *converter.Registry) []*url.URL {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(p.content))
+	if err != nil {
+		return nil
+	}
+
+	nofollow := false
+	doc.Find("meta[name]").Each(func(_ int, s *goquery.Selection) {
+		name := strings.ToLower(s.AttrOr("name", ""))
+		if (name == "robots" || name == userAgent) && hasToken(s.AttrOr("content", ""), "nofollow") {
+			nofollow = true
+		}
+	})
+	if nofollow {
+		return nil
+	}
+
+	base := p.url
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if ref, err := url.Parse(strings.TrimSpace(href)); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	var links []*url.URL
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		if hasToken(s.AttrOr("rel", ""), "nofollow") {
+			return
+		}
+		ref, err := url.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil {
+			return
+		}
+		u := base.ResolveReference(ref)
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return
+		}
+		u = normalize(u)
+		if !c.inScope(u) {
+			return
+		}
+		if ext := strings.ToLower(path.Ext(u.Path)); ext != "" &&
+			!formats.IsSupported(u.Path) && !serverPageExtensions[ext] {
+			return
+		}
+		links = append(links, u)
+	})
+
+	return links
+}
+
+// hasToken returns true if a comma or space separated attribute value
+// contains the token, ignoring case
+func hasToken(value, token string) bool {
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,392 @@
+//-------------------------------------------------------------------------
+//
+// pgEdge Docloader
+//
+// Copyright (c) 2025 - 2026, pgEdge, Inc.
+// This software is released under The PostgreSQL License
+//
+//-------------------------------------------------------------------------
+
+package websource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pgedge/pgedge-docloader/internal/processor"
+	"github.com/pgedge/pgedge-docloader/internal/types"
+)
+
+var pageModified = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+// testSite serves a small website and records the paths requested
+type testSite struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requested []string
+}
+
+// newTestSite serves pages (path -> HTML body) and other files (path ->
+// content type and body), with robots.txt if given
+func newTestSite(t *testing.T, pages map[string]string, files map[string][2]string) *testSite {
+	t.Helper()
+	site := &testSite{}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requested = append(site.requested, r.URL.RequestURI())
+		site.mu.Unlock()
+
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		if body, ok := pages[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Last-Modified", pageModified.Format(http.TimeFormat))
+			fmt.Fprint(w, body)
+			return
+		}
+		if file, ok := files[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", file[0])
+			fmt.Fprint(w, file[1])
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// fetched returns the paths requested, other than robots.txt
+func (s *testSite) fetched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var paths []string
+	for _, p := range s.requested {
+		if p != "/robots.txt" {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// htmlPage returns an HTML page with a title and links
+func htmlPage(title string, links ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<html><head><title>%s</title></head><body><h1>%s</h1>", title, title)
+	for _, link := range links {
+		fmt.Fprintf(&b, `<a href="%s">%s</a>`, link, link)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+// load crawls with the configuration, without a rate limit
+func load(t *testing.T, cfg *types.Config) ([]*types.Document, *types.Stats) {
+	t.Helper()
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	docs, stats, err := c.Load(context.Background(), processor.Options{Source: "web", StripPath: true})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return docs, stats
+}
+
+// docNames returns the file names of documents, sorted
+func docNames(docs []*types.Document) []string {
+	var names []string
+	for _, doc := range docs {
+		names = append(names, doc.FileName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCrawl(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/docs/":                  htmlPage("Home", "intro.html", "guide/", "#top", "/outside.html", "https://example.com/", "mailto:a@example.com", "logo.png"),
+		"/docs/intro.html":        htmlPage("Intro", "/docs/", "guide/#install"),
+		"/docs/guide/":            htmlPage("Guide", "deep.html"),
+		"/docs/guide/deep.html":   htmlPage("Deep", "deeper.html"),
+		"/docs/guide/deeper.html": htmlPage("Deeper"),
+		"/outside.html":           htmlPage("Outside"),
+	}, map[string][2]string{
+		"/docs/notes.md": {"text/plain", "# Notes"},
+	})
+
+	docs, stats := load(t, &types.Config{
+		WebURL:      []string{site.URL + "/docs/", site.URL + "/docs/notes.md"},
+		WebMaxDepth: 2,
+	})
+
+	expected := []string{
+		site.URL + "/docs/",
+		site.URL + "/docs/guide/",
+		site.URL + "/docs/guide/deep.html",
+		site.URL + "/docs/intro.html",
+		site.URL + "/docs/notes.md",
+	}
+	if got := docNames(docs); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected documents %v, got %v", expected, got)
+	}
+	if stats.FilesProcessed != 5 || stats.FilesSkipped != 0 {
+		t.Errorf("expected 5 processed and 0 skipped, got %d and %d", stats.FilesProcessed, stats.FilesSkipped)
+	}
+
+	// Pages are fetched once, and links outside the prefix, beyond the
+	// depth limit or to unsupported files are not followed
+	if got := site.fetched(); len(got) != 5 {
+		t.Errorf("expected 5 requests, got %v", got)
+	}
+
+	for _, doc := range docs {
+		if doc.Source != "web" {
+			t.Errorf("%s: expected source web, got %q", doc.FileName, doc.Source)
+		}
+		switch doc.FileName {
+		case site.URL + "/docs/":
+			if doc.Title != "Home" || doc.DocumentType != types.TypeHTML {
+				t.Errorf("unexpected home page: %q %v", doc.Title, doc.DocumentType)
+			}
+			if doc.FileModified == nil || !doc.FileModified.Equal(pageModified) {
+				t.Errorf("expected modified %v, got %v", pageModified, doc.FileModified)
+			}
+		case site.URL + "/docs/notes.md":
+			if doc.DocumentType != types.TypeMarkdown || doc.FileModified != nil {
+				t.Errorf("unexpected Markdown page: %v %v", doc.DocumentType, doc.FileModified)
+			}
+		}
+	}
+}
+
+func TestCrawlLimits(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/":       htmlPage("Home", "a.html", "b.html", "c.html"),
+		"/a.html": htmlPage("A"),
+		"/b.html": htmlPage("B"),
+		"/c.html": htmlPage("C"),
+	}, nil)
+
+	// The page limit counts pages fetched
+	docs, _ := load(t, &types.Config{WebURL: []string{site.URL}, WebMaxDepth: 3, WebMaxPages: 2})
+	if len(docs) != 2 {
+		t.Errorf("expected 2 documents with the page limit, got %v", docNames(docs))
+	}
+
+	// A depth of 0 loads only the start pages
+	docs, _ = load(t, &types.Config{WebURL: []string{site.URL + "/"}, WebMaxDepth: 0})
+	if len(docs) != 1 {
+		t.Errorf("expected only the start page at depth 0, got %v", docNames(docs))
+	}
+}
+
+func TestCrawlPrefixes(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/docs/v1/index.html": htmlPage("V1", "/docs/v2/index.html", "/blog/post.html"),
+		"/docs/v2/index.html": htmlPage("V2"),
+		"/blog/post.html":     htmlPage("Post"),
+	}, nil)
+
+	// An explicit prefix widens the crawl beyond the start directory
+	docs, _ := load(t, &types.Config{
+		WebURL:      []string{site.URL + "/docs/v1/index.html"},
+		WebPrefix:   []string{site.URL + "/docs/"},
+		WebMaxDepth: 1,
+	})
+	expected := []string{site.URL + "/docs/v1/index.html", site.URL + "/docs/v2/index.html"}
+	if got := docNames(docs); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected documents %v, got %v", expected, got)
+	}
+}
+
+func TestCrawlRobots(t *testing.T) {
+	robots := `# Test robots.txt
+User-agent: *
+Disallow: /
+
+User-agent: pgedge-docloader
+Disallow: /private/
+Allow: /private/public.html
+Disallow: /*.md$
+`
+	site := newTestSite(t, map[string]string{
+		"/":                    htmlPage("Home", "private/secret.html", "private/public.html", "notes.md", "open.html"),
+		"/private/secret.html": htmlPage("Secret"),
+		"/private/public.html": htmlPage("Public"),
+		"/open.html":           htmlPage("Open"),
+	}, map[string][2]string{
+		"/robots.txt": {"text/plain", robots},
+		"/notes.md":   {"text/markdown", "# Notes"},
+	})
+
+	docs, stats := load(t, &types.Config{WebURL: []string{site.URL + "/"}, WebMaxDepth: 1})
+	expected := []string{site.URL + "/", site.URL + "/open.html", site.URL + "/private/public.html"}
+	if got := docNames(docs); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected documents %v, got %v", expected, got)
+	}
+	if stats.FilesSkipped != 2 {
+		t.Errorf("expected 2 disallowed pages skipped, got %d", stats.FilesSkipped)
+	}
+	for _, p := range site.fetched() {
+		if p == "/private/secret.html" || p == "/notes.md" {
+			t.Errorf("fetched disallowed page %s", p)
+		}
+	}
+}
+
+func TestCrawlRobotsUnavailable(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, htmlPage("Home"))
+	}))
+	defer site.Close()
+
+	docs, stats := load(t, &types.Config{WebURL: []string{site.URL + "/"}})
+	if len(docs) != 0 || stats.FilesSkipped != 1 {
+		t.Errorf("expected nothing loaded when robots.txt is unavailable, got %v", docNames(docs))
+	}
+}
+
+func TestCrawlSitemap(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/docs/a.html":      htmlPage("A", "linked.html"),
+		"/docs/b.html":      htmlPage("B"),
+		"/docs/linked.html": htmlPage("Linked"),
+	}, map[string][2]string{
+		"/docs/index.md": {"text/markdown", "# Index"},
+	})
+	// The sitemaps refer to the server's own URL
+	handler := site.Config.Handler
+	site.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs/sitemap.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/docs/pages.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/docs/missing.xml</loc></sitemap>
+</sitemapindex>`, site.URL)
+		case "/docs/pages.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/docs/a.html</loc></url>
+  <url><loc>%[1]s/docs/b.html</loc></url>
+  <url><loc>%[1]s/docs/index.md</loc><lastmod>2023-07-14</lastmod></url>
+  <url><loc>%[1]s/other/c.html</loc></url>
+</urlset>`, site.URL)
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+
+	docs, stats := load(t, &types.Config{WebSitemap: site.URL + "/docs/sitemap.xml"})
+	expected := []string{site.URL + "/docs/a.html", site.URL + "/docs/b.html", site.URL + "/docs/index.md"}
+	if got := docNames(docs); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected documents %v, got %v", expected, got)
+	}
+	// The page outside the prefix is skipped, the missing sitemap recorded
+	if stats.FilesSkipped != 1 || len(stats.Errors) != 1 {
+		t.Errorf("expected 1 skipped and 1 error, got %d and %v", stats.FilesSkipped, stats.Errors)
+	}
+
+	// The sitemap's lastmod is used without a Last-Modified header
+	lastMod := time.Date(2023, 7, 14, 0, 0, 0, 0, time.UTC)
+	for _, doc := range docs {
+		if doc.FileName == site.URL+"/docs/index.md" && (doc.FileModified == nil || !doc.FileModified.Equal(lastMod)) {
+			t.Errorf("expected modified %v from the sitemap, got %v", lastMod, doc.FileModified)
+		}
+	}
+
+	// A sitemap that cannot be read is an error
+	c, err := New(&types.Config{WebSitemap: site.URL + "/docs/missing.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Load(context.Background(), processor.Options{}); err == nil {
+		t.Error("expected error for a missing sitemap")
+	}
+}
+
+func TestCrawlRedirect(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/docs/new.html": htmlPage("New"),
+		"/docs/":         htmlPage("Home", "old.html", "away.html"),
+	}, nil)
+	handler := site.Config.Handler
+	site.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs/old.html":
+			http.Redirect(w, r, "/docs/new.html", http.StatusMovedPermanently)
+		case "/docs/away.html":
+			http.Redirect(w, r, "/elsewhere.html", http.StatusFound)
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+
+	docs, stats := load(t, &types.Config{WebURL: []string{site.URL + "/docs/"}, WebMaxDepth: 1})
+	expected := []string{site.URL + "/docs/", site.URL + "/docs/new.html"}
+	if got := docNames(docs); strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected documents %v, got %v", expected, got)
+	}
+	if stats.FilesSkipped != 1 {
+		t.Errorf("expected the redirect outside the prefix to be skipped, got %d skipped", stats.FilesSkipped)
+	}
+}
+
+func TestCrawlRateLimit(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/":       htmlPage("Home", "a.html", "b.html"),
+		"/a.html": htmlPage("A"),
+		"/b.html": htmlPage("B"),
+	}, nil)
+
+	// robots.txt and three pages at 20 requests per second
+	start := time.Now()
+	docs, _ := load(t, &types.Config{WebURL: []string{site.URL + "/"}, WebMaxDepth: 1, WebRateLimit: 20})
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %v", docNames(docs))
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected requests to be spaced by the rate limit, took %v", elapsed)
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&types.Config{
+		WebURL:     []string{"HTTPS://Example.com/docs/guide/intro.html#top", "https://example.com"},
+		WebSitemap: "https://example.com/site/sitemap.xml",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var prefixes []string
+	for _, p := range c.prefixes {
+		prefixes = append(prefixes, p.String())
+	}
+	expected := "https://example.com/docs/guide/ https://example.com/ https://example.com/site/"
+	if strings.Join(prefixes, " ") != expected {
+		t.Errorf("expected prefixes %s, got %v", expected, prefixes)
+	}
+	if c.starts[0].String() != "https://example.com/docs/guide/intro.html" {
+		t.Errorf("expected normalized start URL, got %s", c.starts[0])
+	}
+
+	for _, bad := range []string{"example.com/docs", "ftp://example.com/", "/docs"} {
+		if _, err := New(&types.Config{WebURL: []string{bad}}); err == nil {
+			t.Errorf("expected error for start URL %q", bad)
+		}
+	}
+}